@@ -0,0 +1,145 @@
+package agentic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ApprovalDecision is the outcome of an approval request.
+type ApprovalDecision string
+
+const (
+	ApprovalAllowOnce   ApprovalDecision = "allow_once"
+	ApprovalAllowAlways ApprovalDecision = "allow_always"
+	ApprovalDeny        ApprovalDecision = "deny"
+)
+
+// ApprovalRequest describes a tool call waiting for approval.
+type ApprovalRequest struct {
+	Tool ToolDefinition
+	Call ToolCall
+	// Rule names the policy rule that asked for approval, if any.
+	Rule string
+	// EveryTime means the rule asks for each call, so an allow_always answer
+	// is treated as allow_once.
+	EveryTime bool
+}
+
+// ApprovalRuler is implemented by policy errors that name the rule asking
+// for approval. everyTime reports that the rule asks for each call.
+type ApprovalRuler interface {
+	ApprovalRule() (rule string, everyTime bool)
+}
+
+// Approver decides whether a paused tool call may run.
+// Implementations may block (e.g. prompting a user) until ctx is done.
+type Approver interface {
+	RequestApproval(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error)
+}
+
+// ApproverFunc adapts a function to an Approver.
+type ApproverFunc func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error)
+
+func (f ApproverFunc) RequestApproval(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+	return f(ctx, req)
+}
+
+// SessionApprover remembers allow_always decisions per tool and rule and only
+// consults the inner Approver for calls not yet approved in this session.
+// Requests with EveryTime set are never remembered.
+type SessionApprover struct {
+	inner  Approver
+	mu     sync.Mutex
+	always map[string]struct{}
+}
+
+// NewSessionApprover wraps inner with per-session memory.
+func NewSessionApprover(inner Approver) *SessionApprover {
+	return &SessionApprover{inner: inner, always: make(map[string]struct{})}
+}
+
+// RequestApproval implements Approver.
+func (s *SessionApprover) RequestApproval(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+	key := approvalKey(req.Tool.Name, req.Rule)
+	if !req.EveryTime && s.approved(key) {
+		return ApprovalAllowAlways, nil
+	}
+	if s.inner == nil {
+		return ApprovalDeny, nil
+	}
+	decision, err := s.inner.RequestApproval(ctx, req)
+	if err != nil {
+		return ApprovalDeny, err
+	}
+	if decision == ApprovalAllowAlways && !req.EveryTime {
+		s.mu.Lock()
+		s.always[key] = struct{}{}
+		s.mu.Unlock()
+	}
+	return decision, nil
+}
+
+// Approved reports whether name was approved for the rest of the session by
+// a policy that names no rule, such as ApprovalPolicy.
+func (s *SessionApprover) Approved(name string) bool {
+	return s.approved(approvalKey(name, ""))
+}
+
+func (s *SessionApprover) approved(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.always[key]
+	return ok
+}
+
+func approvalKey(tool, rule string) string {
+	return tool + "\x00" + rule
+}
+
+// Reset forgets all remembered approvals.
+func (s *SessionApprover) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.always = make(map[string]struct{})
+}
+
+// ApprovalPolicy wraps a Policy and requires approval for the named tools.
+type ApprovalPolicy struct {
+	inner Policy
+	names map[string]struct{}
+}
+
+// NewApprovalPolicy requires approval for names on top of inner (nil allows all).
+func NewApprovalPolicy(inner Policy, names []string) ApprovalPolicy {
+	if inner == nil {
+		inner = AllowAllPolicy{}
+	}
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		set[name] = struct{}{}
+	}
+	return ApprovalPolicy{inner: inner, names: set}
+}
+
+func (p ApprovalPolicy) AllowTool(def ToolDefinition) error {
+	return p.inner.AllowTool(def)
+}
+
+func (p ApprovalPolicy) AllowCall(def ToolDefinition, call ToolCall) error {
+	return p.AllowCallContext(context.Background(), def, call)
+}
+
+// AllowCallContext implements ContextPolicy, forwarding ctx to the inner policy.
+func (p ApprovalPolicy) AllowCallContext(ctx context.Context, def ToolDefinition, call ToolCall) error {
+	if err := allowCall(ctx, p.inner, def, call); err != nil {
+		return err
+	}
+	if _, ok := p.names[def.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrApprovalRequired, def.Name)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package agentic
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRegistryApprovalFlow(t *testing.T) {
+	var prompts int
+	answer := ApprovalAllowOnce
+	approver := NewSessionApprover(ApproverFunc(func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+		prompts++
+		if req.Tool.Name != "bash" || req.Call.Name != "bash" {
+			t.Fatalf("unexpected approval request: %#v", req)
+		}
+		return answer, nil
+	}))
+	reg := NewRegistry(
+		WithPolicy(NewApprovalPolicy(nil, []string{"bash"})),
+		WithApprover(approver),
+	)
+	if err := reg.Register(echoTool{def: ToolDefinition{Name: "bash"}}, echoTool{def: ToolDefinition{Name: "read"}}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := reg.Execute(ctx, ToolCall{Name: "read"}); err != nil || prompts != 0 {
+		t.Fatalf("expected read to skip approval, err=%v prompts=%d", err, prompts)
+	}
+	if _, err := reg.Execute(ctx, ToolCall{Name: "bash"}); err != nil || prompts != 1 {
+		t.Fatalf("expected allow once, err=%v prompts=%d", err, prompts)
+	}
+
+	answer = ApprovalDeny
+	_, err := reg.Execute(ctx, ToolCall{Name: "bash"})
+	if !errors.Is(err, ErrApprovalDenied) || prompts != 2 {
+		t.Fatalf("expected denial after re-prompt, err=%v prompts=%d", err, prompts)
+	}
+
+	answer = ApprovalAllowAlways
+	if _, err := reg.Execute(ctx, ToolCall{Name: "bash"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := reg.Execute(ctx, ToolCall{Name: "bash"}); err != nil || prompts != 3 {
+		t.Fatalf("expected remembered approval, err=%v prompts=%d", err, prompts)
+	}
+
+	approver.Reset()
+	answer = ApprovalDeny
+	if _, err := reg.Execute(ctx, ToolCall{Name: "bash"}); !errors.Is(err, ErrApprovalDenied) {
+		t.Fatalf("expected reset to forget approval, got %v", err)
+	}
+}
+
+func TestRegistryApprovalWithoutApproverDenies(t *testing.T) {
+	reg := NewRegistry(WithPolicy(NewApprovalPolicy(nil, []string{"bash"})))
+	if err := reg.Register(echoTool{def: ToolDefinition{Name: "bash"}}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	_, err := reg.Execute(context.Background(), ToolCall{Name: "bash"})
+	if !errors.Is(err, ErrApprovalDenied) {
+		t.Fatalf("expected denial without approver, got %v", err)
+	}
+}
+
+func TestRegistryValidatesBeforeApproval(t *testing.T) {
+	var prompts int
+	approver := NewSessionApprover(ApproverFunc(func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+		prompts++
+		return ApprovalAllowAlways, nil
+	}))
+	reg := NewRegistry(
+		WithPolicy(NewApprovalPolicy(nil, []string{"bash"})),
+		WithApprover(approver),
+		WithSchemaValidation(),
+	)
+	def := ToolDefinition{
+		Name:           "bash",
+		SchemaHash:     "v2",
+		AllowedCallers: []string{"agent"},
+		InputSchema:    []byte(`{"type":"object","properties":{"command":{"type":"string"}},"required":["command"]}`),
+	}
+	if err := reg.Register(echoTool{def: def}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	ctx := context.Background()
+	agent := &ToolCaller{Type: "agent"}
+
+	if _, err := reg.Execute(ctx, ToolCall{Name: "bash", Input: []byte(`{"command":"ls"}`)}); !errors.Is(err, ErrCallerNotAllowed) {
+		t.Fatalf("expected caller rejection, got %v", err)
+	}
+	if _, err := reg.Execute(ctx, ToolCall{Name: "bash", Caller: agent, SchemaHash: "v1", Input: []byte(`{"command":"ls"}`)}); !errors.Is(err, ErrSchemaMismatch) {
+		t.Fatalf("expected schema mismatch, got %v", err)
+	}
+	if _, err := reg.Execute(ctx, ToolCall{Name: "bash", Caller: agent, Input: []byte(`{}`)}); !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input, got %v", err)
+	}
+	if prompts != 0 {
+		t.Fatalf("expected rejected calls not to prompt, got %d prompts", prompts)
+	}
+	if _, err := reg.Execute(ctx, ToolCall{Name: "bash", Caller: agent, Input: []byte(`{"command":"ls"}`)}); err != nil || prompts != 1 {
+		t.Fatalf("expected a valid call to prompt once, err=%v prompts=%d", err, prompts)
+	}
+}
+
+type ruleAskPolicy struct {
+	rule      string
+	everyTime bool
+}
+
+func (p ruleAskPolicy) AllowTool(ToolDefinition) error { return nil }
+
+func (p ruleAskPolicy) AllowCall(ToolDefinition, ToolCall) error { return ruleAskError(p) }
+
+type ruleAskError ruleAskPolicy
+
+func (e ruleAskError) Error() string { return "ask: " + e.rule }
+
+func (e ruleAskError) Unwrap() error { return ErrApprovalRequired }
+
+func (e ruleAskError) ApprovalRule() (string, bool) { return e.rule, e.everyTime }
+
+func TestSessionApproverKeysByRule(t *testing.T) {
+	var prompts []ApprovalRequest
+	approver := NewSessionApprover(ApproverFunc(func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+		prompts = append(prompts, req)
+		return ApprovalAllowAlways, nil
+	}))
+	ctx := context.Background()
+	tool := echoTool{def: ToolDefinition{Name: "bash"}}
+	run := func(policy Policy) {
+		t.Helper()
+		reg := NewRegistry(WithPolicy(policy), WithApprover(approver))
+		if err := reg.Register(tool); err != nil {
+			t.Fatalf("unexpected register error: %v", err)
+		}
+		if _, err := reg.Execute(ctx, ToolCall{Name: "bash"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	run(ruleAskPolicy{rule: "default"})
+	run(ruleAskPolicy{rule: "default"})
+	if len(prompts) != 1 || prompts[0].Rule != "default" {
+		t.Fatalf("expected one remembered prompt for the default rule, got %+v", prompts)
+	}
+	run(ruleAskPolicy{rule: "other"})
+	if len(prompts) != 2 {
+		t.Fatalf("expected a different rule to prompt again, got %d prompts", len(prompts))
+	}
+	run(ruleAskPolicy{rule: "bash", everyTime: true})
+	run(ruleAskPolicy{rule: "bash", everyTime: true})
+	if len(prompts) != 4 || !prompts[3].EveryTime {
+		t.Fatalf("expected every-time rules to prompt each call, got %+v", prompts)
+	}
+	if approver.Approved("bash") {
+		t.Fatal("expected no rule-less approval for bash")
+	}
+}
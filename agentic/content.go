@@ -0,0 +1,43 @@
+package agentic
+
+// Content part types.
+const (
+	PartText     = "text"
+	PartImage    = "image"
+	PartDocument = "document"
+)
+
+// ContentPart is one piece of multimodal content: text, an image or a
+// document such as a PDF. Binary parts carry either inline Data or a URL.
+type ContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// TextPart returns a text part.
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: PartText, Text: text}
+}
+
+// ImagePart returns an inline image part.
+func ImagePart(mimeType string, data []byte) ContentPart {
+	return ContentPart{Type: PartImage, MIMEType: mimeType, Data: data}
+}
+
+// ImageURLPart returns an image part that references url.
+func ImageURLPart(mimeType, url string) ContentPart {
+	return ContentPart{Type: PartImage, MIMEType: mimeType, URL: url}
+}
+
+// DocumentPart returns an inline document part.
+func DocumentPart(mimeType string, data []byte) ContentPart {
+	return ContentPart{Type: PartDocument, MIMEType: mimeType, Data: data}
+}
+
+// DocumentURLPart returns a document part that references url.
+func DocumentURLPart(mimeType, url string) ContentPart {
+	return ContentPart{Type: PartDocument, MIMEType: mimeType, URL: url}
+}
@@ -0,0 +1,246 @@
+// Package bpe counts tokens offline with a bundled byte-level BPE table.
+//
+// The merge table in merges.bin was trained with gen.go on the Go source tree
+// and its documentation, so counts track real tokenizers closely for code and
+// English prose. They are still estimates: provider vocabularies differ, and
+// non-English text tends to be overcounted.
+package bpe
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:generate go run gen.go -out merges.bin $GOROOT/doc $GOROOT/src
+
+//go:embed merges.bin
+var mergesData []byte
+
+const (
+	maxCacheEntries = 1 << 16
+	maxCachedPiece  = 64
+)
+
+var (
+	loadOnce sync.Once
+	ranks    map[uint32]int32
+)
+
+// Counter implements budget.TokenCounter with the bundled table.
+// The zero value is ready to use and safe for concurrent use.
+type Counter struct{}
+
+// Count implements budget.TokenCounter.
+func (Counter) Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	total := 0
+	for _, piece := range Split(text) {
+		total += countPiece(piece)
+	}
+	return total
+}
+
+// Encode returns the token ids for text. Ids below 256 are raw bytes; id
+// 256+n is the n-th merge in the table.
+func Encode(text string) []int {
+	var ids []int
+	for _, piece := range Split(text) {
+		for _, id := range encodePiece(piece) {
+			ids = append(ids, int(id))
+		}
+	}
+	return ids
+}
+
+var cache = struct {
+	sync.Mutex
+	counts map[string]int
+}{counts: make(map[string]int)}
+
+func countPiece(piece string) int {
+	if len(piece) > maxCachedPiece {
+		return len(encodePiece(piece))
+	}
+	cache.Lock()
+	n, ok := cache.counts[piece]
+	cache.Unlock()
+	if ok {
+		return n
+	}
+	n = len(encodePiece(piece))
+	cache.Lock()
+	if len(cache.counts) >= maxCacheEntries {
+		clear(cache.counts)
+	}
+	cache.counts[piece] = n
+	cache.Unlock()
+	return n
+}
+
+// encodePiece applies merges to piece, lowest rank first.
+func encodePiece(piece string) []int32 {
+	loadOnce.Do(load)
+	ids := make([]int32, len(piece))
+	for i := 0; i < len(piece); i++ {
+		ids[i] = int32(piece[i])
+	}
+	for len(ids) > 1 {
+		best, at := int32(-1), -1
+		for i := 0; i+1 < len(ids); i++ {
+			if rank, ok := ranks[pairKey(ids[i], ids[i+1])]; ok && (best < 0 || rank < best) {
+				best, at = rank, i
+			}
+		}
+		if at < 0 {
+			break
+		}
+		ids[at] = 256 + best
+		ids = append(ids[:at+1], ids[at+2:]...)
+	}
+	return ids
+}
+
+func load() {
+	ranks = make(map[uint32]int32, len(mergesData)/4)
+	for i := 0; i+4 <= len(mergesData); i += 4 {
+		a := int32(binary.LittleEndian.Uint16(mergesData[i:]))
+		b := int32(binary.LittleEndian.Uint16(mergesData[i+2:]))
+		ranks[pairKey(a, b)] = int32(i / 4)
+	}
+}
+
+func pairKey(a, b int32) uint32 {
+	return uint32(a)<<16 | uint32(b)
+}
+
+// Split pre-tokenizes text the way cl100k-style tokenizers do: contractions,
+// words with one leading space or symbol, numbers of up to three digits,
+// symbol runs and whitespace. Merges never cross piece boundaries.
+func Split(text string) []string {
+	var pieces []string
+	for i := 0; i < len(text); {
+		n := pieceLen(text[i:])
+		pieces = append(pieces, text[i:i+n])
+		i += n
+	}
+	return pieces
+}
+
+func pieceLen(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == '\'' {
+		if n := contractionLen(s[size:]); n > 0 {
+			return size + n
+		}
+	}
+	switch {
+	case unicode.IsLetter(r):
+		return size + letterRun(s[size:])
+	case unicode.IsNumber(r):
+		n := size
+		for digits := 1; digits < 3 && n < len(s); digits++ {
+			next, nsize := utf8.DecodeRuneInString(s[n:])
+			if !unicode.IsNumber(next) {
+				break
+			}
+			n += nsize
+		}
+		return n
+	case r != '\r' && r != '\n' && size < len(s):
+		if next, _ := utf8.DecodeRuneInString(s[size:]); unicode.IsLetter(next) {
+			return size + letterRun(s[size:])
+		}
+	}
+	if unicode.IsSpace(r) {
+		next, nsize := utf8.DecodeRuneInString(s[size:])
+		if r == ' ' && size < len(s) && isSymbol(next) {
+			return size + symbolRun(s[size:]) + newlineRun(s[size+nsize:])
+		}
+		return whitespaceLen(s)
+	}
+	return symbolRun(s) + newlineRun(s[symbolRun(s):])
+}
+
+func contractionLen(s string) int {
+	for _, suffix := range []string{"ll", "ve", "re", "s", "t", "m", "d"} {
+		if len(s) >= len(suffix) && equalFoldASCII(s[:len(suffix)], suffix) {
+			return len(suffix)
+		}
+	}
+	return 0
+}
+
+func equalFoldASCII(a, b string) bool {
+	for i := 0; i < len(a); i++ {
+		if a[i]|0x20 != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func letterRun(s string) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !unicode.IsLetter(r) {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+func isSymbol(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func symbolRun(s string) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !isSymbol(r) {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+func newlineRun(s string) int {
+	n := 0
+	for n < len(s) && (s[n] == '\r' || s[n] == '\n') {
+		n++
+	}
+	return n
+}
+
+// whitespaceLen splits a whitespace run: up to its last newline if it has
+// one, otherwise all but the last rune when a word follows, so the word keeps
+// its leading space.
+func whitespaceLen(s string) int {
+	n, lastNewline, lastRune := 0, -1, 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		if r == '\n' || r == '\r' {
+			lastNewline = n + size
+		}
+		lastRune = size
+		n += size
+	}
+	switch {
+	case lastNewline > 0:
+		return lastNewline
+	case n < len(s) && n > lastRune:
+		return n - lastRune
+	}
+	return n
+}
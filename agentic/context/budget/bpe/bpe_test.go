@@ -0,0 +1,60 @@
+package bpe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic/context/budget"
+)
+
+var _ budget.TokenCounter = Counter{}
+
+func TestSplitKeepsLeadingSpaceOnWords(t *testing.T) {
+	got := Split("don't  stop\n\n\tfunc(x) 1234")
+	want := []string{"don", "'t", " ", " stop", "\n\n", "\tfunc", "(x", ")", " ", "123", "4"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected pieces %q", got)
+	}
+}
+
+func TestCountMergesCommonWords(t *testing.T) {
+	var c Counter
+	for _, word := range []string{"func", " return", " the", " error"} {
+		if got := c.Count(word); got != 1 {
+			t.Fatalf("expected %q to be one token, got %d (%v)", word, got, Encode(word))
+		}
+	}
+	text := "// Count implements budget.TokenCounter for the bundled table.\nfunc (Counter) Count(text string) int {\n\treturn len(text)\n}\n"
+	if got := c.Count(text); got == 0 || got > len(text)/2 {
+		t.Fatalf("expected well under one token per two bytes, got %d for %d bytes", got, len(text))
+	}
+	if c.Count("") != 0 {
+		t.Fatal("expected empty text to count zero")
+	}
+}
+
+func TestEncodeRoundTripsBytes(t *testing.T) {
+	text := "naïve café ☕ \xff"
+	if got := decode(Encode(text)); got != text {
+		t.Fatalf("round trip mismatch: %q", got)
+	}
+}
+
+func decode(ids []int) string {
+	loadOnce.Do(load)
+	var b strings.Builder
+	var expand func(id int32)
+	expand = func(id int32) {
+		if id < 256 {
+			b.WriteByte(byte(id))
+			return
+		}
+		i := int(id-256) * 4
+		expand(int32(mergesData[i]) | int32(mergesData[i+1])<<8)
+		expand(int32(mergesData[i+2]) | int32(mergesData[i+3])<<8)
+	}
+	for _, id := range ids {
+		expand(int32(id))
+	}
+	return b.String()
+}
@@ -0,0 +1,199 @@
+//go:build ignore
+
+// gen trains the merge table bundled with package bpe.
+//
+//	go run gen.go -out merges.bin [-merges 16384] [-max-bytes 67108864] dir...
+//
+// It reads .go (excluding tests and testdata), .md, .html and .txt files
+// under each dir in lexical order, splits them with bpe.Split and learns
+// byte-pair merges over pieces seen at least twice.
+package main
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"flag"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/victorarias/agentic-weave/agentic/context/budget/bpe"
+)
+
+func main() {
+	out := flag.String("out", "merges.bin", "output file")
+	merges := flag.Int("merges", 16384, "number of merges to learn")
+	maxBytes := flag.Int("max-bytes", 64<<20, "corpus size limit")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("gen: no corpus directories")
+	}
+
+	freq := make(map[string]int)
+	read := 0
+	for _, dir := range flag.Args() {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || read >= *maxBytes {
+				return err
+			}
+			if d.IsDir() {
+				if name := d.Name(); name == "testdata" || name == "vendor" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !corpusFile(path) {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			read += len(data)
+			for _, piece := range bpe.Split(string(data)) {
+				freq[piece]++
+			}
+			return nil
+		})
+		if err != nil {
+			log.Fatalf("gen: %v", err)
+		}
+	}
+	log.Printf("gen: read %d bytes, %d distinct pieces", read, len(freq))
+
+	table := train(freq, *merges)
+	buf := make([]byte, 0, 4*len(table))
+	for _, pair := range table {
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(pair[0]))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(pair[1]))
+	}
+	if err := os.WriteFile(*out, buf, 0o644); err != nil {
+		log.Fatalf("gen: %v", err)
+	}
+	log.Printf("gen: wrote %d merges to %s", len(table), *out)
+}
+
+func corpusFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".go":
+		return !strings.HasSuffix(path, "_test.go")
+	case ".md", ".html", ".txt":
+		return true
+	}
+	return false
+}
+
+type word struct {
+	ids   []int32
+	count int
+}
+
+type pair [2]int32
+
+// train learns up to n merges, always merging the most frequent pair and
+// breaking ties by the smaller pair.
+func train(freq map[string]int, n int) []pair {
+	var words []word
+	for piece, count := range freq {
+		if count >= 2 && len(piece) > 1 {
+			ids := make([]int32, len(piece))
+			for i := 0; i < len(piece); i++ {
+				ids[i] = int32(piece[i])
+			}
+			words = append(words, word{ids: ids, count: count})
+		}
+	}
+
+	counts := make(map[pair]int)
+	where := make(map[pair]map[int]struct{})
+	for i, w := range words {
+		for j := 0; j+1 < len(w.ids); j++ {
+			p := pair{w.ids[j], w.ids[j+1]}
+			counts[p] += w.count
+			if where[p] == nil {
+				where[p] = make(map[int]struct{})
+			}
+			where[p][i] = struct{}{}
+		}
+	}
+	h := &pairHeap{}
+	for p, c := range counts {
+		*h = append(*h, entry{p, c})
+	}
+	heap.Init(h)
+
+	var table []pair
+	for len(table) < n && h.Len() > 0 {
+		top := heap.Pop(h).(entry)
+		if counts[top.pair] != top.count || top.count < 2 {
+			continue
+		}
+		id := int32(256 + len(table))
+		table = append(table, top.pair)
+
+		changed := make(map[pair]struct{})
+		for i := range where[top.pair] {
+			w := &words[i]
+			for j := 0; j+1 < len(w.ids); j++ {
+				p := pair{w.ids[j], w.ids[j+1]}
+				counts[p] -= w.count
+				changed[p] = struct{}{}
+			}
+			merged := w.ids[:0]
+			for j := 0; j < len(w.ids); j++ {
+				if j+1 < len(w.ids) && w.ids[j] == top.pair[0] && w.ids[j+1] == top.pair[1] {
+					merged = append(merged, id)
+					j++
+					continue
+				}
+				merged = append(merged, w.ids[j])
+			}
+			w.ids = merged
+			for j := 0; j+1 < len(w.ids); j++ {
+				p := pair{w.ids[j], w.ids[j+1]}
+				counts[p] += w.count
+				changed[p] = struct{}{}
+				if where[p] == nil {
+					where[p] = make(map[int]struct{})
+				}
+				where[p][i] = struct{}{}
+			}
+		}
+		delete(where, top.pair)
+		delete(counts, top.pair)
+		for p := range changed {
+			if c := counts[p]; c > 0 {
+				heap.Push(h, entry{p, c})
+			}
+		}
+	}
+	return table
+}
+
+type entry struct {
+	pair  pair
+	count int
+}
+
+type pairHeap []entry
+
+func (h pairHeap) Len() int { return len(h) }
+func (h pairHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count > h[j].count
+	}
+	if h[i].pair[0] != h[j].pair[0] {
+		return h[i].pair[0] < h[j].pair[0]
+	}
+	return h[i].pair[1] < h[j].pair[1]
+}
+func (h pairHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *pairHeap) Push(x any)   { *h = append(*h, x.(entry)) }
+func (h *pairHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
@@ -13,17 +13,51 @@ type TokenCounter interface {
 	Count(text string) int
 }
 
+// BatchCounter is a TokenCounter that can count many texts at once, such as
+// one backed by a provider API. Manager prefers CountBatch when available.
+type BatchCounter interface {
+	TokenCounter
+	CountBatch(ctx context.Context, texts []string) ([]int, error)
+}
+
 // Compactor produces a summary for messages that will be compacted away.
 type Compactor interface {
 	Compact(ctx context.Context, messages []Budgetable) (string, error)
 }
 
+// Roles the cut point understands; they match the message package roles.
+const (
+	roleUser = "user"
+	roleTool = "tool"
+)
+
 // Policy configures compaction thresholds.
 type Policy struct {
 	ContextWindow    int
 	ReserveTokens    int
 	KeepRecentTokens int
 	KeepLast         int
+	// PruneToolOutputBytes, when positive, lets message.CompactIfNeeded
+	// shrink tool outputs larger than this in the messages it would compact,
+	// and skip summarizing when that is enough.
+	PruneToolOutputBytes int
+}
+
+// Plan is the result of checking messages against a Policy.
+type Plan struct {
+	// Over reports whether the messages exceed the threshold.
+	Over bool
+	// Start is the first message to keep; earlier messages are compacted.
+	// It is 0 when there is no valid cut.
+	Start int
+}
+
+// Calibration is a provider-reported token count for the first Messages
+// messages, typically the prompt and output usage of the last response. It
+// includes the system prompt and tool definitions, which messages do not.
+type Calibration struct {
+	Messages int
+	Tokens   int
 }
 
 // Manager handles context compaction when token budgets are exceeded.
@@ -65,18 +99,22 @@ func EstimateTokens[T Budgetable](messages []T, counter TokenCounter) int {
 // CompactIfNeeded compacts messages when budget thresholds are exceeded.
 // Returns the summary, number of messages to keep from the end, whether compaction occurred, and any error.
 func (m Manager) CompactIfNeeded(ctx context.Context, messages []Budgetable) (summary string, keepCount int, changed bool, err error) {
-	if m.Counter == nil || m.Compactor == nil || m.Policy.ContextWindow <= 0 {
-		return "", len(messages), false, nil
-	}
+	return m.CompactCalibrated(ctx, messages, Calibration{})
+}
 
-	total := EstimateTokens(messages, m.Counter)
-	threshold := m.Policy.ContextWindow - max(m.Policy.ReserveTokens, 0)
-	if total <= threshold {
+// CompactCalibrated is CompactIfNeeded with a measured prefix: cal.Tokens
+// replaces the estimate for the first cal.Messages messages when checking the
+// threshold. A zero or out-of-range Calibration is ignored.
+func (m Manager) CompactCalibrated(ctx context.Context, messages []Budgetable, cal Calibration) (summary string, keepCount int, changed bool, err error) {
+	if m.Compactor == nil {
 		return "", len(messages), false, nil
 	}
-
-	start := m.cutPoint(messages)
-	if start <= 0 || start >= len(messages) {
+	plan, err := m.Check(ctx, messages, cal)
+	if err != nil {
+		return "", len(messages), false, err
+	}
+	start := plan.Start
+	if !plan.Over || start <= 0 || start >= len(messages) {
 		return "", len(messages), false, nil
 	}
 
@@ -94,12 +132,55 @@ func (m Manager) CompactIfNeeded(ctx context.Context, messages []Budgetable) (su
 	return summary, keepCount, true, nil
 }
 
-func (m Manager) cutPoint(messages []Budgetable) int {
+// Check counts messages, calibrated by cal, and reports whether they exceed
+// the threshold and where compaction would cut. The cut never leaves a tool
+// result at the start of the kept messages, apart from the tool call that
+// produced it.
+func (m Manager) Check(ctx context.Context, messages []Budgetable, cal Calibration) (Plan, error) {
+	if m.Counter == nil || m.Policy.ContextWindow <= 0 {
+		return Plan{}, nil
+	}
+	counts, err := countMessages(ctx, m.Counter, messages)
+	if err != nil {
+		return Plan{}, err
+	}
+	total, measured := 0, 0
+	if cal.Tokens > 0 && cal.Messages > 0 && cal.Messages <= len(counts) {
+		total, measured = cal.Tokens, cal.Messages
+	}
+	for _, n := range counts[measured:] {
+		total += n
+	}
+	threshold := m.Policy.ContextWindow - max(m.Policy.ReserveTokens, 0)
+	if total <= threshold {
+		return Plan{}, nil
+	}
+	return Plan{Over: true, Start: turnBoundary(messages, counts, m.cutPoint(counts), threshold)}, nil
+}
+
+// countMessages returns the token count of each message, in one batch when
+// counter is a BatchCounter.
+func countMessages(ctx context.Context, counter TokenCounter, messages []Budgetable) ([]int, error) {
+	texts := make([]string, len(messages))
+	for i, msg := range messages {
+		texts[i] = msg.BudgetContent()
+	}
+	if batch, ok := counter.(BatchCounter); ok {
+		return batch.CountBatch(ctx, texts)
+	}
+	counts := make([]int, len(texts))
+	for i, text := range texts {
+		counts[i] = counter.Count(text)
+	}
+	return counts, nil
+}
+
+func (m Manager) cutPoint(counts []int) int {
 	keepTokens := m.Policy.KeepRecentTokens
-	if keepTokens > 0 && m.Counter != nil {
+	if keepTokens > 0 {
 		acc := 0
-		for i := len(messages) - 1; i >= 0; i-- {
-			acc += m.Counter.Count(messages[i].BudgetContent())
+		for i := len(counts) - 1; i >= 0; i-- {
+			acc += counts[i]
 			if acc >= keepTokens {
 				return i
 			}
@@ -111,13 +192,49 @@ func (m Manager) cutPoint(messages []Budgetable) int {
 	if keepLast <= 0 {
 		return 0
 	}
-	start := len(messages) - keepLast
+	start := len(counts) - keepLast
 	if start < 0 {
 		start = 0
 	}
 	return start
 }
 
+// turnBoundary moves the cut at start so the kept messages begin a turn. A
+// tool result never starts them, and a user message is preferred as long as
+// the kept messages still fit under threshold. It returns 0 if no valid cut
+// exists.
+func turnBoundary(messages []Budgetable, counts []int, start, threshold int) int {
+	if start <= 0 || start >= len(messages) {
+		return start
+	}
+	kept := 0
+	for _, n := range counts[start:] {
+		kept += n
+	}
+	for i := start; i > 0; i-- {
+		if i < start {
+			kept += counts[i]
+		}
+		if kept > threshold {
+			break
+		}
+		if messages[i].BudgetRole() == roleUser {
+			return i
+		}
+	}
+	for i := start; i > 0; i-- {
+		if messages[i].BudgetRole() != roleTool {
+			return i
+		}
+	}
+	for i := start + 1; i < len(messages); i++ {
+		if messages[i].BudgetRole() != roleTool {
+			return i
+		}
+	}
+	return 0
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -180,3 +180,74 @@ func TestNoCompactionWhenKeepRecentTooLarge(t *testing.T) {
 		t.Fatalf("expected keepCount %d, got %d", len(msgs), keepCount)
 	}
 }
+
+func TestCompactCalibratedUsesMeasuredPrefix(t *testing.T) {
+	msgs := []Budgetable{
+		testMessage{role: "user", content: "aa"},
+		testMessage{role: "assistant", content: "bb"},
+		testMessage{role: "user", content: "cc"},
+	}
+	mgr := Manager{
+		Counter:   charCounter{},
+		Compactor: &recordingCompactor{summary: "summary"},
+		Policy:    Policy{ContextWindow: 10, KeepLast: 1},
+	}
+
+	if _, _, changed, _ := mgr.CompactIfNeeded(context.Background(), msgs); changed {
+		t.Fatal("expected the estimate to stay under the window")
+	}
+	_, keepCount, changed, err := mgr.CompactCalibrated(context.Background(), msgs, Calibration{Messages: 2, Tokens: 9})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed || keepCount != 1 {
+		t.Fatalf("expected measured tokens to trigger compaction, got changed=%v keep=%d", changed, keepCount)
+	}
+	if _, _, changed, _ := mgr.CompactCalibrated(context.Background(), msgs, Calibration{Messages: 4, Tokens: 9}); changed {
+		t.Fatal("expected an out-of-range calibration to be ignored")
+	}
+}
+
+func TestCheckNeverStartsOnToolResult(t *testing.T) {
+	msgs := []Budgetable{
+		testMessage{role: "user", content: "aaaaaaaaaa"},
+		testMessage{role: "assistant", content: "call"},
+		testMessage{role: "tool", content: "result"},
+	}
+	mgr := Manager{Counter: charCounter{}, Policy: Policy{ContextWindow: 12, KeepLast: 1}}
+	plan, err := mgr.Check(context.Background(), msgs, Calibration{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !plan.Over || plan.Start != 1 {
+		t.Fatalf("expected the cut before the tool call, got %+v", plan)
+	}
+
+	msgs = []Budgetable{
+		testMessage{role: "tool", content: "orphan"},
+		testMessage{role: "tool", content: "results"},
+	}
+	if plan, _ := mgr.Check(context.Background(), msgs, Calibration{Messages: 1, Tokens: 20}); !plan.Over || plan.Start != 0 {
+		t.Fatalf("expected no valid cut, got %+v", plan)
+	}
+}
+
+func TestCheckPrefersUserBoundaryWithinThreshold(t *testing.T) {
+	msgs := []Budgetable{
+		testMessage{role: "user", content: "aaaaaaaaaaaaaaaaaaaa"},
+		testMessage{role: "user", content: "bb"},
+		testMessage{role: "assistant", content: "cc"},
+		testMessage{role: "tool", content: "dd"},
+		testMessage{role: "assistant", content: "ee"},
+		testMessage{role: "tool", content: "ff"},
+	}
+	mgr := Manager{Counter: charCounter{}, Policy: Policy{ContextWindow: 12, KeepLast: 2}}
+	if plan, _ := mgr.Check(context.Background(), msgs, Calibration{}); plan.Start != 1 {
+		t.Fatalf("expected the cut at the user message, got %+v", plan)
+	}
+
+	mgr.Policy.ContextWindow = 8
+	if plan, _ := mgr.Check(context.Background(), msgs, Calibration{}); plan.Start != 4 {
+		t.Fatalf("expected the cut at the nearest assistant message, got %+v", plan)
+	}
+}
@@ -0,0 +1,142 @@
+package budget
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+)
+
+// CountFunc counts the tokens in text, typically through a provider API.
+type CountFunc func(ctx context.Context, text string) (int, error)
+
+// RemoteOptions configures a RemoteCounter.
+type RemoteOptions struct {
+	// Fallback counts a text when the remote call fails. Nil uses CharCounter.
+	Fallback TokenCounter
+	// MaxParallel caps concurrent remote calls in CountBatch (default 4).
+	MaxParallel int
+	// MaxEntries bounds the cache of counts (default 4096).
+	MaxEntries int
+	// OnError reports remote failures that were replaced by the fallback.
+	// It may be called concurrently.
+	OnError func(error)
+}
+
+// RemoteCounter is a BatchCounter backed by a CountFunc. Counts are cached by
+// text hash, so unchanged messages are counted once. Remote failures fall
+// back to an estimate instead of failing compaction.
+type RemoteCounter struct {
+	count    CountFunc
+	fallback TokenCounter
+	parallel int
+	onError  func(error)
+
+	mu         sync.Mutex
+	cache      map[[sha256.Size]byte]int
+	order      [][sha256.Size]byte
+	next       int
+	maxEntries int
+}
+
+// NewRemoteCounter wraps count with caching and batching.
+func NewRemoteCounter(count CountFunc, opts RemoteOptions) *RemoteCounter {
+	fallback := opts.Fallback
+	if fallback == nil {
+		fallback = CharCounter{}
+	}
+	parallel := opts.MaxParallel
+	if parallel <= 0 {
+		parallel = 4
+	}
+	maxEntries := opts.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = 4096
+	}
+	return &RemoteCounter{
+		count:      count,
+		fallback:   fallback,
+		parallel:   parallel,
+		onError:    opts.OnError,
+		cache:      make(map[[sha256.Size]byte]int),
+		maxEntries: maxEntries,
+	}
+}
+
+// Count implements TokenCounter using a background context.
+func (c *RemoteCounter) Count(text string) int {
+	counts, _ := c.CountBatch(context.Background(), []string{text})
+	return counts[0]
+}
+
+// CountBatch implements BatchCounter. Uncached texts are counted
+// concurrently; it only returns an error when ctx is done.
+func (c *RemoteCounter) CountBatch(ctx context.Context, texts []string) ([]int, error) {
+	counts := make([]int, len(texts))
+	pending := make(map[[sha256.Size]byte][]int)
+	c.mu.Lock()
+	for i, text := range texts {
+		if text == "" {
+			continue
+		}
+		key := sha256.Sum256([]byte(text))
+		if n, ok := c.cache[key]; ok {
+			counts[i] = n
+			continue
+		}
+		pending[key] = append(pending[key], i)
+	}
+	c.mu.Unlock()
+
+	sem := make(chan struct{}, c.parallel)
+	var wg sync.WaitGroup
+	for key, indexes := range pending {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			text := texts[indexes[0]]
+			n, err := c.count(ctx, text)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if c.onError != nil {
+					c.onError(err)
+				}
+				n = c.fallback.Count(text)
+			} else {
+				c.store(key, n)
+			}
+			for _, i := range indexes {
+				counts[i] = n
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return counts, err
+	}
+	return counts, nil
+}
+
+// store caches n, evicting the oldest entry once the cache is full.
+func (c *RemoteCounter) store(key [sha256.Size]byte, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.cache[key]; ok {
+		return
+	}
+	if len(c.order) < c.maxEntries {
+		c.order = append(c.order, key)
+	} else {
+		delete(c.cache, c.order[c.next])
+		c.order[c.next] = key
+		c.next = (c.next + 1) % c.maxEntries
+	}
+	c.cache[key] = n
+}
@@ -0,0 +1,93 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRemoteCounterCachesByText(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	counter := NewRemoteCounter(func(_ context.Context, text string) (int, error) {
+		mu.Lock()
+		calls[text]++
+		mu.Unlock()
+		return len(strings.Fields(text)), nil
+	}, RemoteOptions{})
+
+	counts, err := counter.CountBatch(context.Background(), []string{"a b", "c", "a b", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts[0] != 2 || counts[1] != 1 || counts[2] != 2 || counts[3] != 0 {
+		t.Fatalf("unexpected counts: %v", counts)
+	}
+	if got := counter.Count("a b"); got != 2 {
+		t.Fatalf("expected cached count 2, got %d", got)
+	}
+	if calls["a b"] != 1 || calls["c"] != 1 || len(calls) != 2 {
+		t.Fatalf("expected one call per distinct text, got %v", calls)
+	}
+}
+
+func TestRemoteCounterFallsBackOnError(t *testing.T) {
+	var reported error
+	counter := NewRemoteCounter(func(context.Context, string) (int, error) {
+		return 0, errors.New("unavailable")
+	}, RemoteOptions{Fallback: charCounter{}, OnError: func(err error) { reported = err }})
+
+	if got := counter.Count("hello"); got != 5 {
+		t.Fatalf("expected fallback count 5, got %d", got)
+	}
+	if reported == nil {
+		t.Fatal("expected the error to be reported")
+	}
+}
+
+func TestRemoteCounterEvictsOldest(t *testing.T) {
+	calls := 0
+	counter := NewRemoteCounter(func(_ context.Context, text string) (int, error) {
+		calls++
+		return len(text), nil
+	}, RemoteOptions{MaxEntries: 2, MaxParallel: 1})
+
+	for _, text := range []string{"a", "bb", "ccc", "bb", "a"} {
+		counter.Count(text)
+	}
+	if calls != 4 {
+		t.Fatalf("expected the oldest entry to be recounted, got %d calls", calls)
+	}
+}
+
+func TestManagerUsesBatchCounter(t *testing.T) {
+	batches := 0
+	counter := NewRemoteCounter(func(_ context.Context, text string) (int, error) {
+		return len(text), nil
+	}, RemoteOptions{})
+	mgr := Manager{
+		Counter:   batchSpy{RemoteCounter: counter, batches: &batches},
+		Compactor: &recordingCompactor{summary: "summary"},
+		Policy:    Policy{ContextWindow: 10, KeepLast: 1},
+	}
+	msgs := []Budgetable{testMessage{content: "123456"}, testMessage{content: "123456"}}
+	_, keep, changed, err := mgr.CompactIfNeeded(context.Background(), msgs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed || keep != 1 || batches != 1 {
+		t.Fatalf("expected one batch and compaction, got changed=%v keep=%d batches=%d", changed, keep, batches)
+	}
+}
+
+type batchSpy struct {
+	*RemoteCounter
+	batches *int
+}
+
+func (s batchSpy) CountBatch(ctx context.Context, texts []string) ([]int, error) {
+	*s.batches++
+	return s.RemoteCounter.CountBatch(ctx, texts)
+}
@@ -0,0 +1,198 @@
+// Package summarize provides a budget.Compactor that asks a model for a
+// structured summary of the messages being compacted.
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/context/budget"
+	"github.com/victorarias/agentic-weave/agentic/loop"
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/truncate"
+)
+
+// DefaultPrompt is the system prompt used when Options.Prompt is empty.
+const DefaultPrompt = `You compact a conversation between a user and an agent. The agent will read your summary instead of the original messages, so keep everything it needs to continue the work.
+
+Write these Markdown sections in this order. Write "None." under a section with nothing to report.
+## Goals
+What the user wants, most recent first.
+## Decisions
+Choices made so far and why.
+## Files
+Paths read, created or modified, one line each on what happened.
+## Open TODOs
+Work that is not done yet.
+## Tool results
+Only facts from tool output that later work depends on, such as errors, versions and identifiers.
+
+Be terse and do not address the user. If a previous summary is given, fold it in: keep what still holds and drop what was resolved.`
+
+const (
+	defaultMaxTokens     = 2000
+	defaultMaxToolOutput = 2000
+	truncatedMarker      = "\n[summary truncated]"
+)
+
+// Options configures a Compactor.
+type Options struct {
+	// Prompt replaces DefaultPrompt as the system prompt.
+	Prompt string
+	// MaxTokens caps the summary length, measured with Counter (default 2000).
+	// The model is asked to stay under it, and longer replies are cut.
+	MaxTokens int
+	// Counter measures summaries. Nil uses budget.CharCounter.
+	Counter budget.TokenCounter
+	// MaxToolOutput limits each tool call input and result in the transcript,
+	// in bytes (default 2000).
+	MaxToolOutput int
+}
+
+// Compactor implements budget.Compactor with a loop.Decider, so any provider
+// decider can write the summary.
+type Compactor struct {
+	decider       loop.Decider
+	prompt        string
+	maxTokens     int
+	counter       budget.TokenCounter
+	maxToolOutput int
+}
+
+// New creates a Compactor that summarizes with decider.
+func New(decider loop.Decider, opts Options) *Compactor {
+	prompt := strings.TrimSpace(opts.Prompt)
+	if prompt == "" {
+		prompt = DefaultPrompt
+	}
+	maxTokens := opts.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+	counter := opts.Counter
+	if counter == nil {
+		counter = budget.CharCounter{}
+	}
+	maxToolOutput := opts.MaxToolOutput
+	if maxToolOutput <= 0 {
+		maxToolOutput = defaultMaxToolOutput
+	}
+	return &Compactor{
+		decider:       decider,
+		prompt:        prompt,
+		maxTokens:     maxTokens,
+		counter:       counter,
+		maxToolOutput: maxToolOutput,
+	}
+}
+
+// Compact implements budget.Compactor. System messages, such as the summary
+// from an earlier compaction, are passed as the previous summary and folded
+// into the new one.
+func (c *Compactor) Compact(ctx context.Context, messages []budget.Budgetable) (string, error) {
+	if c.decider == nil {
+		return "", errors.New("summarize: decider is required")
+	}
+	var previous []string
+	var transcript strings.Builder
+	for _, msg := range messages {
+		if msg.BudgetRole() == message.RoleSystem {
+			if text := strings.TrimSpace(msg.BudgetContent()); text != "" {
+				previous = append(previous, text)
+			}
+			continue
+		}
+		c.writeMessage(&transcript, msg)
+	}
+
+	var request strings.Builder
+	if len(previous) > 0 {
+		fmt.Fprintf(&request, "<previous-summary>\n%s\n</previous-summary>\n\n", strings.Join(previous, "\n\n"))
+	}
+	fmt.Fprintf(&request, "<conversation>\n%s</conversation>\n\n", transcript.String())
+	fmt.Fprintf(&request, "Write the summary in at most %d tokens.", c.maxTokens)
+
+	decision, err := c.decider.Decide(ctx, loop.Input{
+		SystemPrompt: c.prompt,
+		UserMessage:  request.String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("summarize: %w", err)
+	}
+	summary := strings.TrimSpace(decision.Reply)
+	if summary == "" {
+		return "", errors.New("summarize: empty summary")
+	}
+	return c.limit(summary), nil
+}
+
+// writeMessage renders one message as transcript lines. AgentMessages keep
+// their tool calls and results; other Budgetables use their content.
+func (c *Compactor) writeMessage(b *strings.Builder, msg budget.Budgetable) {
+	agentMsg, ok := msg.(message.AgentMessage)
+	if !ok {
+		writeLine(b, msg.BudgetRole(), msg.BudgetContent())
+		return
+	}
+	writeLine(b, agentMsg.Role, agentMsg.Content+partsNote(agentMsg.Parts))
+	for _, call := range agentMsg.ToolCalls {
+		writeLine(b, "tool call "+call.Name, c.clip(string(call.Input)))
+	}
+	for _, result := range agentMsg.ToolResults {
+		if result.Error != nil {
+			writeLine(b, "tool error "+result.Name, c.clip(result.Error.Message))
+			continue
+		}
+		writeLine(b, "tool result "+result.Name, c.clip(string(result.Output))+partsNote(result.Parts))
+	}
+}
+
+func writeLine(b *strings.Builder, label, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(b, "[%s] %s\n", label, text)
+}
+
+// partsNote names attached non-text parts, whose bytes are not summarized.
+func partsNote(parts []agentic.ContentPart) string {
+	var note string
+	for _, part := range parts {
+		switch part.Type {
+		case agentic.PartText:
+			note += "\n" + part.Text
+		default:
+			note += fmt.Sprintf(" (%s %s attached)", part.MIMEType, part.Type)
+		}
+	}
+	return note
+}
+
+func (c *Compactor) clip(text string) string {
+	result := truncate.Head(text, truncate.Options{MaxBytes: c.maxToolOutput})
+	if !result.Truncated {
+		return text
+	}
+	return fmt.Sprintf("%s\n[... %d of %d bytes omitted]", result.Content, result.TotalBytes-result.OutputBytes, result.TotalBytes)
+}
+
+// limit cuts summary at a line boundary until it fits in maxTokens.
+func (c *Compactor) limit(summary string) string {
+	for c.counter.Count(summary) > c.maxTokens {
+		keep := len(summary) * c.maxTokens / (c.counter.Count(summary) + 1)
+		keep -= len(truncatedMarker)
+		if keep <= 0 {
+			return strings.TrimSpace(truncatedMarker)
+		}
+		cut := strings.LastIndexByte(summary[:keep], '\n')
+		if cut <= 0 {
+			cut = keep
+		}
+		summary = strings.ToValidUTF8(summary[:cut], "") + truncatedMarker
+	}
+	return summary
+}
@@ -0,0 +1,91 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/context/budget"
+	"github.com/victorarias/agentic-weave/agentic/loop"
+	"github.com/victorarias/agentic-weave/agentic/message"
+)
+
+type recordingDecider struct {
+	reply string
+	err   error
+	in    loop.Input
+}
+
+func (d *recordingDecider) Decide(_ context.Context, in loop.Input) (loop.Decision, error) {
+	d.in = in
+	return loop.Decision{Reply: d.reply}, d.err
+}
+
+func TestCompactFoldsPreviousSummaryAndRendersTools(t *testing.T) {
+	decider := &recordingDecider{reply: "## Goals\nShip it."}
+	compactor := New(decider, Options{MaxToolOutput: 8})
+	messages := message.ToBudgetable([]message.AgentMessage{
+		{Role: message.RoleSystem, Content: "## Goals\nEarlier goal."},
+		{Role: message.RoleUser, Content: "read main.go"},
+		{Role: message.RoleAssistant, ToolCalls: []agentic.ToolCall{{Name: "read", Input: json.RawMessage(`{"path":"main.go"}`)}}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{Name: "read", Output: json.RawMessage(`"package main"`)}}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{Name: "bash", Error: &agentic.ToolError{Message: "exit 1"}}}},
+	})
+
+	summary, err := compactor.Compact(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != "## Goals\nShip it." {
+		t.Fatalf("unexpected summary %q", summary)
+	}
+	if decider.in.SystemPrompt != DefaultPrompt {
+		t.Fatalf("expected the default prompt")
+	}
+	request := decider.in.UserMessage
+	for _, want := range []string{
+		"<previous-summary>\n## Goals\nEarlier goal.\n</previous-summary>",
+		"[user] read main.go",
+		`[tool call read] {"path":`,
+		"[tool result read]",
+		"bytes omitted]",
+		"[tool error bash] exit 1",
+		"at most 2000 tokens",
+	} {
+		if !strings.Contains(request, want) {
+			t.Fatalf("expected %q in request:\n%s", want, request)
+		}
+	}
+	if strings.Contains(request, "[system]") {
+		t.Fatalf("expected the previous summary outside the transcript:\n%s", request)
+	}
+}
+
+func TestCompactLimitsSummaryLength(t *testing.T) {
+	long := strings.Repeat("- a fact worth keeping\n", 50)
+	compactor := New(&recordingDecider{reply: long}, Options{Prompt: "custom", MaxTokens: 40})
+
+	summary, err := compactor.Compact(context.Background(), []budget.Budgetable{message.AgentMessage{Role: message.RoleUser, Content: "hi"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := (budget.CharCounter{}).Count(summary); n > 40 {
+		t.Fatalf("expected at most 40 tokens, got %d", n)
+	}
+	if !strings.HasSuffix(summary, "[summary truncated]") || !strings.HasPrefix(summary, "- a fact worth keeping\n") {
+		t.Fatalf("unexpected truncated summary %q", summary)
+	}
+}
+
+func TestCompactReportsDeciderFailures(t *testing.T) {
+	msgs := []budget.Budgetable{message.AgentMessage{Role: message.RoleUser, Content: "hi"}}
+	if _, err := New(&recordingDecider{err: errors.New("boom")}, Options{}).Compact(context.Background(), msgs); err == nil || !strings.Contains(err.Error(), "summarize: boom") {
+		t.Fatalf("expected wrapped decider error, got %v", err)
+	}
+	if _, err := New(&recordingDecider{reply: "  "}, Options{}).Compact(context.Background(), msgs); err == nil {
+		t.Fatal("expected an error for an empty summary")
+	}
+}
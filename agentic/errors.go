@@ -1,9 +1,39 @@
 package agentic
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 var (
 	ErrToolNotFound     = errors.New("tool not found")
 	ErrSchemaMismatch   = errors.New("tool schema mismatch")
 	ErrCallerNotAllowed = errors.New("tool caller not allowed")
+	ErrApprovalRequired = errors.New("tool call requires approval")
+	ErrApprovalDenied   = errors.New("tool call denied")
+	ErrPolicyDenied     = errors.New("tool call denied by policy")
+	ErrInvalidInput     = errors.New("invalid tool input")
 )
+
+// ErrorCode maps an error to a ToolError code. A *ToolError in the chain keeps
+// its own code; well-known sentinels map to "denied", "invalid_input",
+// "canceled" and "timeout". Other errors have no code.
+func ErrorCode(err error) string {
+	var toolErr *ToolError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &toolErr) && toolErr.Code != "":
+		return toolErr.Code
+	case errors.Is(err, ErrApprovalDenied), errors.Is(err, ErrPolicyDenied):
+		return "denied"
+	case errors.Is(err, ErrInvalidInput):
+		return "invalid_input"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return ""
+	}
+}
@@ -1,6 +1,9 @@
 package events
 
-import "github.com/victorarias/agentic-weave/agentic"
+import (
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+)
 
 const (
 	AgentStart             = "agent_start"
@@ -9,21 +12,28 @@ const (
 	TurnEnd                = "turn_end"
 	MessageStart           = "message_start"
 	MessageUpdate          = "message_update"
+	ReasoningUpdate        = "reasoning_update"
 	MessageEnd             = "message_end"
+	UsageUpdate            = "usage_update"
 	ToolStart              = "tool_execution_start"
 	ToolEnd                = "tool_execution_end"
 	ContextCompactionStart = "context_compaction_start"
 	ContextCompactionEnd   = "context_compaction_end"
 	ToolOutputTruncated    = "tool_output_truncated"
+	DeciderRetry           = "decider_retry"
+	DeciderFailover        = "decider_failover"
 )
 
 // Event captures a simple agent lifecycle update.
 //
 // Field usage varies by event type:
 //   - ToolStart/ToolEnd: ToolCall contains the single tool being executed
+//   - MessageUpdate/ReasoningUpdate: Delta holds reply or reasoning text
 //   - MessageEnd: ToolCalls contains all tool calls in the assistant message (may be empty)
 //   - ToolOutputTruncated: ToolResult contains the pre-truncation result, Content has summary
+//   - UsageUpdate: Usage holds one decision's usage, TotalUsage the run total
 //   - ContextCompactionEnd: Content contains the compaction summary
+//   - DeciderRetry/DeciderFailover: Content describes the attempt and error
 type Event struct {
 	Type       string
 	MessageID  string
@@ -33,6 +43,8 @@ type Event struct {
 	ToolCall   *agentic.ToolCall   // single tool call (ToolStart/ToolEnd events)
 	ToolCalls  []agentic.ToolCall  // all tool calls in message (MessageEnd events)
 	ToolResult *agentic.ToolResult // tool execution result or pre-truncation data
+	Usage      *usage.Usage        // usage delta (UsageUpdate events)
+	TotalUsage *usage.Usage        // run total after the delta (UsageUpdate events)
 }
 
 // Sink consumes events (streaming, logging, UI).
@@ -3,7 +3,9 @@ package executor
 import (
 	"context"
 	"encoding/json"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/victorarias/agentic-weave/agentic"
 )
@@ -100,3 +102,43 @@ func TestParallelExecutorCanceledContext(t *testing.T) {
 		t.Fatalf("expected context error")
 	}
 }
+
+type peakExecutor struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (p *peakExecutor) ListTools(ctx context.Context) ([]agentic.ToolDefinition, error) {
+	return nil, nil
+}
+
+func (p *peakExecutor) Execute(ctx context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+	p.mu.Lock()
+	p.running++
+	if p.running > p.peak {
+		p.peak = p.running
+	}
+	p.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	p.mu.Lock()
+	p.running--
+	p.mu.Unlock()
+	return agentic.ToolResult{Name: call.Name}, nil
+}
+
+func TestParallelExecutorWithLimit(t *testing.T) {
+	inner := &peakExecutor{}
+	p := NewParallel(inner, nil).WithLimit(2)
+	calls := []agentic.ToolCall{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	results, err := p.ExecuteBatch(context.Background(), calls)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 4 || results[3].Name != "d" {
+		t.Fatalf("expected results in call order, got %#v", results)
+	}
+	if inner.peak != 2 {
+		t.Fatalf("expected peak concurrency 2, got %d", inner.peak)
+	}
+}
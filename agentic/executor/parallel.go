@@ -17,6 +17,7 @@ type BatchExecutor interface {
 type ParallelExecutor struct {
 	inner     agentic.ToolExecutor
 	allowlist map[string]struct{}
+	limit     int
 }
 
 // BatchError reports per-call failures from ExecuteBatch.
@@ -45,6 +46,13 @@ func NewParallel(inner agentic.ToolExecutor, allowlist []string) *ParallelExecut
 	return &ParallelExecutor{inner: inner, allowlist: allowed}
 }
 
+// WithLimit caps how many calls run at once; calls start in order. Zero
+// means no cap.
+func (p *ParallelExecutor) WithLimit(limit int) *ParallelExecutor {
+	p.limit = limit
+	return p
+}
+
 func (p *ParallelExecutor) ExecuteBatch(ctx context.Context, calls []agentic.ToolCall) ([]agentic.ToolResult, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -60,13 +68,24 @@ func (p *ParallelExecutor) ExecuteBatch(ctx context.Context, calls []agentic.Too
 		}
 	}
 
+	var sem chan struct{}
+	if p.limit > 0 {
+		sem = make(chan struct{}, p.limit)
+	}
 	var wg sync.WaitGroup
-	wg.Add(len(calls))
 	for i := range calls {
 		idx := i
 		call := calls[i]
+		if sem != nil {
+			// Take the slot here so calls start in order.
+			sem <- struct{}{}
+		}
+		wg.Add(1)
 		go func() {
 			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
 			if err := ctx.Err(); err != nil {
 				errors[idx] = err
 				return
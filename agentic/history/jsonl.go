@@ -0,0 +1,322 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/victorarias/agentic-weave/agentic/message"
+)
+
+// SyncPolicy controls when JSONLStore fsyncs appended messages.
+type SyncPolicy int
+
+const (
+	// SyncEveryAppend fsyncs after each Append (default).
+	SyncEveryAppend SyncPolicy = iota
+	// SyncInterval fsyncs at most once per JSONLOptions.SyncInterval.
+	SyncInterval
+	// SyncNone leaves flushing to the OS; call Sync or Close to force it.
+	SyncNone
+)
+
+// JSONLOptions configures a JSONLStore.
+type JSONLOptions struct {
+	Sync         SyncPolicy
+	SyncInterval time.Duration // used with SyncInterval (default 1s)
+	// KeepPrevious keeps the log replaced by Replace as <path>.prev.
+	KeepPrevious bool
+}
+
+// JSONLStore is an append-only JSON Lines history log. Appends write one line
+// each; Replace writes an atomic snapshot that becomes the new log. A torn
+// final line left by a crash is ignored on Load and truncated before the next
+// Append. It implements Rewriter.
+type JSONLStore struct {
+	path string
+	opts JSONLOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	lastSync time.Time
+	dirty    bool
+}
+
+// NewJSONLStore creates a store backed by the file at path.
+func NewJSONLStore(path string, opts JSONLOptions) (*JSONLStore, error) {
+	if path == "" {
+		return nil, errors.New("history: path is required")
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = time.Second
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &JSONLStore{path: path, opts: opts}, nil
+}
+
+// Path returns the log file path.
+func (s *JSONLStore) Path() string {
+	return s.path
+}
+
+// Append writes msg as a single line.
+func (s *JSONLStore) Append(ctx context.Context, msg message.AgentMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("history: encode message: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.openLocked(); err != nil {
+		return err
+	}
+	if _, err := s.file.Write(line); err != nil {
+		return fmt.Errorf("history: append %s: %w", s.path, err)
+	}
+	s.dirty = true
+	switch s.opts.Sync {
+	case SyncEveryAppend:
+		return s.syncLocked()
+	case SyncInterval:
+		if time.Since(s.lastSync) >= s.opts.SyncInterval {
+			return s.syncLocked()
+		}
+	}
+	return nil
+}
+
+// Load reads all complete lines.
+func (s *JSONLStore) Load(ctx context.Context) ([]message.AgentMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return decodeLines(s.path, data)
+}
+
+// Replace atomically swaps the log for a snapshot of messages.
+func (s *JSONLStore) Replace(ctx context.Context, messages []message.AgentMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, msg := range messages {
+		line, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("history: encode message: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.closeLocked(); err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := tmpFile.Name()
+	defer func() {
+		_ = os.Remove(tmp)
+	}()
+	if _, err := tmpFile.Write(buf.Bytes()); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if s.opts.KeepPrevious {
+		prev := s.path + ".prev"
+		_ = os.Remove(prev)
+		if err := os.Link(s.path, prev); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("history: rotate %s: %w", s.path, err)
+		}
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		// Windows does not always allow rename-over-existing semantics.
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Rename(tmp, s.path); err != nil {
+			return err
+		}
+	}
+	syncDir(filepath.Dir(s.path))
+	return nil
+}
+
+// Sync flushes pending appends to disk.
+func (s *JSONLStore) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.syncLocked()
+}
+
+// Close syncs and closes the log. The store reopens it on the next Append.
+func (s *JSONLStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeLocked()
+}
+
+// openLocked opens the log for appending, reopening it when another writer
+// replaced the file and truncating a torn final line.
+func (s *JSONLStore) openLocked() error {
+	if s.file != nil {
+		current, statErr := os.Stat(s.path)
+		open, err := s.file.Stat()
+		if statErr == nil && err == nil && os.SameFile(current, open) {
+			return nil
+		}
+		if err := s.closeLocked(); err != nil {
+			return err
+		}
+	}
+	if err := repairTail(s.path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.lastSync = time.Now()
+	return nil
+}
+
+func (s *JSONLStore) syncLocked() error {
+	if s.file == nil || !s.dirty {
+		return nil
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("history: sync %s: %w", s.path, err)
+	}
+	s.dirty = false
+	s.lastSync = time.Now()
+	return nil
+}
+
+func (s *JSONLStore) closeLocked() error {
+	if s.file == nil {
+		return nil
+	}
+	syncErr := s.syncLocked()
+	closeErr := s.file.Close()
+	s.file = nil
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
+
+func decodeLines(path string, data []byte) ([]message.AgentMessage, error) {
+	// Anything after the final newline is a torn write and is ignored.
+	if end := bytes.LastIndexByte(data, '\n'); end >= 0 {
+		data = data[:end+1]
+	} else {
+		data = nil
+	}
+	var out []message.AgentMessage
+	line := 0
+	for len(data) > 0 {
+		line++
+		next := bytes.IndexByte(data, '\n')
+		raw := bytes.TrimSpace(data[:next])
+		data = data[next+1:]
+		if len(raw) == 0 {
+			continue
+		}
+		var msg message.AgentMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return nil, fmt.Errorf("history: decode %s line %d: %w", path, line, err)
+		}
+		out = append(out, msg)
+	}
+	return out, nil
+}
+
+// repairTail truncates bytes after the last newline left by an interrupted append.
+func repairTail(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil
+	}
+	const chunk = 64 * 1024
+	buf := make([]byte, chunk)
+	end := size
+	for end > 0 {
+		start := max(end-chunk, 0)
+		n, err := file.ReadAt(buf[:end-start], start)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if idx := bytes.LastIndexByte(buf[:n], '\n'); idx >= 0 {
+			keep := start + int64(idx) + 1
+			if keep == size {
+				return nil
+			}
+			return truncateAndSync(file, keep)
+		}
+		end = start
+	}
+	return truncateAndSync(file, 0)
+}
+
+func truncateAndSync(file *os.File, size int64) error {
+	if err := file.Truncate(size); err != nil {
+		return fmt.Errorf("history: repair %s: %w", file.Name(), err)
+	}
+	return file.Sync()
+}
+
+// syncDir makes a rename durable where the platform supports it.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -0,0 +1,122 @@
+package history
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic/message"
+)
+
+func userMsg(content string) message.AgentMessage {
+	return message.AgentMessage{Role: message.RoleUser, Content: content}
+}
+
+func TestJSONLStoreAppendLoadReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "session.jsonl")
+	store, err := NewJSONLStore(path, JSONLOptions{KeepPrevious: true})
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	for _, content := range []string{"one", "two\nlines"} {
+		if err := store.Append(ctx, userMsg(content)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	loaded, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded) != 2 || loaded[1].Content != "two\nlines" {
+		t.Fatalf("unexpected messages: %#v", loaded)
+	}
+
+	if err := store.Replace(ctx, []message.AgentMessage{userMsg("summary")}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if err := store.Append(ctx, userMsg("after")); err != nil {
+		t.Fatalf("append after replace: %v", err)
+	}
+	loaded, _ = store.Load(ctx)
+	if len(loaded) != 2 || loaded[0].Content != "summary" || loaded[1].Content != "after" {
+		t.Fatalf("unexpected messages after replace: %#v", loaded)
+	}
+	prev, err := os.ReadFile(path + ".prev")
+	if err != nil || strings.Count(string(prev), "\n") != 2 {
+		t.Fatalf("expected previous log to be kept, got %q %v", prev, err)
+	}
+}
+
+func TestJSONLStoreRecoversTornLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	good := `{"role":"user","content":"kept"}` + "\n"
+	if err := os.WriteFile(path, []byte(good+`{"role":"user","cont`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	store, err := NewJSONLStore(path, JSONLOptions{Sync: SyncNone})
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	loaded, err := store.Load(ctx)
+	if err != nil || len(loaded) != 1 || loaded[0].Content != "kept" {
+		t.Fatalf("expected torn line to be ignored, got %#v %v", loaded, err)
+	}
+	if err := store.Append(ctx, userMsg("next")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := store.Sync(); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	loaded, err = store.Load(ctx)
+	if err != nil || len(loaded) != 2 || loaded[1].Content != "next" {
+		t.Fatalf("expected repaired log, got %#v %v", loaded, err)
+	}
+}
+
+func TestJSONLStoreRejectsCorruptMiddleLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	data := "not json\n" + `{"role":"user","content":"x"}` + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	store, err := NewJSONLStore(path, JSONLOptions{})
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	if _, err := store.Load(context.Background()); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("expected corrupt line error, got %v", err)
+	}
+}
+
+func TestJSONLStoreFollowsReplaceFromAnotherInstance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	a, _ := NewJSONLStore(path, JSONLOptions{Sync: SyncInterval})
+	b, _ := NewJSONLStore(path, JSONLOptions{})
+	defer a.Close()
+	defer b.Close()
+	ctx := context.Background()
+
+	if err := a.Append(ctx, userMsg("a1")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := b.Replace(ctx, nil); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if err := a.Append(ctx, userMsg("a2")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	loaded, _ := b.Load(ctx)
+	if len(loaded) != 1 || loaded[0].Content != "a2" {
+		t.Fatalf("expected append to land in the replaced log, got %#v", loaded)
+	}
+}
+
+var _ Rewriter = (*JSONLStore)(nil)
@@ -0,0 +1,351 @@
+// Package sqlstore provides a SQLite-backed history store that holds many
+// conversations. It uses the pure-Go modernc.org/sqlite driver.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/victorarias/agentic-weave/agentic/history"
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/toolscope"
+)
+
+// ErrNoConversation is returned when no conversation ID is available.
+var ErrNoConversation = errors.New("sqlstore: conversation id is required")
+
+// migrations are applied in order; never edit an entry once released.
+var migrations = []string{
+	`CREATE TABLE messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		conversation_id TEXT NOT NULL,
+		seq INTEGER NOT NULL,
+		role TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		deleted_at INTEGER,
+		payload TEXT NOT NULL,
+		UNIQUE (conversation_id, seq)
+	);
+	CREATE INDEX idx_messages_live ON messages (conversation_id, deleted_at, seq);
+	CREATE INDEX idx_messages_created ON messages (conversation_id, created_at);`,
+}
+
+// Store persists messages for many conversations in one database.
+// Writes are serialized so concurrent appends keep a gapless order.
+type Store struct {
+	db      *sql.DB
+	writeMu sync.Mutex
+	now     func() time.Time
+}
+
+// Open opens (or creates) a SQLite database file and applies migrations.
+func Open(ctx context.Context, path string) (*Store, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, errors.New("sqlstore: path is required")
+	}
+	query := url.Values{}
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Set("_txlock", "immediate")
+	db, err := sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("sqlstore: open: %w", err)
+	}
+	store, err := New(ctx, db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// New wraps an existing SQLite handle and applies migrations.
+func New(ctx context.Context, db *sql.DB) (*Store, error) {
+	if db == nil {
+		return nil, errors.New("sqlstore: db is required")
+	}
+	store := &Store{db: db, now: time.Now}
+	if err := store.migrate(ctx); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("sqlstore: migrate: %w", err)
+	}
+	var current int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("sqlstore: migrate: %w", err)
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("sqlstore: database schema version %d is newer than supported %d", current, len(migrations))
+	}
+	for version := current + 1; version <= len(migrations); version++ {
+		err := s.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migrations[version-1]); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, s.now().UnixNano())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("sqlstore: migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// SchemaVersion reports the applied migration version.
+func (s *Store) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+// Append stores msg at the end of a conversation.
+func (s *Store) Append(ctx context.Context, conversationID string, msg message.AgentMessage) error {
+	if conversationID == "" {
+		return ErrNoConversation
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		seq, err := nextSeq(ctx, tx, conversationID)
+		if err != nil {
+			return err
+		}
+		return s.insert(ctx, tx, conversationID, seq, msg)
+	})
+}
+
+// Load returns the live (non-compacted) messages of a conversation.
+func (s *Store) Load(ctx context.Context, conversationID string) ([]message.AgentMessage, error) {
+	if conversationID == "" {
+		return nil, ErrNoConversation
+	}
+	return s.query(ctx, `SELECT payload FROM messages
+		WHERE conversation_id = ? AND deleted_at IS NULL ORDER BY seq`, conversationID)
+}
+
+// LoadRange returns live messages created in [from, to). A zero bound is open.
+func (s *Store) LoadRange(ctx context.Context, conversationID string, from, to time.Time) ([]message.AgentMessage, error) {
+	if conversationID == "" {
+		return nil, ErrNoConversation
+	}
+	lower := int64(0)
+	if !from.IsZero() {
+		lower = from.UnixNano()
+	}
+	upper := int64(1<<63 - 1)
+	if !to.IsZero() {
+		upper = to.UnixNano()
+	}
+	return s.query(ctx, `SELECT payload FROM messages
+		WHERE conversation_id = ? AND deleted_at IS NULL AND created_at >= ? AND created_at < ?
+		ORDER BY seq`, conversationID, lower, upper)
+}
+
+// Replace soft-deletes the live messages of a conversation and stores
+// messages in their place. Replaced rows stay available via Compacted.
+func (s *Store) Replace(ctx context.Context, conversationID string, messages []message.AgentMessage) error {
+	if conversationID == "" {
+		return ErrNoConversation
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `UPDATE messages SET deleted_at = ?
+			WHERE conversation_id = ? AND deleted_at IS NULL`, s.now().UnixNano(), conversationID); err != nil {
+			return err
+		}
+		seq, err := nextSeq(ctx, tx, conversationID)
+		if err != nil {
+			return err
+		}
+		for i, msg := range messages {
+			if err := s.insert(ctx, tx, conversationID, seq+int64(i), msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Compacted returns messages soft-deleted by Replace, oldest first.
+func (s *Store) Compacted(ctx context.Context, conversationID string) ([]message.AgentMessage, error) {
+	if conversationID == "" {
+		return nil, ErrNoConversation
+	}
+	return s.query(ctx, `SELECT payload FROM messages
+		WHERE conversation_id = ? AND deleted_at IS NOT NULL ORDER BY seq`, conversationID)
+}
+
+// PurgeCompacted permanently removes messages soft-deleted before cutoff.
+func (s *Store) PurgeCompacted(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	res, err := s.db.ExecContext(ctx, `DELETE FROM messages WHERE deleted_at IS NOT NULL AND deleted_at < ?`, cutoff.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Conversations lists conversation IDs with live messages.
+func (s *Store) Conversations(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT DISTINCT conversation_id FROM messages
+		WHERE deleted_at IS NULL ORDER BY conversation_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// Conversation returns a history.Rewriter bound to one conversation.
+func (s *Store) Conversation(conversationID string) *Conversation {
+	return &Conversation{store: s, id: conversationID}
+}
+
+// Scoped returns a history.Rewriter that reads the conversation ID from the
+// context's toolscope.ToolScope on every call.
+func (s *Store) Scoped() *Scoped {
+	return &Scoped{store: s}
+}
+
+func (s *Store) insert(ctx context.Context, tx *sql.Tx, conversationID string, seq int64, msg message.AgentMessage) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("sqlstore: encode message: %w", err)
+	}
+	created := msg.Timestamp
+	if created.IsZero() {
+		created = s.now()
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO messages (conversation_id, seq, role, created_at, payload)
+		VALUES (?, ?, ?, ?, ?)`, conversationID, seq, msg.Role, created.UnixNano(), string(payload))
+	return err
+}
+
+func (s *Store) query(ctx context.Context, query string, args ...any) ([]message.AgentMessage, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []message.AgentMessage
+	for rows.Next() {
+		var payload string
+		if err := rows.Scan(&payload); err != nil {
+			return nil, err
+		}
+		var msg message.AgentMessage
+		if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+			return nil, fmt.Errorf("sqlstore: decode message: %w", err)
+		}
+		out = append(out, msg)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func nextSeq(ctx context.Context, tx *sql.Tx, conversationID string) (int64, error) {
+	var seq int64
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) + 1 FROM messages WHERE conversation_id = ?`, conversationID).Scan(&seq)
+	return seq, err
+}
+
+// Conversation is a history.Rewriter for a fixed conversation ID.
+type Conversation struct {
+	store *Store
+	id    string
+}
+
+var _ history.Rewriter = (*Conversation)(nil)
+
+// ID returns the conversation ID.
+func (c *Conversation) ID() string { return c.id }
+
+// Append implements history.Store.
+func (c *Conversation) Append(ctx context.Context, msg message.AgentMessage) error {
+	return c.store.Append(ctx, c.id, msg)
+}
+
+// Load implements history.Store.
+func (c *Conversation) Load(ctx context.Context) ([]message.AgentMessage, error) {
+	return c.store.Load(ctx, c.id)
+}
+
+// Replace implements history.Rewriter.
+func (c *Conversation) Replace(ctx context.Context, messages []message.AgentMessage) error {
+	return c.store.Replace(ctx, c.id, messages)
+}
+
+// Scoped is a history.Rewriter keyed by toolscope.ToolScope.ConversationID.
+type Scoped struct {
+	store *Store
+}
+
+var _ history.Rewriter = (*Scoped)(nil)
+
+// Append implements history.Store.
+func (s *Scoped) Append(ctx context.Context, msg message.AgentMessage) error {
+	return s.store.Append(ctx, conversationFromContext(ctx), msg)
+}
+
+// Load implements history.Store.
+func (s *Scoped) Load(ctx context.Context) ([]message.AgentMessage, error) {
+	return s.store.Load(ctx, conversationFromContext(ctx))
+}
+
+// Replace implements history.Rewriter.
+func (s *Scoped) Replace(ctx context.Context, messages []message.AgentMessage) error {
+	return s.store.Replace(ctx, conversationFromContext(ctx), messages)
+}
+
+func conversationFromContext(ctx context.Context) string {
+	scope, ok := toolscope.ScopeFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return scope.ConversationID
+}
@@ -0,0 +1,187 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/toolscope"
+)
+
+func openStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := Open(context.Background(), path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store, path
+}
+
+func msgAt(content string, at time.Time) message.AgentMessage {
+	return message.AgentMessage{Role: message.RoleUser, Content: content, Timestamp: at}
+}
+
+func TestStoreConversationsAreIsolated(t *testing.T) {
+	store, _ := openStore(t)
+	ctx := context.Background()
+	a, b := store.Conversation("a"), store.Conversation("b")
+	if err := a.Append(ctx, msgAt("a1", time.Time{})); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := b.Append(ctx, msgAt("b1", time.Time{})); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if err := a.Append(ctx, msgAt("a2", time.Time{})); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	loaded, err := a.Load(ctx)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].Content != "a1" || loaded[1].Content != "a2" {
+		t.Fatalf("unexpected messages: %#v", loaded)
+	}
+	ids, err := store.Conversations(ctx)
+	if err != nil || len(ids) != 2 || ids[0] != "a" || ids[1] != "b" {
+		t.Fatalf("unexpected conversations: %v %v", ids, err)
+	}
+}
+
+func TestStoreReplaceKeepsCompactedHistory(t *testing.T) {
+	store, _ := openStore(t)
+	ctx := context.Background()
+	conv := store.Conversation("c")
+	for _, content := range []string{"one", "two"} {
+		if err := conv.Append(ctx, msgAt(content, time.Time{})); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	if err := conv.Replace(ctx, []message.AgentMessage{msgAt("summary", time.Time{})}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if err := conv.Append(ctx, msgAt("three", time.Time{})); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	loaded, _ := conv.Load(ctx)
+	if len(loaded) != 2 || loaded[0].Content != "summary" || loaded[1].Content != "three" {
+		t.Fatalf("unexpected live messages: %#v", loaded)
+	}
+	compacted, err := store.Compacted(ctx, "c")
+	if err != nil {
+		t.Fatalf("compacted: %v", err)
+	}
+	if len(compacted) != 2 || compacted[0].Content != "one" {
+		t.Fatalf("unexpected compacted messages: %#v", compacted)
+	}
+	removed, err := store.PurgeCompacted(ctx, time.Now().Add(time.Minute))
+	if err != nil || removed != 2 {
+		t.Fatalf("purge: %d %v", removed, err)
+	}
+	if compacted, _ := store.Compacted(ctx, "c"); len(compacted) != 0 {
+		t.Fatalf("expected purged history, got %#v", compacted)
+	}
+}
+
+func TestStoreLoadRange(t *testing.T) {
+	store, _ := openStore(t)
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		if err := store.Append(ctx, "c", msgAt(fmt.Sprint(i), base.Add(time.Duration(i)*time.Hour))); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	loaded, err := store.LoadRange(ctx, "c", base.Add(time.Hour), base.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("range: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].Content != "1" || loaded[1].Content != "2" {
+		t.Fatalf("unexpected range: %#v", loaded)
+	}
+	loaded, _ = store.LoadRange(ctx, "c", base.Add(2*time.Hour), time.Time{})
+	if len(loaded) != 2 || loaded[1].Content != "3" {
+		t.Fatalf("unexpected open range: %#v", loaded)
+	}
+}
+
+func TestStoreConcurrentAppends(t *testing.T) {
+	store, path := openStore(t)
+	other, err := Open(context.Background(), path)
+	if err != nil {
+		t.Fatalf("open second handle: %v", err)
+	}
+	defer other.Close()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := range 40 {
+		target := store
+		if i%2 == 1 {
+			target = other
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- target.Append(ctx, "c", msgAt(fmt.Sprint(i), time.Time{}))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	loaded, err := store.Load(ctx, "c")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded) != 40 {
+		t.Fatalf("expected 40 messages, got %d", len(loaded))
+	}
+}
+
+func TestScopedUsesConversationFromContext(t *testing.T) {
+	store, _ := openStore(t)
+	scoped := store.Scoped()
+	if err := scoped.Append(context.Background(), msgAt("x", time.Time{})); !errors.Is(err, ErrNoConversation) {
+		t.Fatalf("expected ErrNoConversation, got %v", err)
+	}
+	ctx := toolscope.WithScope(context.Background(), toolscope.ToolScope{ConversationID: "scoped"})
+	if err := scoped.Append(ctx, msgAt("hello", time.Time{})); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	loaded, err := store.Load(context.Background(), "scoped")
+	if err != nil || len(loaded) != 1 || loaded[0].Content != "hello" {
+		t.Fatalf("unexpected messages: %#v %v", loaded, err)
+	}
+}
+
+func TestOpenReappliesNoMigrations(t *testing.T) {
+	store, path := openStore(t)
+	ctx := context.Background()
+	if err := store.Append(ctx, "c", msgAt("kept", time.Time{})); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	reopened, err := Open(ctx, path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	version, err := reopened.SchemaVersion(ctx)
+	if err != nil || version != len(migrations) {
+		t.Fatalf("unexpected schema version %d: %v", version, err)
+	}
+	loaded, _ := reopened.Load(ctx, "c")
+	if len(loaded) != 1 {
+		t.Fatalf("expected data to survive reopen, got %#v", loaded)
+	}
+}
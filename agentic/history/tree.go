@@ -0,0 +1,315 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/victorarias/agentic-weave/agentic/message"
+)
+
+// Brancher is a Store whose messages form a tree. Load returns the path from
+// the root to the active head.
+type Brancher interface {
+	Store
+	// Branches lists the leaves of the tree, oldest first.
+	Branches(ctx context.Context) ([]Branch, error)
+	// Checkout makes id the active head. Checking out a message that already
+	// has replies forks: the next Append starts a sibling branch. An empty id
+	// starts a new root.
+	Checkout(ctx context.Context, id string) error
+}
+
+// HeadStore persists the active head of a Tree. Backing stores implement it
+// optionally; without it the head reopens at the last stored message.
+type HeadStore interface {
+	LoadHead(ctx context.Context) (id string, ok bool, err error)
+	SaveHead(ctx context.Context, id string) error
+}
+
+// Branch describes a leaf of a Tree.
+type Branch struct {
+	Head    string // ID of the last message
+	Length  int    // messages from the root to Head
+	Updated time.Time
+	Preview string // content of the last user message on the branch
+	Active  bool
+}
+
+// Tree is a branching history over an append-only backing store. Every
+// message gets an ID and a ParentID pointing at the head it was appended to;
+// messages stored without IDs are linked in storage order. It implements
+// Rewriter and Brancher.
+type Tree struct {
+	backing Store
+
+	mu       sync.Mutex
+	loaded   bool
+	nodes    map[string]message.AgentMessage
+	order    []string
+	children map[string]int
+	head     string
+}
+
+// NewTree creates a tree backed by store. A nil store keeps it in memory.
+func NewTree(store Store) *Tree {
+	return &Tree{backing: store}
+}
+
+// Append stores msg under the active head and makes it the new head.
+func (t *Tree) Append(ctx context.Context, msg message.AgentMessage) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.loadLocked(ctx); err != nil {
+		return err
+	}
+	if msg.ID == "" {
+		msg.ID = message.NewID()
+	}
+	if _, exists := t.nodes[msg.ID]; exists {
+		return fmt.Errorf("history: duplicate message id %s", msg.ID)
+	}
+	if msg.ParentID == "" {
+		msg.ParentID = t.head
+	}
+	if _, ok := t.nodes[msg.ParentID]; msg.ParentID != "" && !ok {
+		return fmt.Errorf("history: unknown parent message %s", msg.ParentID)
+	}
+	if t.backing != nil {
+		if err := t.backing.Append(ctx, msg); err != nil {
+			return err
+		}
+	}
+	t.addLocked(msg)
+	return t.setHeadLocked(ctx, msg.ID)
+}
+
+// Load returns the active branch from the root to the head.
+func (t *Tree) Load(ctx context.Context) ([]message.AgentMessage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+	return t.pathLocked(t.head), nil
+}
+
+// Path returns the messages from the root to id.
+func (t *Tree) Path(ctx context.Context, id string) ([]message.AgentMessage, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+	if _, ok := t.nodes[id]; id != "" && !ok {
+		return nil, fmt.Errorf("history: unknown message %s", id)
+	}
+	return t.pathLocked(id), nil
+}
+
+// Head returns the active head ID.
+func (t *Tree) Head(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.loadLocked(ctx); err != nil {
+		return "", err
+	}
+	return t.head, nil
+}
+
+// Checkout implements Brancher.
+func (t *Tree) Checkout(ctx context.Context, id string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.loadLocked(ctx); err != nil {
+		return err
+	}
+	if _, ok := t.nodes[id]; id != "" && !ok {
+		return fmt.Errorf("history: unknown message %s", id)
+	}
+	return t.setHeadLocked(ctx, id)
+}
+
+// Branches implements Brancher.
+func (t *Tree) Branches(ctx context.Context) ([]Branch, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.loadLocked(ctx); err != nil {
+		return nil, err
+	}
+	var out []Branch
+	for _, id := range t.order {
+		if t.children[id] > 0 {
+			continue
+		}
+		path := t.pathLocked(id)
+		branch := Branch{
+			Head:    id,
+			Length:  len(path),
+			Updated: t.nodes[id].Timestamp,
+			Active:  id == t.head,
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			if path[i].Role == message.RoleUser {
+				branch.Preview = path[i].Content
+				break
+			}
+		}
+		out = append(out, branch)
+	}
+	return out, nil
+}
+
+// Replace rewrites the active branch as messages, starting from a new root,
+// and checks it out. Messages still used by other branches are kept, so
+// those branches stay reachable; messages whose IDs they use get new ones.
+// With a Rewriter backing store the log is rewritten; otherwise the new
+// branch is appended and the old one stays in storage.
+func (t *Tree) Replace(ctx context.Context, messages []message.AgentMessage) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.loadLocked(ctx); err != nil {
+		return err
+	}
+	rewriter, rewrite := t.backing.(Rewriter)
+	keep := t.otherBranchesLocked()
+	if !rewrite && t.backing != nil {
+		// Append-only storage still holds the old branch.
+		for id := range t.nodes {
+			keep[id] = struct{}{}
+		}
+	}
+
+	var retained []message.AgentMessage
+	for _, id := range t.order {
+		if _, ok := keep[id]; ok {
+			retained = append(retained, t.nodes[id])
+		}
+	}
+	used := make(map[string]struct{}, len(retained)+len(messages))
+	for _, msg := range retained {
+		used[msg.ID] = struct{}{}
+	}
+	branch := make([]message.AgentMessage, 0, len(messages))
+	parent := ""
+	for _, msg := range messages {
+		if _, exists := used[msg.ID]; msg.ID == "" || exists {
+			msg.ID = message.NewID()
+		}
+		used[msg.ID] = struct{}{}
+		msg.ParentID = parent
+		branch = append(branch, msg)
+		parent = msg.ID
+	}
+
+	if rewrite {
+		if err := rewriter.Replace(ctx, append(append([]message.AgentMessage(nil), retained...), branch...)); err != nil {
+			return err
+		}
+	} else if t.backing != nil {
+		for _, msg := range branch {
+			if err := t.backing.Append(ctx, msg); err != nil {
+				return err
+			}
+		}
+	}
+	t.nodes = make(map[string]message.AgentMessage, len(retained)+len(branch))
+	t.children = make(map[string]int)
+	t.order = nil
+	for _, msg := range retained {
+		t.addLocked(msg)
+	}
+	for _, msg := range branch {
+		t.addLocked(msg)
+	}
+	return t.setHeadLocked(ctx, parent)
+}
+
+// otherBranchesLocked returns the IDs on the paths to every leaf other than
+// the active head.
+func (t *Tree) otherBranchesLocked() map[string]struct{} {
+	keep := make(map[string]struct{})
+	for _, id := range t.order {
+		if t.children[id] > 0 || id == t.head {
+			continue
+		}
+		for _, msg := range t.pathLocked(id) {
+			keep[msg.ID] = struct{}{}
+		}
+	}
+	return keep
+}
+
+func (t *Tree) loadLocked(ctx context.Context) error {
+	if t.loaded {
+		return nil
+	}
+	t.nodes = make(map[string]message.AgentMessage)
+	t.children = make(map[string]int)
+	if t.backing != nil {
+		stored, err := t.backing.Load(ctx)
+		if err != nil {
+			return err
+		}
+		previous := ""
+		for i, msg := range stored {
+			if msg.ID == "" {
+				// Messages written before IDs existed form a linear chain.
+				msg.ID = fmt.Sprintf("legacy-%d", i)
+				msg.ParentID = previous
+			}
+			t.addLocked(msg)
+			previous = msg.ID
+		}
+		t.head = previous
+		if heads, ok := t.backing.(HeadStore); ok {
+			id, found, err := heads.LoadHead(ctx)
+			if err != nil {
+				return err
+			}
+			if _, exists := t.nodes[id]; found && (id == "" || exists) {
+				t.head = id
+			}
+		}
+	}
+	t.loaded = true
+	return nil
+}
+
+func (t *Tree) addLocked(msg message.AgentMessage) {
+	t.nodes[msg.ID] = msg
+	t.order = append(t.order, msg.ID)
+	if msg.ParentID != "" {
+		t.children[msg.ParentID]++
+	}
+}
+
+func (t *Tree) setHeadLocked(ctx context.Context, id string) error {
+	t.head = id
+	if heads, ok := t.backing.(HeadStore); ok {
+		return heads.SaveHead(ctx, id)
+	}
+	return nil
+}
+
+func (t *Tree) pathLocked(id string) []message.AgentMessage {
+	var path []message.AgentMessage
+	seen := make(map[string]struct{})
+	for id != "" {
+		msg, ok := t.nodes[id]
+		if !ok {
+			break
+		}
+		if _, loop := seen[id]; loop {
+			break
+		}
+		seen[id] = struct{}{}
+		path = append(path, msg)
+		id = msg.ParentID
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
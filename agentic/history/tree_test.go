@@ -0,0 +1,169 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic/message"
+)
+
+func contents(messages []message.AgentMessage) []string {
+	out := make([]string, len(messages))
+	for i, msg := range messages {
+		out[i] = msg.Content
+	}
+	return out
+}
+
+func TestTreeForkKeepsOriginalBranch(t *testing.T) {
+	ctx := context.Background()
+	tree := NewTree(NewMemoryStore())
+	for _, content := range []string{"q1", "a1", "q2", "a2"} {
+		if err := tree.Append(ctx, userMsg(content)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	path, _ := tree.Load(ctx)
+	original := path[len(path)-1].ID
+
+	if err := tree.Checkout(ctx, path[1].ID); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	if err := tree.Append(ctx, userMsg("q2 retry")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	path, _ = tree.Load(ctx)
+	if got := contents(path); len(got) != 3 || got[2] != "q2 retry" {
+		t.Fatalf("unexpected forked path: %v", got)
+	}
+
+	branches, err := tree.Branches(ctx)
+	if err != nil {
+		t.Fatalf("branches: %v", err)
+	}
+	if len(branches) != 2 || branches[0].Head != original || branches[0].Length != 4 || !branches[1].Active {
+		t.Fatalf("unexpected branches: %#v", branches)
+	}
+	if branches[1].Preview != "q2 retry" {
+		t.Fatalf("unexpected preview %q", branches[1].Preview)
+	}
+
+	if err := tree.Checkout(ctx, original); err != nil {
+		t.Fatalf("checkout original: %v", err)
+	}
+	path, _ = tree.Load(ctx)
+	if got := contents(path); len(got) != 4 || got[3] != "a2" {
+		t.Fatalf("unexpected original path: %v", got)
+	}
+	if err := tree.Checkout(ctx, "missing"); err == nil {
+		t.Fatalf("expected error for unknown message")
+	}
+}
+
+func TestTreeReplaceRewritesActiveBranch(t *testing.T) {
+	ctx := context.Background()
+	tree := NewTree(nil)
+	_ = tree.Append(ctx, userMsg("one"))
+	_ = tree.Append(ctx, userMsg("two"))
+	path, _ := tree.Load(ctx)
+
+	if err := tree.Replace(ctx, []message.AgentMessage{{Role: message.RoleSystem, Content: "summary"}, path[1]}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	compacted, _ := tree.Load(ctx)
+	if got := contents(compacted); len(got) != 2 || got[0] != "summary" || got[1] != "two" {
+		t.Fatalf("unexpected compacted path: %v", got)
+	}
+	if compacted[1].ID != path[1].ID || compacted[0].ParentID != "" || compacted[1].ParentID != compacted[0].ID {
+		t.Fatalf("expected the branch rewritten from a new root: %#v", compacted)
+	}
+	if _, err := tree.Path(ctx, path[0].ID); err == nil {
+		t.Fatal("expected the compacted message to be gone")
+	}
+	if branches, _ := tree.Branches(ctx); len(branches) != 1 {
+		t.Fatalf("expected one branch, got %#v", branches)
+	}
+
+	if err := tree.Replace(ctx, nil); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if cleared, _ := tree.Load(ctx); len(cleared) != 0 {
+		t.Fatalf("expected empty active branch, got %v", contents(cleared))
+	}
+}
+
+func TestTreeReplaceKeepsOtherBranchesAndRewritesLog(t *testing.T) {
+	ctx := context.Background()
+	log, err := NewJSONLStore(filepath.Join(t.TempDir(), "tree.jsonl"), JSONLOptions{})
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer log.Close()
+	tree := NewTree(log)
+	_ = tree.Append(ctx, userMsg("root"))
+	_ = tree.Append(ctx, userMsg("old"))
+	path, _ := tree.Load(ctx)
+	if err := tree.Checkout(ctx, path[0].ID); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	_ = tree.Append(ctx, userMsg("active"))
+	active, _ := tree.Load(ctx)
+
+	for range 3 {
+		current, _ := tree.Load(ctx)
+		if err := tree.Replace(ctx, []message.AgentMessage{{Role: message.RoleSystem, Content: "summary"}, current[len(current)-1]}); err != nil {
+			t.Fatalf("replace: %v", err)
+		}
+	}
+
+	stored, _ := log.Load(ctx)
+	if len(stored) != 4 {
+		t.Fatalf("expected the log to hold the old branch and the compacted one, got %v", contents(stored))
+	}
+	if old, err := tree.Path(ctx, path[1].ID); err != nil || len(old) != 2 {
+		t.Fatalf("expected the other branch to survive: %v %v", contents(old), err)
+	}
+	reopened := NewTree(log)
+	got, _ := reopened.Load(ctx)
+	if c := contents(got); len(c) != 2 || c[0] != "summary" || c[1] != "active" || got[1].ID != active[1].ID {
+		t.Fatalf("unexpected reloaded branch: %v", c)
+	}
+	if branches, _ := reopened.Branches(ctx); len(branches) != 2 {
+		t.Fatalf("expected two branches, got %#v", branches)
+	}
+}
+
+func TestTreeReloadsFromJSONL(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tree.jsonl")
+	log, err := NewJSONLStore(path, JSONLOptions{})
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer log.Close()
+	// Messages written before IDs existed are chained in order.
+	_ = log.Append(ctx, userMsg("legacy"))
+
+	tree := NewTree(log)
+	_ = tree.Append(ctx, userMsg("first"))
+	loaded, _ := tree.Load(ctx)
+	_ = tree.Append(ctx, userMsg("second"))
+	if err := tree.Checkout(ctx, loaded[1].ID); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	_ = tree.Append(ctx, userMsg("fork"))
+
+	reopened := NewTree(log)
+	got, err := reopened.Load(ctx)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if c := contents(got); len(c) != 3 || c[0] != "legacy" || c[2] != "fork" {
+		t.Fatalf("unexpected reloaded path: %v", c)
+	}
+	branches, _ := reopened.Branches(ctx)
+	if len(branches) != 2 {
+		t.Fatalf("expected two branches, got %#v", branches)
+	}
+}
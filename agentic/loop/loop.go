@@ -2,18 +2,23 @@ package loop
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/victorarias/agentic-weave/agentic"
 	"github.com/victorarias/agentic-weave/agentic/context/budget"
 	"github.com/victorarias/agentic-weave/agentic/events"
+	"github.com/victorarias/agentic-weave/agentic/executor"
 	"github.com/victorarias/agentic-weave/agentic/history"
 	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/spill"
 	"github.com/victorarias/agentic-weave/agentic/truncate"
 	"github.com/victorarias/agentic-weave/agentic/usage"
+	"github.com/victorarias/agentic-weave/capabilities"
 )
 
 // Decider chooses between replying or calling tools.
@@ -21,6 +26,21 @@ type Decider interface {
 	Decide(ctx context.Context, in Input) (Decision, error)
 }
 
+// StreamDelta is an incremental chunk reported by a StreamingDecider.
+// Reasoning carries thinking text, kept apart from the reply Text.
+type StreamDelta struct {
+	Text      string
+	Reasoning string
+}
+
+// StreamingDecider is a Decider that can report partial output while deciding.
+// The loop prefers DecideStream when the configured Decider implements it and
+// forwards each delta as a MessageUpdate or ReasoningUpdate event.
+type StreamingDecider interface {
+	Decider
+	DecideStream(ctx context.Context, in Input, onDelta func(StreamDelta)) (Decision, error)
+}
+
 // Input captures state for a single decision step.
 type Input struct {
 	SystemPrompt string
@@ -30,14 +50,21 @@ type Input struct {
 	ToolCalls    []agentic.ToolCall
 	ToolResults  []agentic.ToolResult
 	Turn         int
+	// Reasoning requests extended thinking; nil leaves the provider default.
+	Reasoning *capabilities.Reasoning
 }
 
 // Decision is the result of a decision step.
 type Decision struct {
 	Reply      string
+	Reasoning  string // provider reasoning/thinking text, when available
 	ToolCalls  []agentic.ToolCall
 	Usage      *usage.Usage
 	StopReason usage.StopReason
+	Model      string
+	// ProviderBlocks are stored on the assistant message so providers can
+	// replay them (for example signed thinking blocks).
+	ProviderBlocks []message.ProviderBlock
 }
 
 // Config controls the loop behavior.
@@ -51,13 +78,42 @@ type Config struct {
 	Events         events.Sink
 	MaxTurns       int
 	ToolCallerType string
+	// MaxParallelTools caps how many tool calls from a single decision run
+	// concurrently. Values <= 1 execute calls sequentially.
+	MaxParallelTools int
+	// ToolSearch hides DeferLoad tools behind a search_tools meta-tool.
+	// Nil sends the full ListTools result every turn.
+	ToolSearch *ToolSearchConfig
+	// Reasoning is passed to the decider on every turn.
+	Reasoning *capabilities.Reasoning
+	// ToolTruncation overrides Truncation and TruncationMode by tool name.
+	ToolTruncation map[string]ToolTruncation
+	// Spill saves the full output of truncated tool results and points the
+	// model at it. Register spill.NewTool(store) with the Executor so the
+	// model can page through the saved output.
+	Spill spill.Store
+}
+
+// ToolTruncation configures truncation for one tool.
+type ToolTruncation struct {
+	Options truncate.Options
+	// Mode defaults to Config.TruncationMode.
+	Mode truncate.Mode
+	// Disabled leaves the tool's output untouched.
+	Disabled bool
 }
 
 // Request provides the conversation input.
 type Request struct {
 	SystemPrompt string
 	UserMessage  string
-	History      []message.AgentMessage
+	// UserParts attaches multimodal content, such as images, to the user
+	// message. Providers read it from the recorded history message.
+	UserParts []agentic.ContentPart
+	History   []message.AgentMessage
+	// Head continues the conversation from this message ID, forking when it
+	// already has replies. It requires a history.Brancher HistoryStore.
+	Head string
 }
 
 // Result captures the final output.
@@ -67,14 +123,21 @@ type Result struct {
 	Summary     string
 	ToolCalls   []agentic.ToolCall
 	ToolResults []agentic.ToolResult
-	Usage       *usage.Usage
-	StopReason  usage.StopReason
-	Exhausted   bool // true when the loop exited because it hit MaxTurns
+	// Usage sums every decision of the run; SessionUsage sums every run of
+	// the Runner. Both are nil when the decider reports no usage.
+	Usage        *usage.Usage
+	SessionUsage *usage.Usage
+	StopReason   usage.StopReason
+	Exhausted    bool // true when the loop exited because it hit MaxTurns
 }
 
 // Runner executes a tool-aware loop with optional compaction and truncation.
 type Runner struct {
-	cfg Config
+	cfg    Config
+	emitMu sync.Mutex
+
+	usageMu sync.Mutex
+	session *usage.Usage
 }
 
 // New creates a new Runner.
@@ -91,30 +154,107 @@ func New(cfg Config) *Runner {
 	return &Runner{cfg: cfg}
 }
 
+// Usage returns the usage summed across all runs, or nil if none was reported.
+func (r *Runner) Usage() *usage.Usage {
+	r.usageMu.Lock()
+	defer r.usageMu.Unlock()
+	return copyUsage(r.session)
+}
+
+// addUsage adds a decision's usage to the run total and the session total,
+// and reports it as a UsageUpdate event.
+func (r *Runner) addUsage(msgID string, total **usage.Usage, delta *usage.Usage) {
+	if delta == nil {
+		return
+	}
+	normalized := usage.Normalize(*delta)
+	*total = sumUsage(*total, normalized)
+	r.usageMu.Lock()
+	r.session = sumUsage(r.session, normalized)
+	r.usageMu.Unlock()
+	r.emit(events.Event{
+		Type:       events.UsageUpdate,
+		MessageID:  msgID,
+		Role:       message.RoleAssistant,
+		Usage:      &normalized,
+		TotalUsage: copyUsage(*total),
+	})
+}
+
+func sumUsage(total *usage.Usage, delta usage.Usage) *usage.Usage {
+	if total == nil {
+		return &delta
+	}
+	sum := usage.Add(*total, delta)
+	return &sum
+}
+
+func copyUsage(u *usage.Usage) *usage.Usage {
+	if u == nil {
+		return nil
+	}
+	out := *u
+	return &out
+}
+
 // emit sends an event if a sink is configured.
+// Emission is serialized so sinks never observe concurrent calls.
 func (r *Runner) emit(e events.Event) {
 	if r.cfg.Events != nil {
+		r.emitMu.Lock()
+		defer r.emitMu.Unlock()
 		r.cfg.Events.Emit(e)
 	}
 }
 
+// decide calls the configured Decider, streaming deltas as MessageStart,
+// MessageUpdate and ReasoningUpdate events when it implements StreamingDecider.
+func (r *Runner) decide(ctx context.Context, msgID string, in Input) (Decision, error) {
+	streamer, ok := r.cfg.Decider.(StreamingDecider)
+	if !ok {
+		return r.cfg.Decider.Decide(ctx, in)
+	}
+	r.emit(events.Event{Type: events.MessageStart, MessageID: msgID, Role: message.RoleAssistant})
+	return streamer.DecideStream(ctx, in, func(delta StreamDelta) {
+		if delta.Reasoning != "" {
+			r.emit(events.Event{
+				Type:      events.ReasoningUpdate,
+				MessageID: msgID,
+				Role:      message.RoleAssistant,
+				Delta:     delta.Reasoning,
+			})
+		}
+		if delta.Text != "" {
+			r.emit(events.Event{
+				Type:      events.MessageUpdate,
+				MessageID: msgID,
+				Role:      message.RoleAssistant,
+				Delta:     delta.Text,
+			})
+		}
+	})
+}
+
 // recordAssistantMessage stores an assistant message in history and emits MessageEnd.
-func (r *Runner) recordAssistantMessage(ctx context.Context, turn int, reply string, toolCalls []agentic.ToolCall, history *[]message.AgentMessage, isFinal bool) error {
+func (r *Runner) recordAssistantMessage(ctx context.Context, msgID string, decision Decision, toolCalls []agentic.ToolCall, history *[]message.AgentMessage) error {
+	reply := decision.Reply
 	msg := message.AgentMessage{
-		Role:      message.RoleAssistant,
-		Content:   reply,
-		ToolCalls: toolCalls,
-		Timestamp: time.Now(),
+		ID:             msgID,
+		Role:           message.RoleAssistant,
+		Content:        reply,
+		Reasoning:      decision.Reasoning,
+		ToolCalls:      toolCalls,
+		Timestamp:      time.Now(),
+		Usage:          decision.Usage,
+		StopReason:     decision.StopReason,
+		Model:          decision.Model,
+		ProviderBlocks: decision.ProviderBlocks,
 	}
 	*history = append(*history, msg)
 	if err := r.appendHistory(ctx, msg); err != nil {
 		return err
 	}
 
-	msgID := fmt.Sprintf("msg-%d", turn)
-	if isFinal {
-		msgID = fmt.Sprintf("msg-final-%d", turn)
-	}
 	r.emit(events.Event{
 		Type:      events.MessageEnd,
 		MessageID: msgID,
@@ -145,10 +285,12 @@ func (r *Runner) Run(ctx context.Context, req Request) (Result, error) {
 	}
 
 	userMessage := strings.TrimSpace(req.UserMessage)
-	if userMessage != "" {
+	if userMessage != "" || len(req.UserParts) > 0 {
 		userMsg := message.AgentMessage{
+			ID:        message.NewID(),
 			Role:      message.RoleUser,
 			Content:   userMessage,
+			Parts:     req.UserParts,
 			Timestamp: time.Now(),
 		}
 		historyMessages = append(historyMessages, userMsg)
@@ -157,12 +299,13 @@ func (r *Runner) Run(ctx context.Context, req Request) (Result, error) {
 		}
 	}
 
-	summary, historyMessages, err := r.applyCompaction(ctx, historyMessages)
+	summary, historyMessages, _, err := r.applyCompaction(ctx, historyMessages)
 	if err != nil {
 		return Result{}, err
 	}
 
-	tools, err := r.listTools(ctx)
+	search := newToolSearchState(r.cfg.ToolSearch, r.cfg.Executor)
+	tools, err := r.listTools(ctx, search)
 	if err != nil {
 		return Result{}, err
 	}
@@ -172,8 +315,15 @@ func (r *Runner) Run(ctx context.Context, req Request) (Result, error) {
 
 	turn := 0
 	runID := time.Now().UnixNano()
+	var runUsage *usage.Usage
 	for {
-		decision, err := r.cfg.Decider.Decide(ctx, Input{
+		msgID := message.NewID()
+		if search != nil && turn > 0 {
+			if tools, err = r.listTools(ctx, search); err != nil {
+				return Result{}, err
+			}
+		}
+		decision, err := r.decide(ctx, msgID, Input{
 			SystemPrompt: req.SystemPrompt,
 			UserMessage:  userMessage,
 			History:      historyMessages,
@@ -181,10 +331,12 @@ func (r *Runner) Run(ctx context.Context, req Request) (Result, error) {
 			ToolCalls:    toolCalls,
 			ToolResults:  toolResults,
 			Turn:         turn,
+			Reasoning:    r.cfg.Reasoning,
 		})
 		if err != nil {
 			return Result{}, err
 		}
+		r.addUsage(msgID, &runUsage, decision.Usage)
 
 		for i := range decision.ToolCalls {
 			if decision.ToolCalls[i].ID == "" {
@@ -196,84 +348,56 @@ func (r *Runner) Run(ctx context.Context, req Request) (Result, error) {
 		}
 
 		if len(decision.ToolCalls) == 0 {
-			if err := r.recordAssistantMessage(ctx, turn, decision.Reply, nil, &historyMessages, true); err != nil {
+			if err := r.recordAssistantMessage(ctx, msgID, decision, nil, &historyMessages); err != nil {
 				return Result{}, err
 			}
 
 			return Result{
-				Reply:       decision.Reply,
-				History:     historyMessages,
-				Summary:     summary,
-				ToolCalls:   toolCalls,
-				ToolResults: toolResults,
-				Usage:       decision.Usage,
-				StopReason:  decision.StopReason,
-				Exhausted:   false,
+				Reply:        decision.Reply,
+				History:      historyMessages,
+				Summary:      summary,
+				ToolCalls:    toolCalls,
+				ToolResults:  toolResults,
+				Usage:        runUsage,
+				SessionUsage: r.Usage(),
+				StopReason:   decision.StopReason,
+				Exhausted:    false,
 			}, nil
 		}
 
 		if turn >= r.cfg.MaxTurns {
-			if err := r.recordAssistantMessage(ctx, turn, decision.Reply, decision.ToolCalls, &historyMessages, true); err != nil {
+			if err := r.recordAssistantMessage(ctx, msgID, decision, decision.ToolCalls, &historyMessages); err != nil {
 				return Result{}, err
 			}
 			return Result{
-				Reply:       decision.Reply,
-				History:     historyMessages,
-				Summary:     summary,
-				ToolCalls:   append(toolCalls, decision.ToolCalls...),
-				ToolResults: toolResults,
-				Usage:       decision.Usage,
-				StopReason:  decision.StopReason,
-				Exhausted:   true,
+				Reply:        decision.Reply,
+				History:      historyMessages,
+				Summary:      summary,
+				ToolCalls:    append(toolCalls, decision.ToolCalls...),
+				ToolResults:  toolResults,
+				Usage:        runUsage,
+				SessionUsage: r.Usage(),
+				StopReason:   decision.StopReason,
+				Exhausted:    true,
 			}, nil
 		}
 
-		if r.cfg.Executor == nil {
+		if r.cfg.Executor == nil && search == nil {
 			return Result{}, errors.New("loop: tool calls requested but no executor configured")
 		}
 
-		if err := r.recordAssistantMessage(ctx, turn, decision.Reply, decision.ToolCalls, &historyMessages, false); err != nil {
+		if err := r.recordAssistantMessage(ctx, msgID, decision, decision.ToolCalls, &historyMessages); err != nil {
 			return Result{}, err
 		}
 
-		for _, call := range decision.ToolCalls {
-			r.emit(events.Event{Type: events.ToolStart, ToolCall: &call})
-
-			result, err := r.cfg.Executor.Execute(ctx, call)
-			if err != nil {
-				result = agentic.ToolResult{
-					ID:    call.ID,
-					Name:  call.Name,
-					Error: &agentic.ToolError{Message: err.Error()},
-				}
-			}
-			if result.ID == "" {
-				result.ID = call.ID
-			}
-			if result.Name == "" {
-				result.Name = call.Name
-			}
-
-			if r.cfg.Truncation != nil {
-				before := result
-				trunc := truncate.Result{}
-				result, trunc = truncateToolResult(result, r.cfg.TruncationMode, *r.cfg.Truncation)
-				if trunc.Truncated {
-					r.emit(events.Event{
-						Type:       events.ToolOutputTruncated,
-						ToolResult: &before,
-						Content:    truncSummary(trunc),
-					})
-				}
-			}
-
-			r.emit(events.Event{Type: events.ToolEnd, ToolResult: &result})
-
-			toolCalls = append(toolCalls, call)
+		results := r.executeTools(ctx, decision.ToolCalls, search)
+		for i, result := range results {
+			toolCalls = append(toolCalls, decision.ToolCalls[i])
 			toolResults = append(toolResults, result)
 
 			// Add tool result as structured message
 			toolMsg := message.AgentMessage{
+				ID:          message.NewID(),
 				Role:        message.RoleTool,
 				ToolResults: []agentic.ToolResult{result},
 				Timestamp:   time.Now(),
@@ -283,15 +407,142 @@ func (r *Runner) Run(ctx context.Context, req Request) (Result, error) {
 				return Result{}, err
 			}
 		}
+
+		// Tool results can push the context over budget mid-run.
+		turnSummary, compacted, changed, err := r.applyCompaction(ctx, historyMessages)
+		if err != nil {
+			return Result{}, err
+		}
+		if changed {
+			historyMessages = compacted
+		}
+		if turnSummary != "" {
+			summary = turnSummary
+		}
 		turn++
 	}
 }
 
+// executeTools runs the calls of a single decision and returns their results
+// in call order. Calls run concurrently through executor.ParallelExecutor when
+// MaxParallelTools > 1.
+func (r *Runner) executeTools(ctx context.Context, calls []agentic.ToolCall, search *toolSearchState) []agentic.ToolResult {
+	limit := r.cfg.MaxParallelTools
+	if limit <= 1 || len(calls) <= 1 {
+		results := make([]agentic.ToolResult, len(calls))
+		for i, call := range calls {
+			results[i] = r.executeTool(ctx, call, search)
+		}
+		return results
+	}
+
+	// executeTool turns failures into error results, so ExecuteBatch only
+	// fails when ctx is canceled; calls it skipped get the context error.
+	batch := executor.NewParallel(runnerTools{r, search}, nil).WithLimit(limit)
+	results, err := batch.ExecuteBatch(ctx, calls)
+	if err == nil {
+		return results
+	}
+	if results == nil {
+		results = make([]agentic.ToolResult, len(calls))
+	}
+	for i, call := range calls {
+		if results[i].ID == "" && results[i].Name == "" {
+			results[i] = errorResult(call, err)
+		}
+	}
+	return results
+}
+
+// runnerTools adapts executeTool to agentic.ToolExecutor for ParallelExecutor.
+type runnerTools struct {
+	r      *Runner
+	search *toolSearchState
+}
+
+func (t runnerTools) ListTools(ctx context.Context) ([]agentic.ToolDefinition, error) {
+	return nil, nil
+}
+
+func (t runnerTools) Execute(ctx context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+	return t.r.executeTool(ctx, call, t.search), nil
+}
+
+// executeTool runs a single call, emitting ToolStart/ToolEnd around it.
+// Executor errors are converted into tool error results.
+func (r *Runner) executeTool(ctx context.Context, call agentic.ToolCall, search *toolSearchState) agentic.ToolResult {
+	r.emit(events.Event{Type: events.ToolStart, ToolCall: &call})
+
+	var result agentic.ToolResult
+	var err error
+	handled := false
+	if search != nil {
+		result, handled, err = search.execute(ctx, call)
+	}
+	if !handled {
+		if r.cfg.Executor == nil {
+			err = fmt.Errorf("%w: %s", agentic.ErrToolNotFound, call.Name)
+		} else {
+			result, err = r.cfg.Executor.Execute(ctx, call)
+		}
+	}
+	if err != nil {
+		result = errorResult(call, err)
+	}
+	if result.ID == "" {
+		result.ID = call.ID
+	}
+	if result.Name == "" {
+		result.Name = call.Name
+	}
+
+	if opts, mode, ok := r.truncationFor(call.Name); ok {
+		before := result
+		trunc := truncate.Result{}
+		result, trunc = truncateToolResult(result, mode, opts)
+		if trunc.Truncated {
+			summary := truncSummary(trunc)
+			if r.cfg.Spill != nil && call.Name != spill.ToolName {
+				ref, err := r.cfg.Spill.Save(ctx, call.Name, before.Output)
+				if err != nil {
+					summary += "; spill failed: " + err.Error()
+				} else {
+					result.Output = spillReference(result.Output, ref, trunc)
+					summary += "; full output saved as " + ref
+				}
+			}
+			r.emit(events.Event{
+				Type:       events.ToolOutputTruncated,
+				ToolResult: &before,
+				Content:    summary,
+			})
+		}
+	}
+
+	r.emit(events.Event{Type: events.ToolEnd, ToolCall: &call, ToolResult: &result})
+	return result
+}
+
+func errorResult(call agentic.ToolCall, err error) agentic.ToolResult {
+	return agentic.ToolResult{
+		ID:    call.ID,
+		Name:  call.Name,
+		Error: &agentic.ToolError{Message: err.Error(), Code: agentic.ErrorCode(err)},
+	}
+}
+
 func (r *Runner) validateConfig() error {
+	if search := r.cfg.ToolSearch; search != nil && search.Fetcher != nil && registrarFor(search, r.cfg.Executor) == nil {
+		return errors.New("loop: tool search with a fetcher needs a registrar (an executor such as *agentic.Registry, or ToolSearchConfig.Registrar)")
+	}
 	if r.cfg.Budget == nil || r.cfg.HistoryStore == nil {
 		return nil
 	}
-	if r.cfg.Budget.Counter == nil || r.cfg.Budget.Compactor == nil || r.cfg.Budget.Policy.ContextWindow <= 0 {
+	mgr := r.cfg.Budget
+	if mgr.Counter == nil || mgr.Policy.ContextWindow <= 0 {
+		return nil
+	}
+	if mgr.Compactor == nil && mgr.Policy.PruneToolOutputBytes <= 0 {
 		return nil
 	}
 	if _, ok := r.cfg.HistoryStore.(history.Rewriter); !ok {
@@ -300,7 +551,10 @@ func (r *Runner) validateConfig() error {
 	return nil
 }
 
-func (r *Runner) listTools(ctx context.Context) ([]agentic.ToolDefinition, error) {
+func (r *Runner) listTools(ctx context.Context, search *toolSearchState) ([]agentic.ToolDefinition, error) {
+	if search != nil {
+		return search.tools(ctx)
+	}
 	if r.cfg.Executor == nil {
 		return nil, nil
 	}
@@ -308,6 +562,15 @@ func (r *Runner) listTools(ctx context.Context) ([]agentic.ToolDefinition, error
 }
 
 func (r *Runner) loadHistory(ctx context.Context, req Request) ([]message.AgentMessage, error) {
+	if req.Head != "" {
+		brancher, ok := r.cfg.HistoryStore.(history.Brancher)
+		if !ok {
+			return nil, errors.New("loop: request head requires a history.Brancher store")
+		}
+		if err := brancher.Checkout(ctx, req.Head); err != nil {
+			return nil, err
+		}
+	}
 	if r.cfg.HistoryStore == nil {
 		return append([]message.AgentMessage(nil), req.History...), nil
 	}
@@ -331,20 +594,22 @@ func (r *Runner) appendHistory(ctx context.Context, msg message.AgentMessage) er
 	return r.cfg.HistoryStore.Append(ctx, msg)
 }
 
-func (r *Runner) applyCompaction(ctx context.Context, messages []message.AgentMessage) (string, []message.AgentMessage, error) {
+// applyCompaction compacts messages when over budget and reports whether they
+// changed. Pruning alone changes them without producing a summary.
+func (r *Runner) applyCompaction(ctx context.Context, messages []message.AgentMessage) (string, []message.AgentMessage, bool, error) {
 	if r.cfg.Budget == nil {
-		return "", messages, nil
+		return "", messages, false, nil
 	}
 	r.emit(events.Event{Type: events.ContextCompactionStart})
 
 	compacted, summary, changed, err := message.CompactIfNeeded(ctx, *r.cfg.Budget, messages)
 	if err != nil {
 		r.emit(events.Event{Type: events.ContextCompactionEnd})
-		return "", messages, err
+		return "", messages, false, err
 	}
 	if !changed {
 		r.emit(events.Event{Type: events.ContextCompactionEnd})
-		return "", messages, nil
+		return "", messages, false, nil
 	}
 
 	r.emit(events.Event{Type: events.ContextCompactionEnd, Content: summary})
@@ -352,12 +617,12 @@ func (r *Runner) applyCompaction(ctx context.Context, messages []message.AgentMe
 	if r.cfg.HistoryStore != nil {
 		if rewriter, ok := r.cfg.HistoryStore.(history.Rewriter); ok {
 			if err := rewriter.Replace(ctx, compacted); err != nil {
-				return "", messages, err
+				return "", messages, false, err
 			}
 		}
 	}
 
-	return summary, compacted, nil
+	return summary, compacted, true, nil
 }
 
 // extractToolsFromHistory extracts tool calls and results from history messages.
@@ -371,15 +636,55 @@ func extractToolsFromHistory(messages []message.AgentMessage) ([]agentic.ToolCal
 	return calls, results
 }
 
+// truncationFor returns the truncation settings for a tool, and false when
+// its output should be left alone.
+func (r *Runner) truncationFor(name string) (truncate.Options, truncate.Mode, bool) {
+	if rule, ok := r.cfg.ToolTruncation[name]; ok {
+		if rule.Disabled {
+			return truncate.Options{}, "", false
+		}
+		mode := rule.Mode
+		if mode == "" {
+			mode = r.cfg.TruncationMode
+		}
+		return rule.Options, mode, true
+	}
+	if r.cfg.Truncation == nil {
+		return truncate.Options{}, "", false
+	}
+	return *r.cfg.Truncation, r.cfg.TruncationMode, true
+}
+
 func truncateToolResult(result agentic.ToolResult, mode truncate.Mode, opts truncate.Options) (agentic.ToolResult, truncate.Result) {
 	switch mode {
 	case truncate.ModeHead:
 		return truncate.HeadToolResult(result, opts)
+	case truncate.ModeMiddle:
+		return truncate.MiddleToolResult(result, opts)
+	case truncate.ModeJSON:
+		return truncate.JSONToolResult(result, opts)
 	default:
 		return truncate.TailToolResult(result, opts)
 	}
 }
 
+// spillReference tells the model where the full output was saved. Valid JSON
+// output is wrapped in an object so it stays valid; text gets a note appended.
+func spillReference(output []byte, ref string, trunc truncate.Result) []byte {
+	note := fmt.Sprintf("Output truncated. The full output (%d lines, %d bytes) is saved as %q; call %s with {\"ref\": %q, \"offset\": 1} to page through it.",
+		trunc.TotalLines, trunc.TotalBytes, ref, spill.ToolName, ref)
+	if json.Valid(output) {
+		wrapped, err := json.Marshal(struct {
+			Output json.RawMessage `json:"output"`
+			Note   string          `json:"note"`
+		}{output, note})
+		if err == nil {
+			return wrapped
+		}
+	}
+	return append(append([]byte(nil), output...), "\n\n["+note+"]"...)
+}
+
 func truncSummary(result truncate.Result) string {
 	if !result.Truncated {
 		return ""
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/victorarias/agentic-weave/agentic"
@@ -12,7 +13,10 @@ import (
 	"github.com/victorarias/agentic-weave/agentic/events"
 	"github.com/victorarias/agentic-weave/agentic/history"
 	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/spill"
 	"github.com/victorarias/agentic-weave/agentic/truncate"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+	"github.com/victorarias/agentic-weave/capabilities"
 )
 
 type stepDecider struct {
@@ -276,6 +280,56 @@ func TestMessageEndEventWithToolCalls(t *testing.T) {
 	}
 }
 
+type metadataDecider struct{}
+
+func (metadataDecider) Decide(ctx context.Context, in Input) (Decision, error) {
+	return Decision{
+		Reply:          "done",
+		Usage:          &usage.Usage{Input: 2, Output: 1, Total: 3},
+		StopReason:     usage.StopReasonStop,
+		Model:          "model-x",
+		ProviderBlocks: []message.ProviderBlock{{Provider: "p", Type: "thinking", Data: json.RawMessage(`{}`)}},
+	}, nil
+}
+
+func TestRunRecordsAssistantMetadata(t *testing.T) {
+	var endID string
+	sink := events.SinkFunc(func(e events.Event) {
+		if e.Type == events.MessageEnd {
+			endID = e.MessageID
+		}
+	})
+	store := history.NewMemoryStore()
+	runner := New(Config{Decider: metadataDecider{}, HistoryStore: store, Events: sink})
+	if _, err := runner.Run(context.Background(), Request{UserMessage: "hi"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, _ := store.Load(context.Background())
+	if len(stored) != 2 || stored[0].ID == "" || stored[0].ID == stored[1].ID {
+		t.Fatalf("expected distinct message IDs, got %#v", stored)
+	}
+	reply := stored[1]
+	if reply.ID != endID {
+		t.Fatalf("expected MessageEnd ID %q to match stored ID %q", endID, reply.ID)
+	}
+	if reply.Usage == nil || reply.Usage.Total != 3 || reply.StopReason != usage.StopReasonStop || reply.Model != "model-x" || len(reply.ProviderBlocks) != 1 {
+		t.Fatalf("expected decision metadata on assistant message, got %#v", reply)
+	}
+}
+
+func TestRunRecordsUserParts(t *testing.T) {
+	store := history.NewMemoryStore()
+	runner := New(Config{Decider: &replyDecider{reply: "a cat"}, HistoryStore: store})
+	image := agentic.ImagePart("image/png", []byte("png"))
+	if _, err := runner.Run(context.Background(), Request{UserParts: []agentic.ContentPart{image}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored, _ := store.Load(context.Background())
+	if len(stored) != 2 || stored[0].Role != message.RoleUser || len(stored[0].Parts) != 1 || stored[0].Parts[0].MIMEType != "image/png" {
+		t.Fatalf("expected image-only user message in history, got %#v", stored)
+	}
+}
+
 func TestExhausted_FalseOnNaturalStop(t *testing.T) {
 	runner := New(Config{
 		Decider:  &replyDecider{reply: "done"},
@@ -350,6 +404,38 @@ func TestRunMergesRequestHistoryWithStoreHistory(t *testing.T) {
 	}
 }
 
+func TestRunForksFromRequestHead(t *testing.T) {
+	ctx := context.Background()
+	tree := history.NewTree(nil)
+	runner := New(Config{
+		Decider:      &replyDecider{reply: "ok"},
+		HistoryStore: tree,
+	})
+	if _, err := runner.Run(ctx, Request{UserMessage: "first"}); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if _, err := runner.Run(ctx, Request{UserMessage: "second"}); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	path, _ := tree.Load(ctx)
+	result, err := runner.Run(ctx, Request{UserMessage: "second retry", Head: path[1].ID})
+	if err != nil {
+		t.Fatalf("forked run: %v", err)
+	}
+	if len(result.History) != 4 || result.History[2].Content != "second retry" {
+		t.Fatalf("unexpected forked history: %#v", result.History)
+	}
+	branches, _ := tree.Branches(ctx)
+	if len(branches) != 2 {
+		t.Fatalf("expected original and forked branches, got %#v", branches)
+	}
+
+	plain := New(Config{Decider: &replyDecider{reply: "ok"}, HistoryStore: history.NewMemoryStore()})
+	if _, err := plain.Run(ctx, Request{UserMessage: "hi", Head: "x"}); err == nil {
+		t.Fatalf("expected error for head without a brancher store")
+	}
+}
+
 func TestRunReturnsErrorWhenHistoryAppendFails(t *testing.T) {
 	store := &failingAppendStore{err: errors.New("append failed")}
 	runner := New(Config{
@@ -514,3 +600,586 @@ func (s *failingReplaceStore) Load(_ context.Context) ([]message.AgentMessage, e
 func (s *failingReplaceStore) Replace(_ context.Context, _ []message.AgentMessage) error {
 	return s.err
 }
+
+type parallelDecider struct {
+	calls int
+}
+
+func (d *parallelDecider) Decide(ctx context.Context, in Input) (Decision, error) {
+	if d.calls == 0 {
+		d.calls++
+		return Decision{
+			ToolCalls: []agentic.ToolCall{
+				{Name: "slow", Input: json.RawMessage(`{"n":1}`)},
+				{Name: "fail", Input: json.RawMessage(`{"n":2}`)},
+				{Name: "fast", Input: json.RawMessage(`{"n":3}`)},
+			},
+		}, nil
+	}
+	return Decision{Reply: "done"}, nil
+}
+
+type concurrencyExecutor struct {
+	mu      sync.Mutex
+	active  int
+	peak    int
+	started chan struct{}
+	release chan struct{}
+}
+
+func (e *concurrencyExecutor) ListTools(ctx context.Context) ([]agentic.ToolDefinition, error) {
+	return []agentic.ToolDefinition{{Name: "slow"}, {Name: "fail"}, {Name: "fast"}}, nil
+}
+
+func (e *concurrencyExecutor) Execute(ctx context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+	e.mu.Lock()
+	e.active++
+	if e.active > e.peak {
+		e.peak = e.active
+	}
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		e.active--
+		e.mu.Unlock()
+	}()
+
+	if call.Name == "slow" {
+		close(e.started)
+		<-e.release
+	}
+	if call.Name == "fail" {
+		<-e.started
+		close(e.release)
+		return agentic.ToolResult{}, errors.New("boom")
+	}
+	return agentic.ToolResult{Name: call.Name, Output: json.RawMessage(`"` + call.Name + `"`)}, nil
+}
+
+func TestRunExecutesToolsInParallel(t *testing.T) {
+	exec := &concurrencyExecutor{started: make(chan struct{}), release: make(chan struct{})}
+	var mu sync.Mutex
+	starts := map[string]int{}
+	ends := map[string]int{}
+	sink := events.SinkFunc(func(e events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch e.Type {
+		case events.ToolStart:
+			starts[e.ToolCall.ID]++
+		case events.ToolEnd:
+			ends[e.ToolResult.ID]++
+		}
+	})
+
+	runner := New(Config{
+		Decider:          &parallelDecider{},
+		Executor:         exec,
+		Events:           sink,
+		MaxParallelTools: 2,
+	})
+
+	result, err := runner.Run(context.Background(), Request{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exec.peak != 2 {
+		t.Fatalf("expected peak concurrency 2, got %d", exec.peak)
+	}
+
+	names := []string{"slow", "fail", "fast"}
+	if len(result.ToolResults) != len(names) {
+		t.Fatalf("expected %d tool results, got %d", len(names), len(result.ToolResults))
+	}
+	for i, name := range names {
+		if result.ToolResults[i].Name != name {
+			t.Fatalf("result %d: expected %q, got %q", i, name, result.ToolResults[i].Name)
+		}
+		if result.ToolResults[i].ID != result.ToolCalls[i].ID {
+			t.Fatalf("result %d: id mismatch %q != %q", i, result.ToolResults[i].ID, result.ToolCalls[i].ID)
+		}
+		if starts[result.ToolCalls[i].ID] != 1 || ends[result.ToolCalls[i].ID] != 1 {
+			t.Fatalf("expected one start/end event for %q", name)
+		}
+	}
+	if result.ToolResults[1].Error == nil || result.ToolResults[1].Error.Message != "boom" {
+		t.Fatalf("expected failing call to be recorded as tool error, got %#v", result.ToolResults[1])
+	}
+	if string(result.ToolResults[2].Output) != `"fast"` {
+		t.Fatalf("expected fast output to survive sibling failure, got %q", result.ToolResults[2].Output)
+	}
+
+	var toolNames []string
+	for _, msg := range result.History {
+		if msg.Role == message.RoleTool {
+			toolNames = append(toolNames, msg.ToolResults[0].Name)
+		}
+	}
+	if strings.Join(toolNames, ",") != "slow,fail,fast" {
+		t.Fatalf("expected history in call order, got %v", toolNames)
+	}
+}
+
+type streamingDecider struct {
+	chunks []string
+}
+
+func (d *streamingDecider) Decide(ctx context.Context, in Input) (Decision, error) {
+	return Decision{Reply: strings.Join(d.chunks, "")}, nil
+}
+
+func (d *streamingDecider) DecideStream(ctx context.Context, in Input, onDelta func(StreamDelta)) (Decision, error) {
+	for _, chunk := range d.chunks {
+		onDelta(StreamDelta{Text: chunk})
+	}
+	return Decision{Reply: strings.Join(d.chunks, "")}, nil
+}
+
+func TestRunStreamsDeltasFromStreamingDecider(t *testing.T) {
+	var evts []events.Event
+	sink := events.SinkFunc(func(e events.Event) {
+		evts = append(evts, e)
+	})
+
+	runner := New(Config{
+		Decider: &streamingDecider{chunks: []string{"hel", "lo"}},
+		Events:  sink,
+	})
+
+	result, err := runner.Run(context.Background(), Request{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Reply != "hello" {
+		t.Fatalf("unexpected reply: %q", result.Reply)
+	}
+
+	var types []string
+	var deltas []string
+	ids := map[string]struct{}{}
+	for _, e := range evts {
+		switch e.Type {
+		case events.MessageStart, events.MessageUpdate, events.MessageEnd:
+			types = append(types, e.Type)
+			ids[e.MessageID] = struct{}{}
+			if e.Type == events.MessageUpdate {
+				deltas = append(deltas, e.Delta)
+			}
+		}
+	}
+	want := []string{events.MessageStart, events.MessageUpdate, events.MessageUpdate, events.MessageEnd}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected message events: %v", types)
+	}
+	if strings.Join(deltas, "") != "hello" {
+		t.Fatalf("unexpected deltas: %v", deltas)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("expected a single message id across events, got %v", ids)
+	}
+}
+
+type namedTool struct {
+	def agentic.ToolDefinition
+}
+
+func (t namedTool) Definition() agentic.ToolDefinition { return t.def }
+
+func (t namedTool) Execute(_ context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+	return agentic.ToolResult{ID: call.ID, Name: call.Name, Output: json.RawMessage(`"` + t.def.Name + ` ok"`)}, nil
+}
+
+type searcherFunc func(ctx context.Context, query string) ([]agentic.ToolDefinition, error)
+
+func (f searcherFunc) SearchTools(ctx context.Context, query string) ([]agentic.ToolDefinition, error) {
+	return f(ctx, query)
+}
+
+type fetcherFunc func(ctx context.Context, name string) (agentic.Tool, error)
+
+func (f fetcherFunc) FetchTool(ctx context.Context, name string) (agentic.Tool, error) {
+	return f(ctx, name)
+}
+
+type toolSearchDecider struct {
+	seen [][]string
+}
+
+func (d *toolSearchDecider) Decide(_ context.Context, in Input) (Decision, error) {
+	names := make([]string, 0, len(in.Tools))
+	for _, def := range in.Tools {
+		names = append(names, def.Name)
+	}
+	d.seen = append(d.seen, names)
+	switch in.Turn {
+	case 0:
+		return Decision{ToolCalls: []agentic.ToolCall{{Name: SearchToolsName, Input: json.RawMessage(`{"query":"weather deploy"}`)}}}, nil
+	case 1:
+		return Decision{ToolCalls: []agentic.ToolCall{{Name: "weather"}, {Name: "deploy"}}}, nil
+	default:
+		return Decision{Reply: "done"}, nil
+	}
+}
+
+func TestRunWithToolSearchSurfacesDeferredTools(t *testing.T) {
+	reg := agentic.NewRegistry()
+	if err := reg.Register(namedTool{def: agentic.ToolDefinition{Name: "read"}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := reg.Register(namedTool{def: agentic.ToolDefinition{Name: "deploy", DeferLoad: true}}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	weather := namedTool{def: agentic.ToolDefinition{Name: "weather", Description: "Get the weather", DeferLoad: true}}
+
+	decider := &toolSearchDecider{}
+	runner := New(Config{
+		Decider:  decider,
+		Executor: reg,
+		MaxTurns: 5,
+		ToolSearch: &ToolSearchConfig{
+			Searcher: searcherFunc(func(_ context.Context, query string) ([]agentic.ToolDefinition, error) {
+				return []agentic.ToolDefinition{weather.def, {Name: "deploy"}, {Name: "missing"}}, nil
+			}),
+			Fetcher: fetcherFunc(func(_ context.Context, name string) (agentic.Tool, error) {
+				if name == "weather" {
+					return weather, nil
+				}
+				return nil, errors.New("not found")
+			}),
+		},
+	})
+
+	result, err := runner.Run(context.Background(), Request{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if result.Reply != "done" {
+		t.Fatalf("unexpected reply: %q", result.Reply)
+	}
+	if got := strings.Join(decider.seen[0], ","); got != "read,search_tools" {
+		t.Fatalf("expected deferred tools hidden on first turn, got %s", got)
+	}
+	if got := strings.Join(decider.seen[1], ","); got != "deploy,read,weather,search_tools" {
+		t.Fatalf("expected surfaced tools on second turn, got %s", got)
+	}
+	if len(result.ToolResults) != 3 {
+		t.Fatalf("expected three tool results, got %#v", result.ToolResults)
+	}
+	if out := string(result.ToolResults[0].Output); out != `{"tools":[{"name":"weather","description":"Get the weather"},{"name":"deploy"}]}` {
+		t.Fatalf("unexpected search output: %s", out)
+	}
+	for _, res := range result.ToolResults[1:] {
+		if res.Error != nil {
+			t.Fatalf("unexpected tool error: %#v", res.Error)
+		}
+	}
+	if _, err := reg.Execute(context.Background(), agentic.ToolCall{Name: "weather"}); err != nil {
+		t.Fatalf("expected fetched tool to be registered: %v", err)
+	}
+}
+
+type denyCallPolicy struct {
+	name string
+}
+
+func (p denyCallPolicy) AllowTool(agentic.ToolDefinition) error { return nil }
+
+func (p denyCallPolicy) AllowCall(def agentic.ToolDefinition, _ agentic.ToolCall) error {
+	if def.Name == p.name {
+		return agentic.ErrPolicyDenied
+	}
+	return nil
+}
+
+type executorOnly struct {
+	agentic.ToolExecutor
+}
+
+func TestRunKeepsDeferredToolsBehindRegistryPolicy(t *testing.T) {
+	reg := agentic.NewRegistry(agentic.WithPolicy(denyCallPolicy{name: "deploy"}))
+	deploy := namedTool{def: agentic.ToolDefinition{Name: "deploy", DeferLoad: true}}
+	search := &ToolSearchConfig{
+		Searcher: searcherFunc(func(context.Context, string) ([]agentic.ToolDefinition, error) {
+			return []agentic.ToolDefinition{deploy.def}, nil
+		}),
+		Fetcher: fetcherFunc(func(context.Context, string) (agentic.Tool, error) { return deploy, nil }),
+	}
+	runner := New(Config{Decider: &toolSearchDecider{}, Executor: reg, MaxTurns: 5, ToolSearch: search})
+
+	result, err := runner.Run(context.Background(), Request{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	var deployResult *agentic.ToolResult
+	for i := range result.ToolResults {
+		if result.ToolResults[i].Name == "deploy" {
+			deployResult = &result.ToolResults[i]
+		}
+	}
+	if deployResult == nil || deployResult.Error == nil || deployResult.Error.Code != "denied" {
+		t.Fatalf("expected the fetched tool to stay denied, got %#v", result.ToolResults)
+	}
+
+	unregistered := New(Config{Decider: &toolSearchDecider{}, Executor: executorOnly{reg}, ToolSearch: search})
+	if _, err := unregistered.Run(context.Background(), Request{UserMessage: "hi"}); err == nil || !strings.Contains(err.Error(), "registrar") {
+		t.Fatalf("expected a fetcher without a registrar to be rejected, got %v", err)
+	}
+}
+
+type reasoningDecider struct {
+	got *capabilities.Reasoning
+}
+
+func (d *reasoningDecider) Decide(ctx context.Context, in Input) (Decision, error) {
+	return d.DecideStream(ctx, in, func(StreamDelta) {})
+}
+
+func (d *reasoningDecider) DecideStream(_ context.Context, in Input, onDelta func(StreamDelta)) (Decision, error) {
+	d.got = in.Reasoning
+	onDelta(StreamDelta{Reasoning: "thinking"})
+	onDelta(StreamDelta{Text: "done"})
+	return Decision{Reply: "done", Reasoning: "thinking"}, nil
+}
+
+func TestRunEmitsReasoningDeltasSeparately(t *testing.T) {
+	var types []string
+	sink := events.SinkFunc(func(e events.Event) {
+		if e.Type == events.ReasoningUpdate || e.Type == events.MessageUpdate {
+			types = append(types, e.Type+":"+e.Delta)
+		}
+	})
+	decider := &reasoningDecider{}
+	reasoning := &capabilities.Reasoning{Effort: capabilities.ReasoningHigh}
+	store := history.NewMemoryStore()
+	runner := New(Config{Decider: decider, Events: sink, HistoryStore: store, Reasoning: reasoning})
+	if _, err := runner.Run(context.Background(), Request{UserMessage: "hi"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decider.got != reasoning {
+		t.Fatalf("expected reasoning config on input, got %#v", decider.got)
+	}
+	want := []string{events.ReasoningUpdate + ":thinking", events.MessageUpdate + ":done"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected events: %v", types)
+	}
+	stored, _ := store.Load(context.Background())
+	if len(stored) != 2 || stored[1].Reasoning != "thinking" {
+		t.Fatalf("expected reasoning on assistant message, got %#v", stored)
+	}
+}
+
+type usageDecider struct {
+	histories [][]message.AgentMessage
+}
+
+func (d *usageDecider) Decide(_ context.Context, in Input) (Decision, error) {
+	d.histories = append(d.histories, in.History)
+	if in.Turn == 0 {
+		return Decision{
+			ToolCalls: []agentic.ToolCall{{Name: "echo", Input: json.RawMessage(`{"text":"hello"}`)}},
+			Usage:     &usage.Usage{Input: 10, Output: 2},
+		}, nil
+	}
+	return Decision{Reply: "done", Usage: &usage.Usage{Input: 20, Output: 3, CacheRead: 5}}, nil
+}
+
+func TestRunSumsUsageAcrossTurnsAndRuns(t *testing.T) {
+	var updates []events.Event
+	runner := New(Config{
+		Decider:  &usageDecider{},
+		Executor: stubExecutor{},
+		Events: events.SinkFunc(func(e events.Event) {
+			if e.Type == events.UsageUpdate {
+				updates = append(updates, e)
+			}
+		}),
+	})
+
+	result, err := runner.Run(context.Background(), Request{UserMessage: "ping"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := usage.Usage{Input: 30, Output: 5, Total: 40, CacheRead: 5}
+	if result.Usage == nil || *result.Usage != want {
+		t.Fatalf("expected run usage %+v, got %+v", want, result.Usage)
+	}
+	if len(updates) != 2 || updates[0].Usage.Total != 12 || *updates[1].TotalUsage != want {
+		t.Fatalf("unexpected usage events: %+v", updates)
+	}
+
+	result, err = runner.Run(context.Background(), Request{UserMessage: "again"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Usage.Total != 40 || result.SessionUsage == nil || result.SessionUsage.Total != 80 || runner.Usage().Total != 80 {
+		t.Fatalf("expected session usage to span runs, got run %+v session %+v", result.Usage, result.SessionUsage)
+	}
+}
+
+func TestRunCompactsBetweenTurns(t *testing.T) {
+	compactor := &recordingCompactor{summary: "summary"}
+	decider := &usageDecider{}
+	runner := New(Config{
+		Decider:      decider,
+		Executor:     stubExecutor{},
+		HistoryStore: history.NewMemoryStore(),
+		Budget: &budget.Manager{
+			Counter:   budget.CharCounter{},
+			Compactor: compactor,
+			Policy:    budget.Policy{ContextWindow: 5, KeepLast: 1},
+		},
+	})
+
+	result, err := runner.Run(context.Background(), Request{UserMessage: "ping"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decider.histories) != 2 || len(decider.histories[0]) != 1 {
+		t.Fatalf("expected no compaction before the first turn, got %v", decider.histories)
+	}
+	second := decider.histories[1]
+	if len(second) != 3 || second[0].Role != message.RoleSystem || second[1].Role != message.RoleAssistant || second[2].Role != message.RoleTool {
+		t.Fatalf("expected compacted history keeping the tool call with its result, got %+v", second)
+	}
+	if result.Summary != "summary" || len(compactor.last) != 1 {
+		t.Fatalf("unexpected compaction: summary %q, compacted %d", result.Summary, len(compactor.last))
+	}
+}
+
+type countingStore struct {
+	*history.MemoryStore
+	replaces int
+}
+
+func (s *countingStore) Replace(ctx context.Context, messages []message.AgentMessage) error {
+	s.replaces++
+	return s.MemoryStore.Replace(ctx, messages)
+}
+
+type repeatToolDecider struct {
+	histories [][]message.AgentMessage
+}
+
+func (d *repeatToolDecider) Decide(_ context.Context, in Input) (Decision, error) {
+	d.histories = append(d.histories, in.History)
+	if in.Turn < 2 {
+		return Decision{ToolCalls: []agentic.ToolCall{{Name: "logs"}}}, nil
+	}
+	return Decision{Reply: "done"}, nil
+}
+
+func TestRunAdoptsPrunedHistory(t *testing.T) {
+	output, _ := json.Marshal(strings.Repeat("log line\n", 200))
+	decider := &repeatToolDecider{}
+	store := &countingStore{MemoryStore: history.NewMemoryStore()}
+	runner := New(Config{
+		Decider:      decider,
+		Executor:     outputExecutor{outputs: map[string]string{"logs": string(output)}},
+		HistoryStore: store,
+		Budget: &budget.Manager{
+			Counter: budget.CharCounter{},
+			Policy:  budget.Policy{ContextWindow: 600, KeepLast: 1, PruneToolOutputBytes: 256},
+		},
+	})
+
+	if _, err := runner.Run(context.Background(), Request{UserMessage: "go"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(decider.histories) != 3 {
+		t.Fatalf("expected 3 decisions, got %d", len(decider.histories))
+	}
+	last := decider.histories[2]
+	if len(last) != 5 || last[2].Role != message.RoleTool {
+		t.Fatalf("unexpected history: %+v", last)
+	}
+	if got := len(last[2].ToolResults[0].Output); got > 256 {
+		t.Fatalf("expected the old tool output to be pruned, got %d bytes", got)
+	}
+	if got := len(last[4].ToolResults[0].Output); got != len(output) {
+		t.Fatalf("expected the latest tool output to be kept, got %d bytes", got)
+	}
+	if store.replaces != 1 {
+		t.Fatalf("expected one history replace, got %d", store.replaces)
+	}
+}
+
+type outputExecutor struct {
+	outputs map[string]string
+}
+
+func (e outputExecutor) ListTools(context.Context) ([]agentic.ToolDefinition, error) {
+	var defs []agentic.ToolDefinition
+	for name := range e.outputs {
+		defs = append(defs, agentic.ToolDefinition{Name: name})
+	}
+	return defs, nil
+}
+
+func (e outputExecutor) Execute(_ context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+	return agentic.ToolResult{Name: call.Name, Output: json.RawMessage(e.outputs[call.Name])}, nil
+}
+
+type callBothDecider struct {
+	calls int
+}
+
+func (d *callBothDecider) Decide(context.Context, Input) (Decision, error) {
+	d.calls++
+	if d.calls > 1 {
+		return Decision{Reply: "done"}, nil
+	}
+	return Decision{ToolCalls: []agentic.ToolCall{{Name: "logs"}, {Name: "raw"}}}, nil
+}
+
+func TestRunAppliesPerToolTruncationAndSpills(t *testing.T) {
+	logs := strings.TrimSuffix(strings.Repeat("log line\n", 50), "\n")
+	store := spill.NewMemoryStore()
+	var truncated []events.Event
+	runner := New(Config{
+		Decider:    &callBothDecider{},
+		Executor:   outputExecutor{outputs: map[string]string{"logs": logs, "raw": logs}},
+		Truncation: &truncate.Options{MaxLines: 2},
+		ToolTruncation: map[string]ToolTruncation{
+			"logs": {Options: truncate.Options{MaxLines: 5}, Mode: truncate.ModeMiddle},
+			"raw":  {Disabled: true},
+		},
+		Spill: store,
+		Events: events.SinkFunc(func(e events.Event) {
+			if e.Type == events.ToolOutputTruncated {
+				truncated = append(truncated, e)
+			}
+		}),
+	})
+
+	result, err := runner.Run(context.Background(), Request{UserMessage: "go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := string(result.ToolResults[0].Output)
+	if !strings.Contains(got, "... [46 lines, ") || !strings.Contains(got, spill.ToolName) {
+		t.Fatalf("expected middle truncation with a spill note, got %q", got)
+	}
+	if string(result.ToolResults[1].Output) != logs {
+		t.Fatalf("expected raw output untouched")
+	}
+	if len(truncated) != 1 || !strings.Contains(truncated[0].Content, "saved as logs-1") {
+		t.Fatalf("unexpected truncation events: %+v", truncated)
+	}
+	if full, err := store.Load(context.Background(), "logs-1"); err != nil || string(full) != logs {
+		t.Fatalf("expected the full output in the store, got %v", err)
+	}
+}
+
+func TestSpillReferenceKeepsJSONValid(t *testing.T) {
+	out := spillReference([]byte(`{"items":["a"]}`), "read-1", truncate.Result{TotalLines: 1, TotalBytes: 900})
+	var wrapped struct {
+		Output map[string]any `json:"output"`
+		Note   string         `json:"note"`
+	}
+	if err := json.Unmarshal(out, &wrapped); err != nil {
+		t.Fatalf("expected valid JSON, got %v: %s", err, out)
+	}
+	if wrapped.Output["items"] == nil || !strings.Contains(wrapped.Note, `"read-1"`) {
+		t.Fatalf("unexpected wrapped output: %s", out)
+	}
+}
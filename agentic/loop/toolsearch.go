@@ -0,0 +1,196 @@
+package loop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+// SearchToolsName is the name of the built-in tool search meta-tool.
+const SearchToolsName = "search_tools"
+
+// ToolSearchConfig enables deferred tool loading. The decider only sees
+// non-deferred tools plus the search_tools meta-tool; tools found through
+// search become visible for the remaining turns of the run.
+type ToolSearchConfig struct {
+	Searcher agentic.ToolSearcher
+	// Fetcher loads tools the executor does not list yet. Optional.
+	Fetcher agentic.ToolFetcher
+	// Registrar receives fetched tools, so they run through the executor's
+	// policy, approval and validation. Defaults to the Executor when it
+	// implements ToolRegistrar; a Fetcher without a registrar is an error.
+	Registrar ToolRegistrar
+	// MaxResults caps tools surfaced per search (default 5).
+	MaxResults int
+}
+
+// ToolRegistrar accepts tools loaded at runtime. *agentic.Registry implements it.
+type ToolRegistrar interface {
+	Register(tools ...agentic.Tool) error
+}
+
+// toolSearchState tracks which deferred tools a run has surfaced.
+type toolSearchState struct {
+	cfg       ToolSearchConfig
+	executor  agentic.ToolExecutor
+	registrar ToolRegistrar
+
+	mu      sync.Mutex
+	visible map[string]struct{}
+}
+
+func newToolSearchState(cfg *ToolSearchConfig, executor agentic.ToolExecutor) *toolSearchState {
+	if cfg == nil {
+		return nil
+	}
+	state := &toolSearchState{
+		cfg:       *cfg,
+		executor:  executor,
+		registrar: registrarFor(cfg, executor),
+		visible:   make(map[string]struct{}),
+	}
+	if state.cfg.MaxResults <= 0 {
+		state.cfg.MaxResults = 5
+	}
+	return state
+}
+
+// registrarFor returns the registrar for fetched tools, or nil if none.
+func registrarFor(cfg *ToolSearchConfig, executor agentic.ToolExecutor) ToolRegistrar {
+	if cfg.Registrar != nil {
+		return cfg.Registrar
+	}
+	registrar, _ := executor.(ToolRegistrar)
+	return registrar
+}
+
+func searchToolsDefinition() agentic.ToolDefinition {
+	return agentic.ToolDefinition{
+		Name:        SearchToolsName,
+		Description: "Search for additional tools by keyword. Matching tools become callable on the next turn.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"query":{"type":"string","description":"Keywords describing the capability you need."}},"required":["query"]}`),
+	}
+}
+
+// tools returns non-deferred and surfaced tools plus the search meta-tool.
+func (s *toolSearchState) tools(ctx context.Context) ([]agentic.ToolDefinition, error) {
+	var listed []agentic.ToolDefinition
+	if s.executor != nil {
+		var err error
+		listed, err = s.executor.ListTools(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]agentic.ToolDefinition, 0, len(listed)+1)
+	for _, def := range listed {
+		if _, ok := s.visible[def.Name]; def.DeferLoad && !ok {
+			continue
+		}
+		out = append(out, def)
+	}
+	return append(out, searchToolsDefinition()), nil
+}
+
+// execute handles search_tools, reporting false for calls the executor
+// should run.
+func (s *toolSearchState) execute(ctx context.Context, call agentic.ToolCall) (agentic.ToolResult, bool, error) {
+	if call.Name != SearchToolsName {
+		return agentic.ToolResult{}, false, nil
+	}
+	result, err := s.search(ctx, call)
+	return result, true, err
+}
+
+type searchToolsInput struct {
+	Query string `json:"query"`
+}
+
+type searchToolsMatch struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+func (s *toolSearchState) search(ctx context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+	var in searchToolsInput
+	if len(call.Input) > 0 {
+		if err := json.Unmarshal(call.Input, &in); err != nil {
+			return agentic.ToolResult{}, fmt.Errorf("%w: %v", agentic.ErrInvalidInput, err)
+		}
+	}
+	query := strings.TrimSpace(in.Query)
+	if query == "" {
+		return agentic.ToolResult{}, fmt.Errorf("%w: query is required", agentic.ErrInvalidInput)
+	}
+	if s.cfg.Searcher == nil {
+		return agentic.ToolResult{}, errors.New("loop: tool search has no searcher configured")
+	}
+	found, err := s.cfg.Searcher.SearchTools(ctx, query)
+	if err != nil {
+		return agentic.ToolResult{}, err
+	}
+
+	known, err := s.knownTools(ctx)
+	if err != nil {
+		return agentic.ToolResult{}, err
+	}
+	matches := make([]searchToolsMatch, 0, s.cfg.MaxResults)
+	for _, def := range found {
+		if len(matches) >= s.cfg.MaxResults {
+			break
+		}
+		if def.Name == "" || def.Name == SearchToolsName {
+			continue
+		}
+		if _, ok := known[def.Name]; !ok {
+			if err := s.load(ctx, def.Name); err != nil {
+				continue
+			}
+		}
+		s.mu.Lock()
+		s.visible[def.Name] = struct{}{}
+		s.mu.Unlock()
+		matches = append(matches, searchToolsMatch{Name: def.Name, Description: def.Description})
+	}
+	payload, err := json.Marshal(map[string]any{"tools": matches})
+	if err != nil {
+		return agentic.ToolResult{}, err
+	}
+	return agentic.ToolResult{ID: call.ID, Name: call.Name, Output: payload}, nil
+}
+
+func (s *toolSearchState) knownTools(ctx context.Context) (map[string]struct{}, error) {
+	known := make(map[string]struct{})
+	if s.executor != nil {
+		listed, err := s.executor.ListTools(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, def := range listed {
+			known[def.Name] = struct{}{}
+		}
+	}
+	return known, nil
+}
+
+// load fetches a tool and registers it with the registrar.
+func (s *toolSearchState) load(ctx context.Context, name string) error {
+	if s.cfg.Fetcher == nil || s.registrar == nil {
+		return fmt.Errorf("loop: no fetcher for tool %s", name)
+	}
+	tool, err := s.cfg.Fetcher.FetchTool(ctx, name)
+	if err != nil {
+		return err
+	}
+	if tool == nil {
+		return fmt.Errorf("loop: fetcher returned nil tool %s", name)
+	}
+	return s.registrar.Register(tool)
+}
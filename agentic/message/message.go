@@ -3,10 +3,14 @@ package message
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"time"
 
 	"github.com/victorarias/agentic-weave/agentic"
 	"github.com/victorarias/agentic-weave/agentic/context/budget"
+	"github.com/victorarias/agentic-weave/agentic/usage"
 )
 
 // Role constants for message types.
@@ -19,12 +23,56 @@ const (
 
 // AgentMessage is the rich internal message representation.
 // Tool calls and results are structured, not flattened to text.
+// ID and ParentID link messages into a tree for branching histories.
 type AgentMessage struct {
+	ID          string
+	ParentID    string
 	Role        string
 	Content     string
 	ToolCalls   []agentic.ToolCall
 	ToolResults []agentic.ToolResult
 	Timestamp   time.Time
+
+	// Parts holds multimodal content, such as images, sent after Content.
+	Parts []agentic.ContentPart `json:",omitempty"`
+	// Reasoning is the readable thinking behind an assistant message.
+	Reasoning string `json:",omitempty"`
+	// Usage, StopReason and Model describe the response that produced an
+	// assistant message.
+	Usage      *usage.Usage     `json:",omitempty"`
+	StopReason usage.StopReason `json:",omitempty"`
+	Model      string           `json:",omitempty"`
+	// Metadata holds caller-defined values; it must be JSON-encodable to persist.
+	Metadata map[string]any `json:",omitempty"`
+	// ProviderBlocks carry provider data that must be sent back unchanged,
+	// such as signed reasoning blocks.
+	ProviderBlocks []ProviderBlock `json:",omitempty"`
+}
+
+// ProviderBlock is opaque provider data attached to a message. Providers
+// replay their own blocks and ignore the rest.
+type ProviderBlock struct {
+	Provider string
+	Type     string
+	Data     json.RawMessage
+}
+
+// Blocks returns the message's blocks for provider.
+func (m AgentMessage) Blocks(provider string) []ProviderBlock {
+	var out []ProviderBlock
+	for _, block := range m.ProviderBlocks {
+		if block.Provider == provider {
+			out = append(out, block)
+		}
+	}
+	return out
+}
+
+// NewID returns a random message ID.
+func NewID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return "msg_" + hex.EncodeToString(b[:])
 }
 
 // BudgetRole implements budget.Budgetable.
@@ -36,11 +84,13 @@ func (m AgentMessage) BudgetRole() string {
 // Returns all content concatenated for token estimation.
 func (m AgentMessage) BudgetContent() string {
 	content := m.Content
+	content += partsText(m.Parts)
 	for _, tc := range m.ToolCalls {
 		content += tc.Name + string(tc.Input)
 	}
 	for _, tr := range m.ToolResults {
 		content += string(tr.Output)
+		content += partsText(tr.Parts)
 		if tr.Error != nil {
 			content += tr.Error.Message
 		}
@@ -48,6 +98,19 @@ func (m AgentMessage) BudgetContent() string {
 	return content
 }
 
+// partsText returns the text of parts; binary parts count as their MIME type.
+func partsText(parts []agentic.ContentPart) string {
+	var text string
+	for _, part := range parts {
+		if part.Type == agentic.PartText {
+			text += part.Text
+		} else {
+			text += part.MIMEType + part.URL
+		}
+	}
+	return text
+}
+
 // ToBudgetable converts a slice of AgentMessage to []budget.Budgetable.
 func ToBudgetable(messages []AgentMessage) []budget.Budgetable {
 	out := make([]budget.Budgetable, len(messages))
@@ -57,7 +120,35 @@ func ToBudgetable(messages []AgentMessage) []budget.Budgetable {
 	return out
 }
 
-// CompactIfNeeded wraps budget.Manager.CompactIfNeeded for AgentMessage slices.
+// Calibration measures messages up to the last assistant message with Usage,
+// whose prompt and output tokens cover everything before it. Usage recorded
+// before the latest system (compaction summary) message is ignored, since it
+// measured messages that were compacted away.
+func Calibration(messages []AgentMessage) budget.Calibration {
+	var compacted time.Time
+	for _, msg := range messages {
+		if msg.Role == RoleSystem && msg.Timestamp.After(compacted) {
+			compacted = msg.Timestamp
+		}
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.Role != RoleAssistant || msg.Usage == nil {
+			continue
+		}
+		if !compacted.IsZero() && !msg.Timestamp.After(compacted) {
+			break
+		}
+		return budget.Calibration{Messages: i + 1, Tokens: usage.Prompt(*msg.Usage) + msg.Usage.Output}
+	}
+	return budget.Calibration{}
+}
+
+// CompactIfNeeded wraps budget.Manager.CompactIfNeeded for AgentMessage slices,
+// calibrated by the recorded Usage of the latest assistant message.
+// When Policy.PruneToolOutputBytes is set, it first prunes large tool outputs
+// before the cut point and only summarizes if the messages are still over
+// budget; the summary is then empty when pruning alone was enough.
 // It preserves the full AgentMessage structure for messages after the cut point.
 // Returns: (compacted messages, summary text, whether compaction occurred, error).
 func CompactIfNeeded(ctx context.Context, mgr budget.Manager, messages []AgentMessage) ([]AgentMessage, string, bool, error) {
@@ -65,10 +156,30 @@ func CompactIfNeeded(ctx context.Context, mgr budget.Manager, messages []AgentMe
 		return messages, "", false, nil
 	}
 
-	budgetable := ToBudgetable(messages)
-	summary, keepCount, changed, err := mgr.CompactIfNeeded(ctx, budgetable)
-	if err != nil || !changed {
-		return messages, summary, changed, err
+	original := messages
+	cal := Calibration(messages)
+	pruned := false
+	if limit := mgr.Policy.PruneToolOutputBytes; limit > 0 {
+		plan, err := mgr.Check(ctx, ToBudgetable(messages), cal)
+		if err != nil {
+			return messages, "", false, err
+		}
+		if plan.Over && plan.Start > 0 {
+			var old []AgentMessage
+			old, pruned = PruneToolOutputs(messages[:plan.Start], limit)
+			if pruned {
+				cal.Tokens -= savedTokens(mgr.Counter, messages[:min(plan.Start, cal.Messages)], old)
+				messages = append(old, messages[plan.Start:]...)
+			}
+		}
+	}
+
+	summary, keepCount, changed, err := mgr.CompactCalibrated(ctx, ToBudgetable(messages), cal)
+	if err != nil {
+		return original, "", false, err
+	}
+	if !changed {
+		return messages, "", pruned, nil
 	}
 
 	// Keep original AgentMessages (with tool data) from the end
@@ -76,6 +187,7 @@ func CompactIfNeeded(ctx context.Context, mgr budget.Manager, messages []AgentMe
 
 	result := make([]AgentMessage, 0, keepCount+1)
 	result = append(result, AgentMessage{
+		ID:        NewID(),
 		Role:      RoleSystem,
 		Content:   summary,
 		Timestamp: time.Now(),
@@ -84,3 +196,14 @@ func CompactIfNeeded(ctx context.Context, mgr budget.Manager, messages []AgentMe
 
 	return result, summary, true, nil
 }
+
+// savedTokens estimates how many tokens pruning removed from before.
+func savedTokens(counter budget.TokenCounter, before, after []AgentMessage) int {
+	saved := 0
+	for i, msg := range before {
+		if len(msg.ToolResults) > 0 {
+			saved += counter.Count(msg.BudgetContent()) - counter.Count(after[i].BudgetContent())
+		}
+	}
+	return saved
+}
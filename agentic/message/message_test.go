@@ -1,10 +1,16 @@
 package message
 
 import (
+	"context"
 	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/context/budget"
+	"github.com/victorarias/agentic-weave/agentic/usage"
 )
 
 func TestToolResultsIncludedInBudget(t *testing.T) {
@@ -36,3 +42,132 @@ func TestToolErrorsIncludedInBudget(t *testing.T) {
 		t.Errorf("expected tool error in content, got %q", content)
 	}
 }
+
+func TestCalibrationUsesLatestUsageAfterCompaction(t *testing.T) {
+	start := time.Now()
+	messages := []AgentMessage{
+		{Role: RoleSystem, Content: "summary", Timestamp: start},
+		{Role: RoleAssistant, Usage: &usage.Usage{Input: 500}, Timestamp: start.Add(-time.Minute)},
+		{Role: RoleUser, Content: "hi", Timestamp: start.Add(time.Second)},
+	}
+	if got := Calibration(messages); got != (budget.Calibration{}) {
+		t.Fatalf("expected usage from before compaction to be ignored, got %+v", got)
+	}
+
+	messages = append(messages,
+		AgentMessage{Role: RoleAssistant, Usage: &usage.Usage{Input: 40, Output: 5, CacheRead: 100}, Timestamp: start.Add(2 * time.Second)},
+		AgentMessage{Role: RoleTool, Timestamp: start.Add(3 * time.Second)},
+	)
+	want := budget.Calibration{Messages: 4, Tokens: 145}
+	if got := Calibration(messages); got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestPruneToolOutputsShrinksLargeOutputs(t *testing.T) {
+	large := json.RawMessage(`"` + strings.Repeat("line of output\\n", 200) + `"`)
+	messages := []AgentMessage{
+		{Role: RoleTool, ToolResults: []agentic.ToolResult{
+			{Name: "read", Output: large, Parts: []agentic.ContentPart{agentic.ImagePart("image/png", []byte("png"))}},
+			{Name: "bash", Output: json.RawMessage(`"small"`)},
+		}},
+		{Role: RoleTool, ToolResults: []agentic.ToolResult{{Name: "bash", Error: &agentic.ToolError{Message: "exit 1"}}}},
+	}
+
+	pruned, changed := PruneToolOutputs(messages, 300)
+	if !changed {
+		t.Fatal("expected the large output to be pruned")
+	}
+	result := pruned[0].ToolResults[0]
+	var text string
+	if err := json.Unmarshal(result.Output, &text); err != nil || len(result.Output) > 300 || result.Parts != nil {
+		t.Fatalf("expected a short JSON string without parts, got %s (%v)", result.Output, err)
+	}
+	if !strings.HasPrefix(text, "line of output\n") || !strings.Contains(text, "bytes of old tool output pruned]") {
+		t.Fatalf("unexpected pruned output %q", text)
+	}
+	if string(pruned[0].ToolResults[1].Output) != `"small"` || pruned[1].ToolResults[0].Error == nil {
+		t.Fatalf("expected small outputs and errors to be kept")
+	}
+	if len(messages[0].ToolResults[0].Output) != len(large) {
+		t.Fatal("expected the input messages to be left alone")
+	}
+	if _, changed := PruneToolOutputs(pruned, 300); changed {
+		t.Fatal("expected pruning to be idempotent")
+	}
+}
+
+func TestPruneToolOutputsReplacesMediaParts(t *testing.T) {
+	messages := []AgentMessage{
+		{Role: RoleTool, ToolResults: []agentic.ToolResult{{
+			Name:   "screenshot",
+			Output: json.RawMessage(`"ok"`),
+			Parts: []agentic.ContentPart{
+				agentic.TextPart("caption"),
+				agentic.ImagePart("image/png", []byte(strings.Repeat("x", 4096))),
+				{Type: agentic.PartDocument, URL: "https://example.com/a.pdf"},
+			},
+		}}},
+	}
+
+	pruned, changed := PruneToolOutputs(messages, 300)
+	if !changed {
+		t.Fatal("expected media parts to be pruned")
+	}
+	result := pruned[0].ToolResults[0]
+	want := []agentic.ContentPart{
+		agentic.TextPart("caption"),
+		agentic.TextPart("[old image pruned: image/png]"),
+		agentic.TextPart("[old document pruned]"),
+	}
+	if string(result.Output) != `"ok"` || !reflect.DeepEqual(result.Parts, want) {
+		t.Fatalf("unexpected pruned result %s %#v", result.Output, result.Parts)
+	}
+	if messages[0].ToolResults[0].Parts[1].Type != agentic.PartImage {
+		t.Fatal("expected the input messages to be left alone")
+	}
+	if _, changed := PruneToolOutputs(pruned, 300); changed {
+		t.Fatal("expected pruning to be idempotent")
+	}
+}
+
+func TestCompactIfNeededPrunesBeforeSummarizing(t *testing.T) {
+	compactor := &countingCompactor{}
+	mgr := budget.Manager{
+		Counter:   budget.CharCounter{},
+		Compactor: compactor,
+		Policy:    budget.Policy{ContextWindow: 200, KeepLast: 1, PruneToolOutputBytes: 256},
+	}
+	messages := []AgentMessage{
+		{Role: RoleUser, Content: "read it"},
+		{Role: RoleAssistant, ToolCalls: []agentic.ToolCall{{Name: "read"}}},
+		{Role: RoleTool, ToolResults: []agentic.ToolResult{{Name: "read", Output: json.RawMessage(`"` + strings.Repeat("x", 2000) + `"`)}}},
+		{Role: RoleUser, Content: "thanks"},
+	}
+
+	compacted, summary, changed, err := CompactIfNeeded(context.Background(), mgr, messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changed || summary != "" || compactor.calls != 0 || len(compacted) != len(messages) {
+		t.Fatalf("expected pruning without a summary, got changed=%v summary=%q calls=%d", changed, summary, compactor.calls)
+	}
+
+	mgr.Policy.ContextWindow = 20
+	compacted, summary, changed, err = CompactIfNeeded(context.Background(), mgr, messages)
+	if err != nil || !changed || summary != "summary" || compactor.calls != 1 || len(compacted) != 2 {
+		t.Fatalf("expected summarization after pruning, got %d messages, summary %q, err %v", len(compacted), summary, err)
+	}
+	if compacted[0].Role != RoleSystem || compacted[0].ID == "" {
+		t.Fatalf("expected the summary message to have an ID, got %+v", compacted[0])
+	}
+}
+
+type countingCompactor struct {
+	calls int
+}
+
+func (c *countingCompactor) Compact(context.Context, []budget.Budgetable) (string, error) {
+	c.calls++
+	return "summary", nil
+}
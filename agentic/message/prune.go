@@ -0,0 +1,94 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/truncate"
+)
+
+// minPruneBytes leaves room for the note that replaces a pruned output.
+const minPruneBytes = 256
+
+// PruneToolOutputs shrinks tool outputs larger than maxBytes (at least 256)
+// to a JSON string holding their first lines and a note on what was dropped.
+// Content parts of those results are dropped too, and images and documents
+// in the other results become text placeholders; errors are kept. Pruned
+// outputs fit in maxBytes, so pruning twice changes nothing. It returns a new
+// slice and whether anything changed.
+func PruneToolOutputs(messages []AgentMessage, maxBytes int) ([]AgentMessage, bool) {
+	maxBytes = max(maxBytes, minPruneBytes)
+	out := messages
+	changed := false
+	for i, msg := range messages {
+		var results []agentic.ToolResult
+		for j, result := range msg.ToolResults {
+			large := len(result.Output) > maxBytes
+			parts, partsPruned := pruneParts(result.Parts)
+			if !large && !partsPruned {
+				continue
+			}
+			if results == nil {
+				results = append([]agentic.ToolResult(nil), msg.ToolResults...)
+			}
+			if large {
+				results[j].Output = pruneOutput(result.Output, maxBytes)
+				results[j].Parts = nil
+			} else {
+				results[j].Parts = parts
+			}
+		}
+		if results == nil {
+			continue
+		}
+		if !changed {
+			out = append([]AgentMessage(nil), messages...)
+			changed = true
+		}
+		out[i].ToolResults = results
+	}
+	return out, changed
+}
+
+// pruneParts replaces image and document parts with text placeholders. It
+// reports false when there were none.
+func pruneParts(parts []agentic.ContentPart) ([]agentic.ContentPart, bool) {
+	var out []agentic.ContentPart
+	for i, part := range parts {
+		if part.Type != agentic.PartImage && part.Type != agentic.PartDocument {
+			continue
+		}
+		if out == nil {
+			out = append([]agentic.ContentPart(nil), parts...)
+		}
+		note := "[old " + part.Type + " pruned"
+		if part.MIMEType != "" {
+			note += ": " + part.MIMEType
+		}
+		out[i] = agentic.TextPart(note + "]")
+	}
+	return out, out != nil
+}
+
+// pruneOutput keeps the head of output that fits, with the note, in maxBytes.
+func pruneOutput(output json.RawMessage, maxBytes int) json.RawMessage {
+	text := string(output)
+	var unquoted string
+	if json.Unmarshal(output, &unquoted) == nil {
+		text = unquoted
+	}
+	head := maxBytes
+	for {
+		kept := ""
+		if head > 0 {
+			kept = truncate.Head(text, truncate.Options{MaxBytes: head}).Content
+		}
+		note := fmt.Sprintf("\n[... %d of %d bytes of old tool output pruned]", len(text)-len(kept), len(text))
+		data, _ := json.Marshal(kept + note)
+		if len(data) <= maxBytes || head == 0 {
+			return data
+		}
+		head = max(0, min(head-1, head*maxBytes/len(data)-len(note)))
+	}
+}
@@ -1,6 +1,9 @@
 package agentic
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // Policy guards tool visibility and execution.
 type Policy interface {
@@ -8,6 +11,19 @@ type Policy interface {
 	AllowCall(def ToolDefinition, call ToolCall) error
 }
 
+// ContextPolicy is an optional Policy extension for rules that need the call
+// context (e.g. toolscope metadata). The Registry prefers it over AllowCall.
+type ContextPolicy interface {
+	AllowCallContext(ctx context.Context, def ToolDefinition, call ToolCall) error
+}
+
+func allowCall(ctx context.Context, policy Policy, def ToolDefinition, call ToolCall) error {
+	if cp, ok := policy.(ContextPolicy); ok {
+		return cp.AllowCallContext(ctx, def, call)
+	}
+	return policy.AllowCall(def, call)
+}
+
 // AllowAllPolicy is the default permissive policy.
 type AllowAllPolicy struct{}
 
@@ -14,6 +14,7 @@ import (
 	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/victorarias/agentic-weave/agentic"
 	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/retry"
 	"github.com/victorarias/agentic-weave/agentic/usage"
 	"github.com/victorarias/agentic-weave/capabilities"
 )
@@ -27,14 +28,21 @@ type Input struct {
 	Tools        []agentic.ToolDefinition
 	MaxTokens    int
 	Temperature  *float64
+	// Reasoning overrides Config.ThinkingBudget for this request.
+	Reasoning *capabilities.Reasoning
 }
 
 // Decision is the output from a single model call.
 type Decision struct {
 	Reply      string
+	Reasoning  string
 	ToolCalls  []agentic.ToolCall
 	StopReason string
 	Usage      *usage.Usage
+	Model      string
+	// Blocks holds thinking blocks to store on the assistant message; they
+	// are replayed on later requests.
+	Blocks []message.ProviderBlock
 }
 
 // Config controls an Anthropic client.
@@ -45,14 +53,27 @@ type Config struct {
 	MaxTokens   int
 	Temperature *float64
 	HTTPClient  *http.Client
+	// ThinkingBudget enables extended thinking with this many tokens.
+	// Temperature is not sent while thinking is enabled.
+	ThinkingBudget int
+	// Cache selects prompt cache breakpoints; empty means CacheAuto.
+	Cache CacheStrategy
+	// CacheTTL is the cache lifetime, "5m" (the API default) or "1h".
+	CacheTTL string
+	// DisableRetries turns off the SDK's own retries. Set it when the client
+	// is wrapped in retry.Decider, so attempts do not multiply.
+	DisableRetries bool
 }
 
 // Client calls the Anthropic Messages API.
 type Client struct {
-	client      anthropic.Client
-	model       string
-	maxTokens   int
-	temperature *float64
+	client         anthropic.Client
+	model          string
+	maxTokens      int
+	temperature    *float64
+	thinkingBudget int
+	cache          CacheStrategy
+	cacheTTL       string
 }
 
 // New constructs an Anthropic client from config.
@@ -78,14 +99,20 @@ func New(cfg Config) (*Client, error) {
 	if cfg.HTTPClient != nil {
 		opts = append(opts, option.WithHTTPClient(cfg.HTTPClient))
 	}
+	if cfg.DisableRetries {
+		opts = append(opts, option.WithMaxRetries(0))
+	}
 
 	client := anthropic.NewClient(opts...)
 
 	return &Client{
-		client:      client,
-		model:       model,
-		maxTokens:   maxTokens,
-		temperature: cfg.Temperature,
+		client:         client,
+		model:          model,
+		maxTokens:      maxTokens,
+		temperature:    cfg.Temperature,
+		thinkingBudget: cfg.ThinkingBudget,
+		cache:          cfg.Cache,
+		cacheTTL:       cfg.CacheTTL,
 	}, nil
 }
 
@@ -122,6 +149,28 @@ func NewFromEnv() (*Client, error) {
 
 // Decide calls the Anthropic Messages API.
 func (c *Client) Decide(ctx context.Context, input Input) (Decision, error) {
+	msg, err := c.client.Messages.New(ctx, c.params(input))
+	if err != nil {
+		return Decision{}, wrapError("anthropic", err)
+	}
+
+	reply, calls, blocks := parseResponse(msg)
+	u := msg.Usage
+	usageValue := usageFrom(u.InputTokens, u.OutputTokens, u.CacheReadInputTokens, u.CacheCreationInputTokens)
+
+	return Decision{
+		Reply:      reply,
+		Reasoning:  reasoningText(blocks),
+		ToolCalls:  calls,
+		StopReason: string(msg.StopReason),
+		Usage:      usageValue,
+		Model:      string(msg.Model),
+		Blocks:     blocks,
+	}, nil
+}
+
+// params builds the Messages API request shared by Decide and Stream.
+func (c *Client) params(input Input) anthropic.MessageNewParams {
 	messages := appendHistory(nil, input.History)
 
 	userMessage := strings.TrimSpace(input.UserMessage)
@@ -140,14 +189,22 @@ func (c *Client) Decide(ctx context.Context, input Input) (Decision, error) {
 	}
 
 	if system := strings.TrimSpace(input.SystemPrompt); system != "" {
-		req.System = []anthropic.TextBlockParam{{
-			Text: system,
-		}}
+		req.System = []anthropic.TextBlockParam{{Text: system}}
 	}
 
 	if input.MaxTokens > 0 {
 		req.MaxTokens = int64(input.MaxTokens)
 	}
+	c.applyCache(&req)
+
+	if budget := c.thinkingBudgetFor(input); budget > 0 {
+		// The budget counts toward max_tokens, which must stay larger.
+		if req.MaxTokens <= int64(budget) {
+			req.MaxTokens += int64(budget)
+		}
+		req.Thinking = anthropic.ThinkingConfigParamOfEnabled(int64(budget))
+		return req
+	}
 
 	temperature := input.Temperature
 	if temperature == nil {
@@ -156,21 +213,20 @@ func (c *Client) Decide(ctx context.Context, input Input) (Decision, error) {
 	if temperature != nil {
 		req.Temperature = anthropic.Float(*temperature)
 	}
+	return req
+}
 
-	msg, err := c.client.Messages.New(ctx, req)
-	if err != nil {
-		return Decision{}, fmt.Errorf("anthropic: %w", err)
+// thinkingBudgetFor returns the thinking budget for input, raised to the
+// API minimum of 1024 tokens. Zero disables thinking.
+func (c *Client) thinkingBudgetFor(input Input) int {
+	budget := c.thinkingBudget
+	if input.Reasoning != nil {
+		budget = input.Reasoning.Budget()
 	}
-
-	reply, calls := parseResponse(msg)
-	usageValue := capabilities.NormalizeUsage(int(msg.Usage.InputTokens), int(msg.Usage.OutputTokens), 0)
-
-	return Decision{
-		Reply:      reply,
-		ToolCalls:  calls,
-		StopReason: string(msg.StopReason),
-		Usage:      &usageValue,
-	}, nil
+	if budget > 0 && budget < minThinkingBudget {
+		budget = minThinkingBudget
+	}
+	return budget
 }
 
 func appendHistory(messages []anthropic.MessageParam, history []message.AgentMessage) []anthropic.MessageParam {
@@ -178,16 +234,17 @@ func appendHistory(messages []anthropic.MessageParam, history []message.AgentMes
 		msg := history[i]
 		switch msg.Role {
 		case message.RoleUser:
-			blocks := make([]anthropic.ContentBlockParamUnion, 0, 1)
+			blocks := make([]anthropic.ContentBlockParamUnion, 0, 1+len(msg.Parts))
 			if strings.TrimSpace(msg.Content) != "" {
 				blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
 			}
+			blocks = append(blocks, contentBlocks(msg.Parts)...)
 			if len(blocks) > 0 {
 				messages = append(messages, anthropic.NewUserMessage(blocks...))
 			}
 
 		case message.RoleAssistant:
-			blocks := make([]anthropic.ContentBlockParamUnion, 0, 1+len(msg.ToolCalls))
+			blocks := replayThinking(msg)
 			if strings.TrimSpace(msg.Content) != "" {
 				blocks = append(blocks, anthropic.NewTextBlock(msg.Content))
 			}
@@ -214,8 +271,7 @@ func appendHistory(messages []anthropic.MessageParam, history []message.AgentMes
 					if id == "" {
 						id = result.Name
 					}
-					content, isError := toolResultContent(result)
-					blocks = append(blocks, anthropic.NewToolResultBlock(id, content, isError))
+					blocks = append(blocks, toolResultBlock(id, result))
 				}
 			}
 			i-- // compensate for the outer loop increment
@@ -233,11 +289,16 @@ func appendHistory(messages []anthropic.MessageParam, history []message.AgentMes
 	return messages
 }
 
-func parseResponse(msg *anthropic.Message) (string, []agentic.ToolCall) {
+func parseResponse(msg *anthropic.Message) (string, []agentic.ToolCall, []message.ProviderBlock) {
 	var reply strings.Builder
 	calls := make([]agentic.ToolCall, 0)
+	var blocks []message.ProviderBlock
 	for _, block := range msg.Content {
 		switch variant := block.AsAny().(type) {
+		case anthropic.ThinkingBlock:
+			blocks = append(blocks, thinkingBlock(variant.Thinking, variant.Signature))
+		case anthropic.RedactedThinkingBlock:
+			blocks = append(blocks, redactedThinkingBlock(variant.Data))
 		case anthropic.TextBlock:
 			reply.WriteString(variant.Text)
 		case anthropic.ToolUseBlock:
@@ -249,7 +310,7 @@ func parseResponse(msg *anthropic.Message) (string, []agentic.ToolCall) {
 			calls = append(calls, call)
 		}
 	}
-	return strings.TrimSpace(reply.String()), calls
+	return strings.TrimSpace(reply.String()), calls, blocks
 }
 
 func toolDefsToAnthropic(tools []agentic.ToolDefinition) []anthropic.ToolUnionParam {
@@ -334,6 +395,21 @@ func toolResultContent(result agentic.ToolResult) (string, bool) {
 	return string(result.Output), false
 }
 
+// wrapError prefixes err and, for API responses, exposes the HTTP status and
+// Retry-After header as a retry.HTTPError so callers can classify it.
+func wrapError(prefix string, err error) error {
+	wrapped := fmt.Errorf("%s: %w", prefix, err)
+	var apiErr *anthropic.Error
+	if !errors.As(err, &apiErr) {
+		return wrapped
+	}
+	var header http.Header
+	if apiErr.Response != nil {
+		header = apiErr.Response.Header
+	}
+	return retry.NewHTTPError(apiErr.StatusCode, header, wrapped)
+}
+
 func envTrimmed(key string) string {
 	return strings.TrimSpace(os.Getenv(key))
 }
@@ -17,6 +17,11 @@ type VertexConfig struct {
 	Model       string
 	MaxTokens   int
 	Temperature *float64
+	// ThinkingBudget enables extended thinking; see Config.ThinkingBudget.
+	ThinkingBudget int
+	// Cache and CacheTTL control prompt caching; see Config.Cache.
+	Cache    CacheStrategy
+	CacheTTL string
 }
 
 // NewVertex constructs an Anthropic client that uses Vertex AI as the backend.
@@ -49,10 +54,13 @@ func NewVertex(ctx context.Context, cfg VertexConfig) (client *Client, err error
 	sdkClient := sdk.NewClient(vertexOpt)
 
 	return &Client{
-		client:      sdkClient,
-		model:       model,
-		maxTokens:   maxTokens,
-		temperature: cfg.Temperature,
+		client:         sdkClient,
+		model:          model,
+		maxTokens:      maxTokens,
+		temperature:    cfg.Temperature,
+		thinkingBudget: cfg.ThinkingBudget,
+		cache:          cfg.Cache,
+		cacheTTL:       cfg.CacheTTL,
 	}, nil
 }
 
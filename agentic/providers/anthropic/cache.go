@@ -0,0 +1,82 @@
+package anthropic
+
+import (
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+)
+
+// CacheStrategy selects where prompt cache breakpoints are placed.
+type CacheStrategy string
+
+const (
+	// CacheAuto marks the tool list, the system prompt and the last message,
+	// so each turn reads the prefix the previous turn wrote. It is the default.
+	CacheAuto CacheStrategy = "auto"
+	// CacheStatic marks only the tool list and the system prompt.
+	CacheStatic CacheStrategy = "static"
+	// CacheOff sends no cache_control.
+	CacheOff CacheStrategy = "off"
+)
+
+// applyCache sets cache_control breakpoints on req for the client's strategy.
+func (c *Client) applyCache(req *anthropic.MessageNewParams) {
+	if c.cache == CacheOff {
+		return
+	}
+	control := anthropic.NewCacheControlEphemeralParam()
+	control.TTL = anthropic.CacheControlEphemeralTTL(c.cacheTTL)
+
+	if n := len(req.Tools); n > 0 && req.Tools[n-1].OfTool != nil {
+		req.Tools[n-1].OfTool.CacheControl = control
+	}
+	if n := len(req.System); n > 0 {
+		req.System[n-1].CacheControl = control
+	}
+	if c.cache == CacheStatic || len(req.Messages) == 0 {
+		return
+	}
+	// Thinking blocks cannot carry cache_control, so mark the last block that can.
+	blocks := req.Messages[len(req.Messages)-1].Content
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if cc := blocks[i].GetCacheControl(); cc != nil {
+			*cc = control
+			return
+		}
+	}
+}
+
+// usageFrom converts API token counts, including prompt cache reads and
+// writes, to a normalized usage value.
+func usageFrom(input, output, cacheRead, cacheWrite int64) *usage.Usage {
+	u := usage.Normalize(usage.Usage{
+		Input:      int(input),
+		Output:     int(output),
+		CacheRead:  int(cacheRead),
+		CacheWrite: int(cacheWrite),
+	})
+	return &u
+}
+
+// mergeDeltaUsage applies the cumulative counts of a message_delta event to
+// the usage reported by message_start. Zero counts keep the earlier value.
+func mergeDeltaUsage(start *usage.Usage, delta anthropic.MessageDeltaUsage) *usage.Usage {
+	var u usage.Usage
+	if start != nil {
+		u = *start
+	}
+	if delta.InputTokens > 0 {
+		u.Input = int(delta.InputTokens)
+	}
+	if delta.OutputTokens > 0 {
+		u.Output = int(delta.OutputTokens)
+	}
+	if delta.CacheReadInputTokens > 0 {
+		u.CacheRead = int(delta.CacheReadInputTokens)
+	}
+	if delta.CacheCreationInputTokens > 0 {
+		u.CacheWrite = int(delta.CacheCreationInputTokens)
+	}
+	u.Total = 0
+	u = usage.Normalize(u)
+	return &u
+}
@@ -0,0 +1,91 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+)
+
+func TestParamsPlacesCacheBreakpoints(t *testing.T) {
+	input := Input{
+		SystemPrompt: "be helpful",
+		Tools:        []agentic.ToolDefinition{{Name: "a"}, {Name: "b"}},
+		History: []message.AgentMessage{
+			{Role: message.RoleUser, Content: "first"},
+			{Role: message.RoleAssistant, Content: "reply"},
+		},
+		UserMessage: "second",
+	}
+	count := func(req anthropic.MessageNewParams) (string, int) {
+		raw, err := json.Marshal(req)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		return string(raw), strings.Count(string(raw), `"cache_control"`)
+	}
+
+	client := &Client{model: "claude-test", maxTokens: 1024, cacheTTL: "1h"}
+	req := client.params(input)
+	if raw, n := count(req); n != 3 || !strings.Contains(raw, `"ttl":"1h"`) {
+		t.Fatalf("expected 3 breakpoints with a 1h ttl, got %d in %s", n, raw)
+	}
+	if req.Tools[0].OfTool.CacheControl.Type != "" || req.Tools[1].OfTool.CacheControl.Type == "" {
+		t.Fatalf("expected only the last tool to be marked")
+	}
+	last := req.Messages[len(req.Messages)-1].Content[0]
+	if last.GetCacheControl().Type == "" {
+		t.Fatalf("expected the last message to be marked")
+	}
+
+	client.cache = CacheStatic
+	if _, n := count(client.params(input)); n != 2 {
+		t.Fatalf("expected 2 static breakpoints, got %d", n)
+	}
+	client.cache = CacheOff
+	if _, n := count(client.params(input)); n != 0 {
+		t.Fatalf("expected no breakpoints, got %d", n)
+	}
+}
+
+func TestDecideReportsCacheUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-test",
+			"content": [{"type": "text", "text": "hi"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 10, "output_tokens": 5, "cache_read_input_tokens": 900, "cache_creation_input_tokens": 100}
+		}`)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{APIKey: "test", Model: "claude-test", BaseURL: server.URL, HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	decision, err := client.Decide(context.Background(), Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	want := usage.Usage{Input: 10, Output: 5, Total: 1015, CacheRead: 900, CacheWrite: 100}
+	if decision.Usage == nil || *decision.Usage != want {
+		t.Fatalf("expected %+v, got %+v", want, decision.Usage)
+	}
+}
+
+func TestMergeDeltaUsageKeepsStartCounts(t *testing.T) {
+	start := usageFrom(10, 1, 900, 0)
+	got := mergeDeltaUsage(start, anthropic.MessageDeltaUsage{OutputTokens: 42})
+	if got.Input != 10 || got.CacheRead != 900 || got.Output != 42 || got.Total != 952 {
+		t.Fatalf("unexpected merged usage: %+v", got)
+	}
+}
@@ -0,0 +1,70 @@
+package anthropic
+
+import (
+	"encoding/base64"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+// contentBlocks converts content parts to request blocks. Documents other
+// than PDF and plain text are skipped because the API cannot read them.
+func contentBlocks(parts []agentic.ContentPart) []anthropic.ContentBlockParamUnion {
+	var out []anthropic.ContentBlockParamUnion
+	for _, part := range parts {
+		switch part.Type {
+		case agentic.PartText:
+			if strings.TrimSpace(part.Text) != "" {
+				out = append(out, anthropic.NewTextBlock(part.Text))
+			}
+		case agentic.PartImage:
+			if part.URL != "" {
+				out = append(out, anthropic.NewImageBlock(anthropic.URLImageSourceParam{URL: part.URL}))
+			} else if len(part.Data) > 0 {
+				out = append(out, anthropic.NewImageBlockBase64(part.MIMEType, base64.StdEncoding.EncodeToString(part.Data)))
+			}
+		case agentic.PartDocument:
+			if block, ok := documentBlock(part); ok {
+				out = append(out, block)
+			}
+		}
+	}
+	return out
+}
+
+func documentBlock(part agentic.ContentPart) (anthropic.ContentBlockParamUnion, bool) {
+	switch {
+	case part.URL != "":
+		return anthropic.NewDocumentBlock(anthropic.URLPDFSourceParam{URL: part.URL}), true
+	case len(part.Data) == 0:
+		return anthropic.ContentBlockParamUnion{}, false
+	case strings.HasPrefix(part.MIMEType, "text/"):
+		return anthropic.NewDocumentBlock(anthropic.PlainTextSourceParam{Data: string(part.Data)}), true
+	case part.MIMEType == "" || part.MIMEType == "application/pdf":
+		return anthropic.NewDocumentBlock(anthropic.Base64PDFSourceParam{Data: base64.StdEncoding.EncodeToString(part.Data)}), true
+	default:
+		return anthropic.ContentBlockParamUnion{}, false
+	}
+}
+
+// toolResultBlock builds a tool_result block whose content holds the output
+// text followed by any content parts.
+func toolResultBlock(id string, result agentic.ToolResult) anthropic.ContentBlockParamUnion {
+	content, isError := toolResultContent(result)
+	block := anthropic.NewToolResultBlock(id, content, isError)
+	if len(result.Parts) == 0 {
+		return block
+	}
+	if len(result.Output) == 0 && result.Error == nil {
+		block.OfToolResult.Content = nil
+	}
+	for _, part := range contentBlocks(result.Parts) {
+		block.OfToolResult.Content = append(block.OfToolResult.Content, anthropic.ToolResultBlockParamContentUnion{
+			OfText:     part.OfText,
+			OfImage:    part.OfImage,
+			OfDocument: part.OfDocument,
+		})
+	}
+	return block
+}
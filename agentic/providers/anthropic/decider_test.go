@@ -0,0 +1,260 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/loop"
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+	"github.com/victorarias/agentic-weave/capabilities"
+)
+
+func TestNormalizeStopReason(t *testing.T) {
+	cases := map[string]usage.StopReason{
+		"tool_use":   usage.StopReasonTool,
+		"max_tokens": usage.StopReasonMaxTokens,
+		"end_turn":   usage.StopReasonStop,
+		"stop":       usage.StopReasonStop,
+		"unknown":    usage.StopReason("unknown"),
+		"":           usage.StopReasonStop,
+	}
+	for in, want := range cases {
+		if got := normalizeStopReason(in); got != want {
+			t.Fatalf("normalizeStopReason(%q)=%v want %v", in, got, want)
+		}
+	}
+}
+
+func TestDeciderMapsProviderDecision(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-test",
+			"content": [
+				{"type": "text", "text": "adding"},
+				{"type": "tool_use", "id": "toolu_1", "name": "add", "input": {"a": 1}}
+			],
+			"stop_reason": "tool_use",
+			"usage": {"input_tokens": 5, "output_tokens": 3}
+		}`)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{APIKey: "test", Model: "claude-test", BaseURL: server.URL, HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	var decider loop.StreamingDecider = NewDecider(client, DeciderOptions{MaxTokens: 64})
+	decision, err := decider.Decide(context.Background(), loop.Input{UserMessage: "add"})
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if decision.Reply != "adding" || len(decision.ToolCalls) != 1 || decision.ToolCalls[0].ID != "toolu_1" {
+		t.Fatalf("unexpected decision: %#v", decision)
+	}
+	if decision.StopReason != usage.StopReasonTool {
+		t.Fatalf("expected tool stop reason, got %q", decision.StopReason)
+	}
+	if decision.Usage == nil || decision.Usage.Total != 8 {
+		t.Fatalf("unexpected usage: %#v", decision.Usage)
+	}
+}
+
+func TestCollectDecisionForwardsTextDeltas(t *testing.T) {
+	ch := make(chan StreamEvent, 3)
+	ch <- TextDeltaEvent{Delta: "hel"}
+	ch <- TextDeltaEvent{Delta: "lo"}
+	ch <- DoneEvent{StopReason: "end_turn"}
+	close(ch)
+
+	var deltas []string
+	got, err := collectDecision(ch, func(text string) { deltas = append(deltas, text) }, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deltas) != 2 || got.Reply != "hello" {
+		t.Fatalf("unexpected deltas %v / reply %q", deltas, got.Reply)
+	}
+}
+
+func TestThinkingBlocksRoundTrip(t *testing.T) {
+	var second map[string]any
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 2 {
+			_ = json.NewDecoder(r.Body).Decode(&second)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-test-1",
+			"content": [
+				{"type": "thinking", "thinking": "let me think", "signature": "sig-1"},
+				{"type": "redacted_thinking", "data": "opaque"},
+				{"type": "text", "text": "done"}
+			],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 5, "output_tokens": 3}
+		}`)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{APIKey: "test", Model: "claude-test", BaseURL: server.URL, HTTPClient: server.Client(), ThinkingBudget: 1024})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	decider := NewDecider(client, DeciderOptions{MaxTokens: 2048})
+	decision, err := decider.Decide(context.Background(), loop.Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if decision.Reasoning != "let me think" || decision.Model != "claude-test-1" || len(decision.ProviderBlocks) != 2 {
+		t.Fatalf("unexpected decision: %#v", decision)
+	}
+
+	history := []message.AgentMessage{
+		{Role: message.RoleUser, Content: "hi"},
+		{Role: message.RoleAssistant, Content: decision.Reply, ProviderBlocks: decision.ProviderBlocks},
+	}
+	if _, err := decider.Decide(context.Background(), loop.Input{History: history, UserMessage: "again"}); err != nil {
+		t.Fatalf("second decide: %v", err)
+	}
+	if thinking, ok := second["thinking"].(map[string]any); !ok || thinking["budget_tokens"] != float64(1024) {
+		t.Fatalf("expected thinking config, got %#v", second["thinking"])
+	}
+	messages := second["messages"].([]any)
+	content := messages[1].(map[string]any)["content"].([]any)
+	first := content[0].(map[string]any)
+	redacted := content[1].(map[string]any)
+	if first["type"] != "thinking" || first["signature"] != "sig-1" || redacted["type"] != "redacted_thinking" || redacted["data"] != "opaque" {
+		t.Fatalf("expected replayed thinking blocks, got %#v", content)
+	}
+}
+
+func TestCollectDecisionKeepsThinkingBlocks(t *testing.T) {
+	ch := make(chan StreamEvent, 3)
+	ch <- ThinkingEvent{Block: thinkingBlock("hmm", "sig")}
+	ch <- TextDeltaEvent{Delta: "ok"}
+	ch <- DoneEvent{StopReason: "end_turn", Model: "claude-test"}
+	close(ch)
+
+	got, err := CollectDecision(ch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Reasoning != "hmm" || got.Model != "claude-test" || len(got.Blocks) != 1 || got.Blocks[0].Provider != ProviderName {
+		t.Fatalf("unexpected decision: %#v", got)
+	}
+}
+
+func TestParamsAppliesRequestReasoning(t *testing.T) {
+	client := &Client{model: "claude-test", maxTokens: 1024, thinkingBudget: 4096}
+
+	req := client.params(Input{Reasoning: &capabilities.Reasoning{Effort: capabilities.ReasoningLow}})
+	if req.Thinking.OfEnabled == nil || req.Thinking.OfEnabled.BudgetTokens != 2048 {
+		t.Fatalf("expected low effort budget, got %#v", req.Thinking)
+	}
+	if req.MaxTokens != 1024+2048 {
+		t.Fatalf("expected max tokens above the budget, got %d", req.MaxTokens)
+	}
+
+	req = client.params(Input{Reasoning: &capabilities.Reasoning{BudgetTokens: 100}})
+	if req.Thinking.OfEnabled == nil || req.Thinking.OfEnabled.BudgetTokens != minThinkingBudget {
+		t.Fatalf("expected minimum budget, got %#v", req.Thinking)
+	}
+
+	req = client.params(Input{Reasoning: &capabilities.Reasoning{}})
+	if req.Thinking.OfEnabled != nil {
+		t.Fatalf("expected zero reasoning to disable thinking, got %#v", req.Thinking)
+	}
+}
+
+func TestCollectDecisionForwardsThinkingDeltas(t *testing.T) {
+	ch := make(chan StreamEvent, 4)
+	ch <- ThinkingDeltaEvent{Delta: "hm"}
+	ch <- ThinkingEvent{Block: thinkingBlock("hm", "sig")}
+	ch <- TextDeltaEvent{Delta: "ok"}
+	ch <- DoneEvent{StopReason: "end_turn"}
+	close(ch)
+
+	var thinking []string
+	got, err := collectDecision(ch, nil, func(delta string) { thinking = append(thinking, delta) })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(thinking) != 1 || thinking[0] != "hm" || got.Reply != "ok" || got.Reasoning != "hm" {
+		t.Fatalf("unexpected thinking %v / decision %#v", thinking, got)
+	}
+}
+
+func TestDeciderSendsUserMessageOnce(t *testing.T) {
+	var sent struct {
+		Messages []struct {
+			Role    string `json:"role"`
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"msg_1","type":"message","role":"assistant","model":"claude-test","content":[{"type":"text","text":"3"}],"stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{APIKey: "test", Model: "claude-test", BaseURL: server.URL, HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	history := []message.AgentMessage{
+		{Role: message.RoleUser, Content: "add 1 and 2"},
+		{Role: message.RoleAssistant, ToolCalls: []agentic.ToolCall{{ID: "toolu_1", Name: "add", Input: json.RawMessage(`{}`)}}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{ID: "toolu_1", Name: "add", Output: json.RawMessage(`3`)}}},
+	}
+	if _, err := NewDecider(client, DeciderOptions{}).Decide(context.Background(), loop.Input{UserMessage: "add 1 and 2", History: history, Turn: 1}); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+
+	var got []string
+	for _, msg := range sent.Messages {
+		for _, block := range msg.Content {
+			got = append(got, msg.Role+":"+block.Type+":"+block.Text)
+		}
+	}
+	want := []string{"user:text:add 1 and 2", "assistant:tool_use:", "user:tool_result:"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected messages:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestDisableRetriesSendsOneRequest(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, `{"type": "error", "error": {"type": "api_error", "message": "boom"}}`)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{APIKey: "test", Model: "claude-test", BaseURL: server.URL, HTTPClient: server.Client(), DisableRetries: true})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, err := client.Decide(context.Background(), Input{UserMessage: "hi"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if hits != 1 {
+		t.Fatalf("expected one request without SDK retries, got %d", hits)
+	}
+}
@@ -7,10 +7,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/message"
 	"github.com/victorarias/agentic-weave/agentic/usage"
-	"github.com/victorarias/agentic-weave/capabilities"
 )
 
 // StreamEvent represents a single streaming event emitted by Stream.
@@ -34,10 +33,25 @@ type ToolUseEvent struct {
 
 func (ToolUseEvent) anthropicStreamEvent() {}
 
+// ThinkingDeltaEvent represents incremental thinking text from the model.
+type ThinkingDeltaEvent struct {
+	Delta string
+}
+
+func (ThinkingDeltaEvent) anthropicStreamEvent() {}
+
+// ThinkingEvent carries a completed thinking or redacted_thinking block.
+type ThinkingEvent struct {
+	Block message.ProviderBlock
+}
+
+func (ThinkingEvent) anthropicStreamEvent() {}
+
 // DoneEvent signals completion of the stream.
 type DoneEvent struct {
 	StopReason string
 	Usage      *usage.Usage
+	Model      string
 }
 
 func (DoneEvent) anthropicStreamEvent() {}
@@ -55,40 +69,7 @@ func (ErrorEvent) anthropicStreamEvent() {}
 // and is converted to Anthropic's required tool_use/tool_result structure (tool
 // results are sent as a user message containing tool_result blocks).
 func (c *Client) Stream(ctx context.Context, input Input) (<-chan StreamEvent, error) {
-	messages := appendHistory(nil, input.History)
-
-	userMessage := strings.TrimSpace(input.UserMessage)
-	if userMessage != "" {
-		messages = append(messages, anthropic.NewUserMessage(anthropic.NewTextBlock(userMessage)))
-	}
-
-	req := anthropic.MessageNewParams{
-		Model:     anthropic.Model(c.model),
-		MaxTokens: int64(c.maxTokens),
-		Messages:  messages,
-	}
-
-	if len(input.Tools) > 0 {
-		req.Tools = toolDefsToAnthropic(input.Tools)
-	}
-
-	if system := strings.TrimSpace(input.SystemPrompt); system != "" {
-		req.System = []anthropic.TextBlockParam{{Text: system}}
-	}
-
-	if input.MaxTokens > 0 {
-		req.MaxTokens = int64(input.MaxTokens)
-	}
-
-	temperature := input.Temperature
-	if temperature == nil {
-		temperature = c.temperature
-	}
-	if temperature != nil {
-		req.Temperature = anthropic.Float(*temperature)
-	}
-
-	stream := c.client.Messages.NewStreaming(ctx, req)
+	stream := c.client.Messages.NewStreaming(ctx, c.params(input))
 
 	events := make(chan StreamEvent, 32)
 	go func() {
@@ -98,6 +79,7 @@ func (c *Client) Stream(ctx context.Context, input Input) (<-chan StreamEvent, e
 		var (
 			stopReason string
 			usageValue *usage.Usage
+			model      string
 		)
 
 		type toolState struct {
@@ -107,17 +89,33 @@ func (c *Client) Stream(ctx context.Context, input Input) (<-chan StreamEvent, e
 		}
 		var currentTool *toolState
 
+		type thinkingState struct {
+			thinking  strings.Builder
+			signature string
+		}
+		var currentThinking *thinkingState
+
 		for stream.Next() {
 			ev := stream.Current()
 
 			switch ev.Type {
+			case "message_start":
+				model = string(ev.Message.Model)
+				u := ev.Message.Usage
+				usageValue = usageFrom(u.InputTokens, u.OutputTokens, u.CacheReadInputTokens, u.CacheCreationInputTokens)
+
 			case "content_block_start":
-				if ev.ContentBlock.Type != "tool_use" {
-					continue
-				}
-				currentTool = &toolState{
-					id:   strings.TrimSpace(ev.ContentBlock.ID),
-					name: strings.TrimSpace(ev.ContentBlock.Name),
+				switch ev.ContentBlock.Type {
+				case "tool_use":
+					currentTool = &toolState{
+						id:   strings.TrimSpace(ev.ContentBlock.ID),
+						name: strings.TrimSpace(ev.ContentBlock.Name),
+					}
+				case "thinking":
+					currentThinking = &thinkingState{signature: ev.ContentBlock.Signature}
+					currentThinking.thinking.WriteString(ev.ContentBlock.Thinking)
+				case "redacted_thinking":
+					events <- ThinkingEvent{Block: redactedThinkingBlock(ev.ContentBlock.Data)}
 				}
 
 			case "content_block_delta":
@@ -130,9 +128,25 @@ func (c *Client) Stream(ctx context.Context, input Input) (<-chan StreamEvent, e
 					if currentTool != nil && ev.Delta.PartialJSON != "" {
 						currentTool.partialJSON.WriteString(ev.Delta.PartialJSON)
 					}
+				case "thinking_delta":
+					if currentThinking != nil {
+						currentThinking.thinking.WriteString(ev.Delta.Thinking)
+					}
+					if ev.Delta.Thinking != "" {
+						events <- ThinkingDeltaEvent{Delta: ev.Delta.Thinking}
+					}
+				case "signature_delta":
+					if currentThinking != nil {
+						currentThinking.signature += ev.Delta.Signature
+					}
 				}
 
 			case "content_block_stop":
+				if currentThinking != nil {
+					events <- ThinkingEvent{Block: thinkingBlock(currentThinking.thinking.String(), currentThinking.signature)}
+					currentThinking = nil
+					continue
+				}
 				if currentTool == nil {
 					continue
 				}
@@ -160,20 +174,21 @@ func (c *Client) Stream(ctx context.Context, input Input) (<-chan StreamEvent, e
 
 			case "message_delta":
 				stopReason = string(ev.Delta.StopReason)
-				u := capabilities.NormalizeUsage(int(ev.Usage.InputTokens), int(ev.Usage.OutputTokens), 0)
-				usageValue = &u
+				// Delta counts are cumulative; input and cache counts may be
+				// missing here, so keep the ones from message_start.
+				usageValue = mergeDeltaUsage(usageValue, ev.Usage)
 			}
 		}
 
 		if err := stream.Err(); err != nil {
-			events <- ErrorEvent{Err: fmt.Errorf("anthropic stream: %w", err)}
+			events <- ErrorEvent{Err: wrapError("anthropic stream", err)}
 			return
 		}
 
 		if stopReason == "" {
 			stopReason = "end_turn"
 		}
-		events <- DoneEvent{StopReason: stopReason, Usage: usageValue}
+		events <- DoneEvent{StopReason: stopReason, Usage: usageValue, Model: model}
 	}()
 
 	return events, nil
@@ -182,13 +197,20 @@ func (c *Client) Stream(ctx context.Context, input Input) (<-chan StreamEvent, e
 // CollectDecision converts Stream events into a Decision.
 // It returns an error if an ErrorEvent is received or the stream ends without DoneEvent.
 func CollectDecision(events <-chan StreamEvent) (Decision, error) {
+	return collectDecision(events, nil, nil)
+}
+
+// collectDecision is CollectDecision with optional callbacks for text and
+// thinking deltas.
+func collectDecision(events <-chan StreamEvent, onText, onThinking func(string)) (Decision, error) {
 	if events == nil {
 		return Decision{}, errors.New("anthropic stream: nil events channel")
 	}
 
 	var (
-		reply strings.Builder
-		calls []agentic.ToolCall
+		reply  strings.Builder
+		calls  []agentic.ToolCall
+		blocks []message.ProviderBlock
 
 		stop string
 		u    *usage.Usage
@@ -198,16 +220,28 @@ func CollectDecision(events <-chan StreamEvent) (Decision, error) {
 		switch e := ev.(type) {
 		case TextDeltaEvent:
 			reply.WriteString(e.Delta)
+			if onText != nil {
+				onText(e.Delta)
+			}
+		case ThinkingDeltaEvent:
+			if onThinking != nil {
+				onThinking(e.Delta)
+			}
 		case ToolUseEvent:
 			calls = append(calls, e.Call)
+		case ThinkingEvent:
+			blocks = append(blocks, e.Block)
 		case DoneEvent:
 			stop = e.StopReason
 			u = e.Usage
 			return Decision{
 				Reply:      strings.TrimSpace(reply.String()),
+				Reasoning:  reasoningText(blocks),
 				ToolCalls:  calls,
 				StopReason: stop,
 				Usage:      u,
+				Model:      e.Model,
+				Blocks:     blocks,
 			}, nil
 		case ErrorEvent:
 			if e.Err == nil {
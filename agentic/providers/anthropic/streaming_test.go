@@ -71,6 +71,42 @@ func TestAppendHistory_CoalescesConsecutiveToolMessages(t *testing.T) {
 	}
 }
 
+func TestAppendHistory_MapsContentParts(t *testing.T) {
+	history := []message.AgentMessage{
+		{Role: message.RoleUser, Content: "what is this?", Parts: []agentic.ContentPart{
+			agentic.ImagePart("image/png", []byte("png-bytes")),
+			agentic.DocumentPart("application/pdf", []byte("pdf-bytes")),
+		}},
+		{Role: message.RoleAssistant, ToolCalls: []agentic.ToolCall{{ID: "toolu_1", Name: "read", Input: json.RawMessage(`{}`)}}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{
+			ID:    "toolu_1",
+			Name:  "read",
+			Parts: []agentic.ContentPart{agentic.ImageURLPart("image/jpeg", "https://example.com/cat.jpg")},
+		}}},
+	}
+
+	msgs := appendHistory(nil, history)
+	if len(msgs) != 3 {
+		t.Fatalf("expected 3 anthropic messages, got %d", len(msgs))
+	}
+	user, err := json.Marshal(msgs[0])
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	for _, want := range []string{`"type":"image"`, `"media_type":"image/png"`, `"data":"cG5nLWJ5dGVz"`, `"type":"document"`, `"media_type":"application/pdf"`} {
+		if !bytes.Contains(user, []byte(want)) {
+			t.Fatalf("expected %s in user message, got %s", want, user)
+		}
+	}
+	tool, err := json.Marshal(msgs[2])
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if !bytes.Contains(tool, []byte(`"url":"https://example.com/cat.jpg"`)) || bytes.Contains(tool, []byte(`"null"`)) {
+		t.Fatalf("expected tool result with only the image, got %s", tool)
+	}
+}
+
 func TestCollectDecision_ErrorsOnNilChannel(t *testing.T) {
 	_, err := CollectDecision(nil)
 	if err == nil {
@@ -0,0 +1,75 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/victorarias/agentic-weave/agentic/message"
+)
+
+// ProviderName tags the message.ProviderBlock values this package stores.
+const ProviderName = "anthropic"
+
+// minThinkingBudget is the smallest budget the API accepts.
+const minThinkingBudget = 1024
+
+const (
+	blockThinking         = "thinking"
+	blockRedactedThinking = "redacted_thinking"
+)
+
+// thinkingData is the payload of a stored thinking block.
+type thinkingData struct {
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Data      string `json:"data,omitempty"`
+}
+
+func thinkingBlock(thinking, signature string) message.ProviderBlock {
+	data, _ := json.Marshal(thinkingData{Thinking: thinking, Signature: signature})
+	return message.ProviderBlock{Provider: ProviderName, Type: blockThinking, Data: data}
+}
+
+func redactedThinkingBlock(redacted string) message.ProviderBlock {
+	data, _ := json.Marshal(thinkingData{Data: redacted})
+	return message.ProviderBlock{Provider: ProviderName, Type: blockRedactedThinking, Data: data}
+}
+
+// replayThinking converts stored thinking blocks back into request blocks.
+// The API requires them unchanged, ahead of the text and tool_use blocks.
+func replayThinking(msg message.AgentMessage) []anthropic.ContentBlockParamUnion {
+	var out []anthropic.ContentBlockParamUnion
+	for _, block := range msg.Blocks(ProviderName) {
+		var data thinkingData
+		if err := json.Unmarshal(block.Data, &data); err != nil {
+			continue
+		}
+		switch block.Type {
+		case blockThinking:
+			if data.Signature != "" {
+				out = append(out, anthropic.NewThinkingBlock(data.Signature, data.Thinking))
+			}
+		case blockRedactedThinking:
+			if data.Data != "" {
+				out = append(out, anthropic.NewRedactedThinkingBlock(data.Data))
+			}
+		}
+	}
+	return out
+}
+
+// reasoningText joins the readable thinking of blocks.
+func reasoningText(blocks []message.ProviderBlock) string {
+	var parts []string
+	for _, block := range blocks {
+		if block.Type != blockThinking {
+			continue
+		}
+		var data thinkingData
+		if err := json.Unmarshal(block.Data, &data); err == nil && strings.TrimSpace(data.Thinking) != "" {
+			parts = append(parts, strings.TrimSpace(data.Thinking))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
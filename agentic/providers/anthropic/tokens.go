@@ -0,0 +1,40 @@
+package anthropic
+
+import (
+	"context"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/victorarias/agentic-weave/agentic/context/budget"
+)
+
+// CountTokens returns the input tokens the request for input would use,
+// via the Messages count_tokens endpoint.
+func (c *Client) CountTokens(ctx context.Context, input Input) (int, error) {
+	req := c.params(input)
+	params := anthropic.MessageCountTokensParams{
+		Model:    req.Model,
+		Messages: req.Messages,
+		Thinking: req.Thinking,
+	}
+	if len(req.System) > 0 {
+		params.System.OfTextBlockArray = req.System
+	}
+	for _, tool := range req.Tools {
+		if tool.OfTool != nil {
+			params.Tools = append(params.Tools, anthropic.MessageCountTokensToolUnionParam{OfTool: tool.OfTool})
+		}
+	}
+	res, err := c.client.Messages.CountTokens(ctx, params)
+	if err != nil {
+		return 0, wrapError("anthropic count tokens", err)
+	}
+	return int(res.InputTokens), nil
+}
+
+// NewTokenCounter returns a budget counter that sends each text as a user
+// message to count_tokens. Counts include a few tokens of message framing.
+func NewTokenCounter(client *Client, opts budget.RemoteOptions) *budget.RemoteCounter {
+	return budget.NewRemoteCounter(func(ctx context.Context, text string) (int, error) {
+		return client.CountTokens(ctx, Input{UserMessage: text})
+	}, opts)
+}
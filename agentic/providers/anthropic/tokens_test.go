@@ -0,0 +1,35 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic/context/budget"
+)
+
+func TestTokenCounterUsesCountTokensEndpoint(t *testing.T) {
+	var path string
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"input_tokens": 12}`)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{APIKey: "test", Model: "claude-test", BaseURL: server.URL, HTTPClient: server.Client()})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	counter := NewTokenCounter(client, budget.RemoteOptions{OnError: func(err error) { t.Fatalf("unexpected error: %v", err) }})
+	if got := counter.Count("hello there"); got != 12 {
+		t.Fatalf("expected 12 tokens, got %d", got)
+	}
+	if path != "/v1/messages/count_tokens" || body["model"] != "claude-test" || body["max_tokens"] != nil {
+		t.Fatalf("unexpected request %s %#v", path, body)
+	}
+}
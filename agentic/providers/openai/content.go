@@ -0,0 +1,131 @@
+package openai
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+// contentParts converts content parts to Chat Completions content parts.
+// Images become image_url parts, text documents become text and inline PDFs
+// become file parts. Other documents, including document URLs, are skipped
+// because the API cannot read them.
+func contentParts(parts []agentic.ContentPart) []chatContentPart {
+	var out []chatContentPart
+	for _, part := range parts {
+		switch part.Type {
+		case agentic.PartText:
+			if strings.TrimSpace(part.Text) != "" {
+				out = append(out, chatContentPart{Type: "text", Text: part.Text})
+			}
+		case agentic.PartImage:
+			if url := partURL(part); url != "" {
+				out = append(out, chatContentPart{Type: "image_url", ImageURL: &chatImageURL{URL: url}})
+			}
+		case agentic.PartDocument:
+			if chatPart, ok := documentPart(part); ok {
+				out = append(out, chatPart)
+			}
+		}
+	}
+	return out
+}
+
+func documentPart(part agentic.ContentPart) (chatContentPart, bool) {
+	switch {
+	case part.URL != "" || len(part.Data) == 0:
+		return chatContentPart{}, false
+	case strings.HasPrefix(part.MIMEType, "text/"):
+		return chatContentPart{Type: "text", Text: string(part.Data)}, true
+	case part.MIMEType == "" || part.MIMEType == "application/pdf":
+		return chatContentPart{Type: "file", File: &chatFile{
+			Filename: "document.pdf",
+			FileData: dataURL("application/pdf", part.Data),
+		}}, true
+	default:
+		return chatContentPart{}, false
+	}
+}
+
+// partURL returns the part's URL, or its inline data as a data URL.
+func partURL(part agentic.ContentPart) string {
+	if part.URL != "" {
+		return part.URL
+	}
+	if len(part.Data) == 0 {
+		return ""
+	}
+	return dataURL(part.MIMEType, part.Data)
+}
+
+func dataURL(mimeType string, data []byte) string {
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// userContent builds a user message from text and content parts. It reports
+// false when there is nothing to send.
+func userContent(text string, parts []agentic.ContentPart) (chatMessage, bool) {
+	converted := contentParts(parts)
+	if len(converted) == 0 {
+		if strings.TrimSpace(text) == "" {
+			return chatMessage{}, false
+		}
+		return chatMessage{Role: "user", Content: stringPtr(text)}, true
+	}
+	if strings.TrimSpace(text) != "" {
+		converted = append([]chatContentPart{{Type: "text", Text: text}}, converted...)
+	}
+	return chatMessage{Role: "user", Parts: converted}, true
+}
+
+// toolResultParts collects the content parts of tool results into one user
+// message, since role=tool messages only carry text. It reports false when
+// no result has parts the API can read.
+func toolResultParts(results []agentic.ToolResult) (chatMessage, bool) {
+	var parts []chatContentPart
+	for _, result := range results {
+		converted := contentParts(result.Parts)
+		if len(converted) == 0 {
+			continue
+		}
+		parts = append(parts, chatContentPart{Type: "text", Text: "Content from tool " + result.Name + ":"})
+		parts = append(parts, converted...)
+	}
+	if len(parts) == 0 {
+		return chatMessage{}, false
+	}
+	return chatMessage{Role: "user", Parts: parts}, true
+}
+
+type chatContentPart struct {
+	Type     string        `json:"type"`
+	Text     string        `json:"text,omitempty"`
+	ImageURL *chatImageURL `json:"image_url,omitempty"`
+	File     *chatFile     `json:"file,omitempty"`
+}
+
+type chatImageURL struct {
+	URL string `json:"url"`
+}
+
+type chatFile struct {
+	Filename string `json:"filename"`
+	FileData string `json:"file_data"`
+}
+
+// MarshalJSON sends Parts as the content array when present.
+func (m chatMessage) MarshalJSON() ([]byte, error) {
+	type plain chatMessage
+	if len(m.Parts) == 0 {
+		return json.Marshal(plain(m))
+	}
+	return json.Marshal(struct {
+		plain
+		Content []chatContentPart `json:"content"`
+	}{plain(m), m.Parts})
+}
@@ -0,0 +1,94 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/loop"
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+)
+
+func TestNormalizeStopReason(t *testing.T) {
+	cases := []struct {
+		finish   string
+		hasCalls bool
+		want     usage.StopReason
+	}{
+		{"tool_calls", true, usage.StopReasonTool},
+		{"stop", true, usage.StopReasonTool},
+		{"length", false, usage.StopReasonMaxTokens},
+		{"stop", false, usage.StopReasonStop},
+		{"", false, usage.StopReasonStop},
+		{"content_filter", false, usage.StopReason("content_filter")},
+	}
+	for _, tc := range cases {
+		if got := normalizeStopReason(tc.finish, tc.hasCalls); got != tc.want {
+			t.Fatalf("normalizeStopReason(%q, %v)=%v want %v", tc.finish, tc.hasCalls, got, tc.want)
+		}
+	}
+}
+
+func TestDeciderStreamsDeltas(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"b\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client, _ := New(Config{Model: "local", BaseURL: server.URL})
+	var deltas []string
+	decision, err := NewDecider(client, DeciderOptions{}).DecideStream(context.Background(), loop.Input{UserMessage: "hi"}, func(d loop.StreamDelta) {
+		deltas = append(deltas, d.Text)
+	})
+	if err != nil {
+		t.Fatalf("decide stream: %v", err)
+	}
+	if len(deltas) != 2 || decision.Reply != "ab" || decision.StopReason != usage.StopReasonStop {
+		t.Fatalf("unexpected result: deltas=%v decision=%#v", deltas, decision)
+	}
+}
+
+func TestDeciderSendsUserMessageOnce(t *testing.T) {
+	var sent struct {
+		Messages []struct {
+			Role    string  `json:"role"`
+			Content *string `json:"content"`
+		} `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"3"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+
+	client, _ := New(Config{Model: "local", BaseURL: server.URL})
+	history := []message.AgentMessage{
+		{Role: message.RoleUser, Content: "add 1 and 2"},
+		{Role: message.RoleAssistant, ToolCalls: []agentic.ToolCall{{ID: "call_1", Name: "add"}}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{ID: "call_1", Name: "add", Output: json.RawMessage(`3`)}}},
+	}
+	if _, err := NewDecider(client, DeciderOptions{}).Decide(context.Background(), loop.Input{SystemPrompt: "be brief", UserMessage: "add 1 and 2", History: history, Turn: 1}); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+
+	var got []string
+	for _, msg := range sent.Messages {
+		text := ""
+		if msg.Content != nil {
+			text = *msg.Content
+		}
+		got = append(got, msg.Role+":"+text)
+	}
+	want := []string{"system:be brief", "user:add 1 and 2", "assistant:", "tool:3"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected messages:\n got %v\nwant %v", got, want)
+	}
+}
@@ -0,0 +1,453 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/retry"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+	"github.com/victorarias/agentic-weave/capabilities"
+)
+
+// Input represents a single decision request to an OpenAI-compatible endpoint.
+// Tool calls and results should be included in History as AgentMessage entries.
+type Input struct {
+	SystemPrompt string
+	UserMessage  string
+	History      []message.AgentMessage
+	Tools        []agentic.ToolDefinition
+	MaxTokens    int
+	Temperature  *float64
+	// Reasoning is sent as reasoning_effort. Temperature is not sent with it.
+	Reasoning *capabilities.Reasoning
+}
+
+// Decision is the output from a single model call.
+type Decision struct {
+	Reply      string
+	ToolCalls  []agentic.ToolCall
+	StopReason string
+	Usage      *usage.Usage
+}
+
+// Config controls an OpenAI-compatible client.
+type Config struct {
+	APIKey      string // Optional for local servers (vLLM, llama.cpp, Ollama)
+	Model       string
+	BaseURL     string
+	MaxTokens   int
+	Temperature *float64
+	HTTPClient  *http.Client // Timeout applies to non-streaming calls only
+}
+
+// Client calls the Chat Completions API.
+type Client struct {
+	apiKey      string
+	model       string
+	baseURL     string
+	maxTokens   int
+	temperature *float64
+	client      *http.Client
+}
+
+// New constructs an OpenAI-compatible client from config.
+func New(cfg Config) (*Client, error) {
+	model := strings.TrimSpace(cfg.Model)
+	if model == "" {
+		return nil, errors.New("openai: model is required")
+	}
+
+	base := strings.TrimSpace(cfg.BaseURL)
+	if base == "" {
+		base = "https://api.openai.com/v1"
+	}
+
+	maxTokens := cfg.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 1024
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 120 * time.Second}
+	}
+
+	return &Client{
+		apiKey:      strings.TrimSpace(cfg.APIKey),
+		model:       model,
+		baseURL:     strings.TrimRight(base, "/"),
+		maxTokens:   maxTokens,
+		temperature: cfg.Temperature,
+		client:      client,
+	}, nil
+}
+
+// NewFromEnv builds an OpenAI-compatible client from environment variables.
+func NewFromEnv() (*Client, error) {
+	model := envTrimmed("OPENAI_MODEL")
+	if model == "" {
+		return nil, errors.New("openai: OPENAI_MODEL is required")
+	}
+
+	maxTokens := 0
+	if v := envTrimmed("OPENAI_MAX_TOKENS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			maxTokens = n
+		}
+	}
+
+	var temperature *float64
+	if v := envTrimmed("OPENAI_TEMPERATURE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			temperature = &f
+		}
+	}
+
+	return New(Config{
+		APIKey:      envTrimmed("OPENAI_API_KEY"),
+		Model:       model,
+		BaseURL:     envTrimmed("OPENAI_BASE_URL"),
+		MaxTokens:   maxTokens,
+		Temperature: temperature,
+	})
+}
+
+func envTrimmed(key string) string {
+	return strings.TrimSpace(os.Getenv(key))
+}
+
+// Decide calls the Chat Completions API.
+func (c *Client) Decide(ctx context.Context, input Input) (Decision, error) {
+	resp, err := c.post(ctx, c.buildRequest(input, false))
+	if err != nil {
+		return Decision{}, err
+	}
+	defer resp.Body.Close()
+
+	var parsed chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Decision{}, fmt.Errorf("openai: decode response: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return Decision{}, errors.New("openai: no choices in response")
+	}
+
+	choice := parsed.Choices[0]
+	calls := make([]agentic.ToolCall, 0, len(choice.Message.ToolCalls))
+	for i, tc := range choice.Message.ToolCalls {
+		calls = append(calls, toolCallFromWire(i, tc.ID, tc.Function.Name, tc.Function.Arguments))
+	}
+
+	decision := Decision{
+		Reply:      strings.TrimSpace(choice.Message.Content),
+		ToolCalls:  calls,
+		StopReason: choice.FinishReason,
+	}
+	if parsed.Usage != nil {
+		u := parsed.Usage.normalized()
+		decision.Usage = &u
+	}
+	return decision, nil
+}
+
+func (c *Client) post(ctx context.Context, body chatRequest) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if body.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient(body.Stream).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		text, _ := readResponseBody(resp)
+		return nil, retry.NewHTTPError(resp.StatusCode, resp.Header, fmt.Errorf("openai: status %d: %s", resp.StatusCode, text))
+	}
+	return resp, nil
+}
+
+// httpClient returns the client for a request. http.Client.Timeout covers
+// reading the whole body, so streams use a copy without it and rely on ctx
+// instead.
+func (c *Client) httpClient(stream bool) *http.Client {
+	if !stream || c.client.Timeout == 0 {
+		return c.client
+	}
+	client := *c.client
+	client.Timeout = 0
+	return &client
+}
+
+func readResponseBody(resp *http.Response) (string, error) {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	body := strings.TrimSpace(string(data))
+	if body == "" {
+		return "<empty body>", nil
+	}
+	if len(body) > 1200 {
+		return body[:1200] + "... (truncated)", nil
+	}
+	return body, nil
+}
+
+func (c *Client) buildRequest(input Input, stream bool) chatRequest {
+	messages := make([]chatMessage, 0, 2+len(input.History))
+	if system := strings.TrimSpace(input.SystemPrompt); system != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: stringPtr(system)})
+	}
+	messages = appendHistory(messages, input.History)
+	if userMessage := strings.TrimSpace(input.UserMessage); userMessage != "" {
+		messages = append(messages, chatMessage{Role: "user", Content: stringPtr(userMessage)})
+	}
+
+	req := chatRequest{
+		Model:     c.model,
+		Messages:  messages,
+		MaxTokens: c.maxTokens,
+		Stream:    stream,
+	}
+	if input.MaxTokens > 0 {
+		req.MaxTokens = input.MaxTokens
+	}
+	temperature := input.Temperature
+	if temperature == nil {
+		temperature = c.temperature
+	}
+	req.Temperature = temperature
+	if input.Reasoning != nil {
+		if effort := input.Reasoning.Level(); effort != "" {
+			req.ReasoningEffort = string(effort)
+			req.Temperature = nil
+		}
+	}
+
+	if len(input.Tools) > 0 {
+		req.Tools = toolDefsToOpenAI(input.Tools)
+	}
+	if stream {
+		req.StreamOptions = &chatStreamOptions{IncludeUsage: true}
+	}
+	return req
+}
+
+// appendHistory converts AgentMessage history to Chat Completions messages.
+// Content parts of tool results are sent in a user message after the run of
+// tool messages, which must directly follow the assistant's tool calls.
+func appendHistory(messages []chatMessage, history []message.AgentMessage) []chatMessage {
+	var toolParts []agentic.ToolResult
+	flushToolParts := func() {
+		if out, ok := toolResultParts(toolParts); ok {
+			messages = append(messages, out)
+		}
+		toolParts = nil
+	}
+	for _, msg := range history {
+		if msg.Role != message.RoleTool {
+			flushToolParts()
+		}
+		switch msg.Role {
+		case message.RoleUser:
+			if out, ok := userContent(msg.Content, msg.Parts); ok {
+				messages = append(messages, out)
+			}
+
+		case message.RoleAssistant:
+			out := chatMessage{Role: "assistant"}
+			if strings.TrimSpace(msg.Content) != "" {
+				out.Content = stringPtr(msg.Content)
+			}
+			for _, call := range msg.ToolCalls {
+				args := strings.TrimSpace(string(call.Input))
+				if args == "" {
+					args = "{}"
+				}
+				out.ToolCalls = append(out.ToolCalls, chatToolCall{
+					ID:   call.ID,
+					Type: "function",
+					Function: chatFunctionCall{
+						Name:      call.Name,
+						Arguments: args,
+					},
+				})
+			}
+			if out.Content != nil || len(out.ToolCalls) > 0 {
+				messages = append(messages, out)
+			}
+
+		case message.RoleTool:
+			// Chat Completions expects one role=tool message per tool_call_id.
+			for _, result := range msg.ToolResults {
+				id := strings.TrimSpace(result.ID)
+				if id == "" {
+					id = result.Name
+				}
+				messages = append(messages, chatMessage{
+					Role:       "tool",
+					ToolCallID: id,
+					Content:    stringPtr(toolResultContent(result)),
+				})
+			}
+			toolParts = append(toolParts, msg.ToolResults...)
+
+		case message.RoleSystem:
+			// System messages in history are typically summaries from compaction.
+			// Many local chat templates reject system messages after the first turn.
+			if strings.TrimSpace(msg.Content) != "" {
+				messages = append(messages, chatMessage{Role: "user", Content: stringPtr("[Context Summary] " + msg.Content)})
+			}
+		}
+	}
+	flushToolParts()
+	return messages
+}
+
+func toolDefsToOpenAI(tools []agentic.ToolDefinition) []chatTool {
+	out := make([]chatTool, 0, len(tools))
+	for _, tool := range tools {
+		out = append(out, chatTool{
+			Type: "function",
+			Function: chatFunctionDefinition{
+				Name:        tool.Name,
+				Description: strings.TrimSpace(tool.Description),
+				Parameters:  ensureSchema(tool.InputSchema),
+			},
+		})
+	}
+	return out
+}
+
+func ensureSchema(schema json.RawMessage) json.RawMessage {
+	if len(schema) == 0 || !json.Valid(schema) {
+		return json.RawMessage(`{"type":"object","properties":{}}`)
+	}
+	return schema
+}
+
+func toolResultContent(result agentic.ToolResult) string {
+	if result.Error != nil {
+		return "error: " + result.Error.Message
+	}
+	if len(result.Output) == 0 {
+		return "null"
+	}
+	return string(result.Output)
+}
+
+func toolCallFromWire(index int, id, name, arguments string) agentic.ToolCall {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		// Some local servers omit ids; keep results addressable.
+		id = fmt.Sprintf("call-%d", index)
+	}
+	args := strings.TrimSpace(arguments)
+	if args == "" || !json.Valid([]byte(args)) {
+		args = "{}"
+	}
+	return agentic.ToolCall{
+		ID:    id,
+		Name:  strings.TrimSpace(name),
+		Input: json.RawMessage(args),
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+type chatRequest struct {
+	Model           string             `json:"model"`
+	Messages        []chatMessage      `json:"messages"`
+	Tools           []chatTool         `json:"tools,omitempty"`
+	MaxTokens       int                `json:"max_tokens,omitempty"`
+	Temperature     *float64           `json:"temperature,omitempty"`
+	ReasoningEffort string             `json:"reasoning_effort,omitempty"`
+	Stream          bool               `json:"stream,omitempty"`
+	StreamOptions   *chatStreamOptions `json:"stream_options,omitempty"`
+}
+
+type chatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    *string        `json:"content,omitempty"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+	// Parts replaces Content with a content array when set.
+	Parts []chatContentPart `json:"-"`
+}
+
+type chatToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function chatFunctionCall `json:"function"`
+}
+
+type chatFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type chatTool struct {
+	Type     string                 `json:"type"`
+	Function chatFunctionDefinition `json:"function"`
+}
+
+type chatFunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+type chatResponse struct {
+	Choices []chatChoice `json:"choices"`
+	Usage   *chatUsage   `json:"usage,omitempty"`
+}
+
+type chatChoice struct {
+	Message      chatResponseMessage `json:"message"`
+	FinishReason string              `json:"finish_reason"`
+}
+
+type chatResponseMessage struct {
+	Content   string         `json:"content"`
+	ToolCalls []chatToolCall `json:"tool_calls"`
+}
+
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u chatUsage) normalized() usage.Usage {
+	return capabilities.NormalizeUsage(u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+}
@@ -0,0 +1,274 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/retry"
+	"github.com/victorarias/agentic-weave/capabilities"
+)
+
+func TestAppendHistoryParallelToolCalls(t *testing.T) {
+	history := []message.AgentMessage{
+		{Role: message.RoleUser, Content: "hi"},
+		{
+			Role:    message.RoleAssistant,
+			Content: "checking",
+			ToolCalls: []agentic.ToolCall{
+				{ID: "call_a", Name: "a", Input: json.RawMessage(`{"x":1}`)},
+				{ID: "call_b", Name: "b"},
+			},
+		},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{ID: "call_a", Name: "a", Output: json.RawMessage(`"one"`)}}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{ID: "call_b", Name: "b", Error: &agentic.ToolError{Message: "boom"}}}},
+		{Role: message.RoleSystem, Content: "earlier summary"},
+	}
+
+	msgs := appendHistory(nil, history)
+	if len(msgs) != 5 {
+		t.Fatalf("expected 5 messages, got %d", len(msgs))
+	}
+	if msgs[1].Role != "assistant" || len(msgs[1].ToolCalls) != 2 {
+		t.Fatalf("expected assistant message with 2 tool calls, got %#v", msgs[1])
+	}
+	if msgs[1].ToolCalls[1].Function.Arguments != "{}" {
+		t.Fatalf("expected empty args to become {}, got %q", msgs[1].ToolCalls[1].Function.Arguments)
+	}
+	if msgs[2].Role != "tool" || msgs[2].ToolCallID != "call_a" || *msgs[2].Content != `"one"` {
+		t.Fatalf("unexpected first tool message: %#v", msgs[2])
+	}
+	if msgs[3].ToolCallID != "call_b" || !strings.Contains(*msgs[3].Content, "boom") {
+		t.Fatalf("unexpected second tool message: %#v", msgs[3])
+	}
+	if msgs[4].Role != "user" || !strings.HasPrefix(*msgs[4].Content, "[Context Summary]") {
+		t.Fatalf("expected summary as user message, got %#v", msgs[4])
+	}
+}
+
+func TestDecideParsesToolCallsAndUsage(t *testing.T) {
+	var captured chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("unexpected auth header %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &captured); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = io.WriteString(w, `{
+			"choices": [{
+				"message": {
+					"content": null,
+					"tool_calls": [
+						{"id": "call_1", "type": "function", "function": {"name": "add", "arguments": "{\"a\":1}"}},
+						{"id": "call_2", "type": "function", "function": {"name": "sub", "arguments": "{\"b\":2}"}}
+					]
+				},
+				"finish_reason": "tool_calls"
+			}],
+			"usage": {"prompt_tokens": 10, "completion_tokens": 5}
+		}`)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{APIKey: "sk-test", Model: "gpt-test", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	decision, err := client.Decide(context.Background(), Input{
+		SystemPrompt: "be brief",
+		UserMessage:  "add",
+		Tools:        []agentic.ToolDefinition{{Name: "add", InputSchema: json.RawMessage(`{"type":"object"}`)}},
+	})
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if len(decision.ToolCalls) != 2 || decision.ToolCalls[1].Name != "sub" {
+		t.Fatalf("unexpected tool calls: %#v", decision.ToolCalls)
+	}
+	if decision.StopReason != "tool_calls" {
+		t.Fatalf("unexpected stop reason %q", decision.StopReason)
+	}
+	if decision.Usage == nil || decision.Usage.Input != 10 || decision.Usage.Total != 15 {
+		t.Fatalf("unexpected usage: %#v", decision.Usage)
+	}
+	if captured.Model != "gpt-test" || len(captured.Messages) != 2 || captured.Messages[0].Role != "system" {
+		t.Fatalf("unexpected request: %#v", captured)
+	}
+	if len(captured.Tools) != 1 || captured.Tools[0].Function.Name != "add" {
+		t.Fatalf("unexpected tools: %#v", captured.Tools)
+	}
+}
+
+func TestDecideReturnsStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"error":"slow down"}`)
+	}))
+	defer server.Close()
+
+	client, _ := New(Config{Model: "local", BaseURL: server.URL})
+	_, err := client.Decide(context.Background(), Input{UserMessage: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "status 429") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+	var httpErr *retry.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.RetryAfter != 2*time.Second {
+		t.Fatalf("expected retry.HTTPError with Retry-After, got %#v", err)
+	}
+	if retry.Classify(err) != retry.Retryable {
+		t.Fatalf("expected 429 to be retryable")
+	}
+}
+
+func TestStreamAccumulatesDeltas(t *testing.T) {
+	chunks := []string{
+		`{"choices":[{"delta":{"content":"Hel"}}]}`,
+		`{"choices":[{"delta":{"content":"lo"}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":1,"id":"call_b","function":{"name":"b","arguments":"{\"y\""}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_a","function":{"name":"a","arguments":""}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":1,"function":{"arguments":":2}"}}]}}]}`,
+		`{"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+	}
+	var captured chatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &captured)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client, _ := New(Config{Model: "local", BaseURL: server.URL})
+	events, err := client.Stream(context.Background(), Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	decision, err := CollectDecision(events)
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if !captured.Stream || captured.StreamOptions == nil || !captured.StreamOptions.IncludeUsage {
+		t.Fatalf("expected streaming request with usage, got %#v", captured)
+	}
+	if decision.Reply != "Hello" {
+		t.Fatalf("unexpected reply %q", decision.Reply)
+	}
+	if len(decision.ToolCalls) != 2 || decision.ToolCalls[0].ID != "call_a" || decision.ToolCalls[1].ID != "call_b" {
+		t.Fatalf("unexpected tool calls: %#v", decision.ToolCalls)
+	}
+	if string(decision.ToolCalls[0].Input) != "{}" || string(decision.ToolCalls[1].Input) != `{"y":2}` {
+		t.Fatalf("unexpected tool inputs: %s / %s", decision.ToolCalls[0].Input, decision.ToolCalls[1].Input)
+	}
+	if decision.StopReason != "tool_calls" || decision.Usage == nil || decision.Usage.Total != 7 {
+		t.Fatalf("unexpected stop/usage: %q %#v", decision.StopReason, decision.Usage)
+	}
+}
+
+func TestStreamOutlivesClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"lo\"},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client, _ := New(Config{Model: "local", BaseURL: server.URL, HTTPClient: &http.Client{Timeout: 50 * time.Millisecond}})
+	events, err := client.Stream(context.Background(), Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	decision, err := CollectDecision(events)
+	if err != nil {
+		t.Fatalf("expected the stream to outlive the client timeout, got %v", err)
+	}
+	if decision.Reply != "Hello" {
+		t.Fatalf("unexpected reply %q", decision.Reply)
+	}
+}
+
+func TestBuildRequestSendsReasoningEffort(t *testing.T) {
+	temperature := 0.3
+	client := &Client{model: "o-test", maxTokens: 1024, temperature: &temperature}
+
+	req := client.buildRequest(Input{UserMessage: "hi", Reasoning: &capabilities.Reasoning{BudgetTokens: 16000}}, false)
+	if req.ReasoningEffort != "high" || req.Temperature != nil {
+		t.Fatalf("expected high effort without temperature, got %#v", req)
+	}
+
+	req = client.buildRequest(Input{UserMessage: "hi"}, false)
+	if req.ReasoningEffort != "" || req.Temperature == nil {
+		t.Fatalf("expected plain request, got %#v", req)
+	}
+}
+
+func TestAppendHistoryMapsContentParts(t *testing.T) {
+	history := []message.AgentMessage{
+		{Role: message.RoleUser, Parts: []agentic.ContentPart{agentic.ImagePart("image/png", []byte("png"))}},
+		{Role: message.RoleAssistant, ToolCalls: []agentic.ToolCall{{ID: "call_a", Name: "shot"}, {ID: "call_b", Name: "read"}}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{ID: "call_a", Name: "shot", Parts: []agentic.ContentPart{
+			agentic.ImageURLPart("image/jpeg", "https://example.com/cat.jpg"),
+		}}}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{ID: "call_b", Name: "read", Output: json.RawMessage(`"ok"`), Parts: []agentic.ContentPart{
+			agentic.DocumentPart("text/plain", []byte("notes")),
+			agentic.DocumentPart("application/pdf", []byte("%PDF")),
+			agentic.DocumentURLPart("application/pdf", "https://example.com/a.pdf"),
+		}}}},
+		{Role: message.RoleUser, Content: "what now?"},
+	}
+
+	data, err := json.Marshal(appendHistory(nil, history))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var msgs []struct {
+		Role       string          `json:"role"`
+		ToolCallID string          `json:"tool_call_id"`
+		Content    json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &msgs); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(msgs) != 6 {
+		t.Fatalf("expected 6 messages, got %s", data)
+	}
+	if got := string(msgs[0].Content); got != `[{"type":"image_url","image_url":{"url":"data:image/png;base64,cG5n"}}]` {
+		t.Fatalf("unexpected image message: %s", got)
+	}
+	if msgs[2].ToolCallID != "call_a" || msgs[3].ToolCallID != "call_b" {
+		t.Fatalf("expected tool messages to follow the tool calls, got %s", data)
+	}
+	var parts []chatContentPart
+	if err := json.Unmarshal(msgs[4].Content, &parts); err != nil || msgs[4].Role != "user" {
+		t.Fatalf("expected tool parts as a user message, got %s", data)
+	}
+	if len(parts) != 5 || parts[1].ImageURL == nil || parts[1].ImageURL.URL != "https://example.com/cat.jpg" ||
+		parts[3].Text != "notes" || parts[4].File == nil || parts[4].File.FileData != "data:application/pdf;base64,JVBERg==" {
+		t.Fatalf("unexpected tool parts: %+v", parts)
+	}
+	if string(msgs[5].Content) != `"what now?"` {
+		t.Fatalf("expected plain text content, got %s", msgs[5].Content)
+	}
+}
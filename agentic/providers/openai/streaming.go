@@ -0,0 +1,217 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+)
+
+// StreamEvent represents a single streaming event emitted by Stream.
+// Consumers can reconstruct a final Decision by concatenating TextDeltaEvent
+// deltas and collecting ToolUseEvent calls until DoneEvent is received.
+type StreamEvent interface {
+	openaiStreamEvent()
+}
+
+// TextDeltaEvent represents incremental text from the model.
+type TextDeltaEvent struct {
+	Delta string
+}
+
+func (TextDeltaEvent) openaiStreamEvent() {}
+
+// ToolUseEvent represents a fully-formed tool call requested by the model.
+type ToolUseEvent struct {
+	Call agentic.ToolCall
+}
+
+func (ToolUseEvent) openaiStreamEvent() {}
+
+// DoneEvent signals completion of the stream.
+type DoneEvent struct {
+	StopReason string
+	Usage      *usage.Usage
+}
+
+func (DoneEvent) openaiStreamEvent() {}
+
+// ErrorEvent signals a stream error. If received, the stream will end shortly after.
+type ErrorEvent struct {
+	Err error
+}
+
+func (ErrorEvent) openaiStreamEvent() {}
+
+// Stream calls the Chat Completions API with stream=true.
+//
+// Tool call fragments are accumulated per index and emitted as ToolUseEvent
+// values once the stream finishes, in index order.
+func (c *Client) Stream(ctx context.Context, input Input) (<-chan StreamEvent, error) {
+	resp, err := c.post(ctx, c.buildRequest(input, true))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan StreamEvent, 32)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		type toolState struct {
+			id        string
+			name      string
+			arguments strings.Builder
+		}
+		var (
+			stopReason string
+			usageValue *usage.Usage
+			tools      = map[int]*toolState{}
+		)
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "" {
+				continue
+			}
+			if data == "[DONE]" {
+				break
+			}
+
+			var chunk chatStreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				events <- ErrorEvent{Err: fmt.Errorf("openai stream: decode chunk: %w", err)}
+				return
+			}
+			if chunk.Usage != nil {
+				u := chunk.Usage.normalized()
+				usageValue = &u
+			}
+			for _, choice := range chunk.Choices {
+				if choice.Delta.Content != "" {
+					events <- TextDeltaEvent{Delta: choice.Delta.Content}
+				}
+				for _, tc := range choice.Delta.ToolCalls {
+					state := tools[tc.Index]
+					if state == nil {
+						state = &toolState{}
+						tools[tc.Index] = state
+					}
+					if tc.ID != "" {
+						state.id = tc.ID
+					}
+					if tc.Function.Name != "" {
+						state.name = tc.Function.Name
+					}
+					state.arguments.WriteString(tc.Function.Arguments)
+				}
+				if choice.FinishReason != "" {
+					stopReason = choice.FinishReason
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			events <- ErrorEvent{Err: fmt.Errorf("openai stream: %w", err)}
+			return
+		}
+
+		indexes := make([]int, 0, len(tools))
+		for idx := range tools {
+			indexes = append(indexes, idx)
+		}
+		sort.Ints(indexes)
+		for _, idx := range indexes {
+			state := tools[idx]
+			rawJSON := strings.TrimSpace(state.arguments.String())
+			if rawJSON != "" && !json.Valid([]byte(rawJSON)) {
+				events <- ErrorEvent{Err: fmt.Errorf("openai stream: invalid tool arguments json for %q (%s): %q", state.name, state.id, rawJSON)}
+				return
+			}
+			events <- ToolUseEvent{Call: toolCallFromWire(idx, state.id, state.name, rawJSON)}
+		}
+
+		if stopReason == "" {
+			stopReason = "stop"
+		}
+		events <- DoneEvent{StopReason: stopReason, Usage: usageValue}
+	}()
+
+	return events, nil
+}
+
+// CollectDecision converts Stream events into a Decision.
+// It returns an error if an ErrorEvent is received or the stream ends without DoneEvent.
+func CollectDecision(events <-chan StreamEvent) (Decision, error) {
+	return collectDecision(events, nil)
+}
+
+// collectDecision is CollectDecision with an optional callback for text deltas.
+func collectDecision(events <-chan StreamEvent, onText func(string)) (Decision, error) {
+	if events == nil {
+		return Decision{}, errors.New("openai stream: nil events channel")
+	}
+
+	var (
+		reply strings.Builder
+		calls []agentic.ToolCall
+	)
+
+	for ev := range events {
+		switch e := ev.(type) {
+		case TextDeltaEvent:
+			reply.WriteString(e.Delta)
+			if onText != nil {
+				onText(e.Delta)
+			}
+		case ToolUseEvent:
+			calls = append(calls, e.Call)
+		case DoneEvent:
+			return Decision{
+				Reply:      strings.TrimSpace(reply.String()),
+				ToolCalls:  calls,
+				StopReason: e.StopReason,
+				Usage:      e.Usage,
+			}, nil
+		case ErrorEvent:
+			if e.Err == nil {
+				return Decision{}, errors.New("openai stream failed")
+			}
+			return Decision{}, e.Err
+		}
+	}
+
+	return Decision{}, errors.New("openai stream ended without done event")
+}
+
+type chatStreamChunk struct {
+	Choices []chatStreamChoice `json:"choices"`
+	Usage   *chatUsage         `json:"usage,omitempty"`
+}
+
+type chatStreamChoice struct {
+	Delta        chatStreamDelta `json:"delta"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+type chatStreamDelta struct {
+	Content   string                `json:"content"`
+	ToolCalls []chatStreamToolDelta `json:"tool_calls"`
+}
+
+type chatStreamToolDelta struct {
+	Index    int              `json:"index"`
+	ID       string           `json:"id"`
+	Function chatFunctionCall `json:"function"`
+}
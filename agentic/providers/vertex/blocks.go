@@ -0,0 +1,42 @@
+package vertex
+
+import (
+	"encoding/json"
+
+	"github.com/victorarias/agentic-weave/agentic/message"
+)
+
+// ProviderName tags the message.ProviderBlock values this package stores.
+const ProviderName = "vertex"
+
+const blockThought = "thought"
+
+// thoughtData is the payload of a stored thought block.
+type thoughtData struct {
+	Text      string `json:"text,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// thoughtBlocks stores reasoning text and the signature of non-call parts.
+// Function call signatures travel on agentic.ToolCall instead.
+func thoughtBlocks(reasoning, signature string) []message.ProviderBlock {
+	if reasoning == "" && signature == "" {
+		return nil
+	}
+	data, _ := json.Marshal(thoughtData{Text: reasoning, Signature: signature})
+	return []message.ProviderBlock{{Provider: ProviderName, Type: blockThought, Data: data}}
+}
+
+// replaySignature returns the stored thought signature of an assistant message.
+func replaySignature(msg message.AgentMessage) string {
+	for _, block := range msg.Blocks(ProviderName) {
+		if block.Type != blockThought {
+			continue
+		}
+		var data thoughtData
+		if err := json.Unmarshal(block.Data, &data); err == nil && data.Signature != "" {
+			return data.Signature
+		}
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+package vertex
+
+import (
+	"strings"
+
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+// contentParts converts content parts to request parts: inline data for
+// bytes and file data for URLs.
+func contentParts(parts []agentic.ContentPart) []vertexPart {
+	var out []vertexPart
+	for _, part := range parts {
+		switch {
+		case part.Type == agentic.PartText:
+			if strings.TrimSpace(part.Text) != "" {
+				out = append(out, vertexPart{Text: part.Text})
+			}
+		case part.URL != "":
+			out = append(out, vertexPart{FileData: &vertexFileData{MimeType: part.MIMEType, FileURI: part.URL}})
+		case len(part.Data) > 0:
+			out = append(out, vertexPart{InlineData: &vertexBlob{MimeType: part.MIMEType, Data: part.Data}})
+		}
+	}
+	return out
+}
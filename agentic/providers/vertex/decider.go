@@ -0,0 +1,84 @@
+package vertex
+
+import (
+	"context"
+
+	"github.com/victorarias/agentic-weave/agentic/loop"
+)
+
+// DeciderOptions configures a Decider.
+type DeciderOptions struct {
+	GoogleSearch bool
+}
+
+// Decider adapts a Client to loop.Decider and loop.StreamingDecider.
+type Decider struct {
+	client *Client
+	opts   DeciderOptions
+}
+
+// NewDecider wraps client for use as a loop decider.
+func NewDecider(client *Client, opts DeciderOptions) *Decider {
+	return &Decider{client: client, opts: opts}
+}
+
+// Decide implements loop.Decider.
+func (d *Decider) Decide(ctx context.Context, in loop.Input) (loop.Decision, error) {
+	decision, err := d.client.Decide(ctx, d.input(in))
+	if err != nil {
+		return loop.Decision{}, err
+	}
+	return toLoopDecision(decision), nil
+}
+
+// DecideStream implements loop.StreamingDecider.
+func (d *Decider) DecideStream(ctx context.Context, in loop.Input, onDelta func(loop.StreamDelta)) (loop.Decision, error) {
+	events, err := d.client.Stream(ctx, d.input(in))
+	if err != nil {
+		return loop.Decision{}, err
+	}
+	decision, err := collectDecision(events, func(text string) {
+		if onDelta != nil {
+			onDelta(loop.StreamDelta{Text: text})
+		}
+	}, func(thought string) {
+		if onDelta != nil {
+			onDelta(loop.StreamDelta{Reasoning: thought})
+		}
+	})
+	if err != nil {
+		return loop.Decision{}, err
+	}
+	return toLoopDecision(decision), nil
+}
+
+// input maps a loop input to a request. The loop has already added the user
+// message to History, so UserMessage is only sent when there is no history.
+func (d *Decider) input(in loop.Input) Input {
+	userMessage := in.UserMessage
+	if len(in.History) > 0 {
+		userMessage = ""
+	}
+	return Input{
+		SystemPrompt: in.SystemPrompt,
+		UserMessage:  userMessage,
+		History:      in.History,
+		Tools:        in.Tools,
+		GoogleSearch: d.opts.GoogleSearch,
+		Reasoning:    in.Reasoning,
+	}
+}
+
+// toLoopDecision carries reasoning and thought signatures (on ToolCalls and
+// provider blocks) through.
+func toLoopDecision(decision Decision) loop.Decision {
+	return loop.Decision{
+		Reply:          decision.Reply,
+		Reasoning:      decision.Reasoning,
+		ToolCalls:      decision.ToolCalls,
+		Usage:          decision.Usage,
+		StopReason:     decision.StopReason,
+		Model:          decision.Model,
+		ProviderBlocks: decision.Blocks,
+	}
+}
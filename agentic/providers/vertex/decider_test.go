@@ -0,0 +1,84 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/loop"
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+)
+
+func TestDeciderCarriesReasoningAndSignatures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"candidates": [{
+				"content": {"role": "model", "parts": [
+					{"text": "weighing options", "thought": true},
+					{"functionCall": {"name": "lookup", "args": {}}, "thoughtSignature": "sig-9"}
+				]},
+				"finishReason": "STOP"
+			}],
+			"usageMetadata": {"promptTokenCount": 2, "candidatesTokenCount": 1, "totalTokenCount": 3}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &Client{model: "gemini-pro", baseURL: server.URL, client: server.Client(), apiKey: "key"}
+	var decider loop.StreamingDecider = NewDecider(client, DeciderOptions{})
+
+	decision, err := decider.Decide(context.Background(), loop.Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if decision.Reasoning != "weighing options" {
+		t.Fatalf("expected reasoning, got %q", decision.Reasoning)
+	}
+	if len(decision.ToolCalls) != 1 || decision.ToolCalls[0].ThoughtSignature != "sig-9" {
+		t.Fatalf("expected thought signature on tool call, got %#v", decision.ToolCalls)
+	}
+	if decision.StopReason != usage.StopReasonTool || decision.Usage == nil || decision.Usage.Total != 3 {
+		t.Fatalf("unexpected stop/usage: %q %#v", decision.StopReason, decision.Usage)
+	}
+}
+
+func TestDeciderSendsUserMessageOnce(t *testing.T) {
+	var sent vertexRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"3"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	client := &Client{model: "gemini-pro", baseURL: server.URL, client: server.Client(), apiKey: "key"}
+	history := []message.AgentMessage{
+		{Role: message.RoleUser, Content: "add 1 and 2"},
+		{Role: message.RoleAssistant, ToolCalls: []agentic.ToolCall{{ID: "call_1", Name: "add", Input: json.RawMessage(`{}`)}}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{ID: "call_1", Name: "add", Output: json.RawMessage(`3`)}}},
+	}
+	if _, err := NewDecider(client, DeciderOptions{}).Decide(context.Background(), loop.Input{UserMessage: "add 1 and 2", History: history, Turn: 1}); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+
+	var got []string
+	for _, content := range sent.Contents {
+		for _, part := range content.Parts {
+			kind := "text:" + part.Text
+			if part.FunctionCall != nil {
+				kind = "call"
+			} else if part.FunctionResponse != nil {
+				kind = "response"
+			}
+			got = append(got, content.Role+":"+kind)
+		}
+	}
+	want := []string{"user:text:add 1 and 2", "model:call", "user:response"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected contents:\n got %v\nwant %v", got, want)
+	}
+}
@@ -0,0 +1,201 @@
+package vertex
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+)
+
+// StreamEvent represents a single streaming event emitted by Stream.
+// Consumers can reconstruct a final Decision by concatenating TextDeltaEvent
+// and ThoughtDeltaEvent deltas and collecting ToolUseEvent calls until
+// DoneEvent is received.
+type StreamEvent interface {
+	vertexStreamEvent()
+}
+
+// TextDeltaEvent represents incremental reply text from the model.
+type TextDeltaEvent struct {
+	Delta string
+}
+
+func (TextDeltaEvent) vertexStreamEvent() {}
+
+// ThoughtDeltaEvent represents incremental reasoning text from the model.
+type ThoughtDeltaEvent struct {
+	Delta string
+}
+
+func (ThoughtDeltaEvent) vertexStreamEvent() {}
+
+// ToolUseEvent represents a function call requested by the model.
+type ToolUseEvent struct {
+	Call agentic.ToolCall
+}
+
+func (ToolUseEvent) vertexStreamEvent() {}
+
+// DoneEvent signals completion of the stream.
+type DoneEvent struct {
+	FinishReason string
+	StopReason   usage.StopReason
+	Usage        *usage.Usage
+	Model        string
+	// ThoughtSignature is the signature of the last non-call part, if any.
+	ThoughtSignature string
+}
+
+func (DoneEvent) vertexStreamEvent() {}
+
+// ErrorEvent signals a stream error. If received, the stream will end shortly after.
+type ErrorEvent struct {
+	Err error
+}
+
+func (ErrorEvent) vertexStreamEvent() {}
+
+// Stream calls Vertex AI streamGenerateContent with server-sent events.
+func (c *Client) Stream(ctx context.Context, input Input) (<-chan StreamEvent, error) {
+	resp, err := c.post(ctx, "streamGenerateContent", input)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan StreamEvent, 32)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		var (
+			finishReason string
+			meta         *vertexUsageMetadata
+			callCount    int
+			hasCalls     bool
+			model        string
+			signature    string
+		)
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "" {
+				continue
+			}
+
+			var chunk vertexResponse
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				events <- ErrorEvent{Err: fmt.Errorf("vertex stream: decode chunk: %w", err)}
+				return
+			}
+			if chunk.ModelVersion != "" {
+				model = chunk.ModelVersion
+			}
+			if chunk.UsageMetadata != nil {
+				// Usage is cumulative; the last chunk carries the final counts.
+				meta = chunk.UsageMetadata
+			}
+			if len(chunk.Candidates) == 0 {
+				continue
+			}
+			candidate := chunk.Candidates[0]
+			for _, part := range candidate.Content.Parts {
+				if part.FunctionCall == nil && part.ThoughtSignature != "" {
+					signature = part.ThoughtSignature
+				}
+				switch {
+				case part.FunctionCall != nil:
+					events <- ToolUseEvent{Call: toolCallFromPart(callCount, part)}
+					callCount++
+					hasCalls = true
+				case part.Thought != "":
+					events <- ThoughtDeltaEvent{Delta: part.Thought}
+				case part.Text != "":
+					events <- TextDeltaEvent{Delta: part.Text}
+				}
+			}
+			if candidate.FinishReason != "" {
+				finishReason = candidate.FinishReason
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			events <- ErrorEvent{Err: fmt.Errorf("vertex stream: %w", err)}
+			return
+		}
+
+		done := DoneEvent{
+			FinishReason:     finishReason,
+			StopReason:       stopReasonFor(finishReason, hasCalls),
+			Model:            c.modelName(model),
+			ThoughtSignature: signature,
+		}
+		if meta != nil {
+			u := meta.normalized()
+			done.Usage = &u
+		}
+		events <- done
+	}()
+
+	return events, nil
+}
+
+// CollectDecision converts Stream events into a Decision.
+// It returns an error if an ErrorEvent is received or the stream ends without DoneEvent.
+func CollectDecision(events <-chan StreamEvent) (Decision, error) {
+	return collectDecision(events, nil, nil)
+}
+
+// collectDecision is CollectDecision with optional callbacks for text and
+// thought deltas.
+func collectDecision(events <-chan StreamEvent, onText, onThought func(string)) (Decision, error) {
+	if events == nil {
+		return Decision{}, errors.New("vertex stream: nil events channel")
+	}
+
+	var (
+		reply     strings.Builder
+		reasoning strings.Builder
+		calls     []agentic.ToolCall
+	)
+
+	for ev := range events {
+		switch e := ev.(type) {
+		case TextDeltaEvent:
+			reply.WriteString(e.Delta)
+			if onText != nil {
+				onText(e.Delta)
+			}
+		case ThoughtDeltaEvent:
+			reasoning.WriteString(e.Delta)
+			if onThought != nil {
+				onThought(e.Delta)
+			}
+		case ToolUseEvent:
+			calls = append(calls, e.Call)
+		case DoneEvent:
+			reasoningText := strings.TrimSpace(reasoning.String())
+			decision := buildDecision(reply.String(), reasoningText, calls, e.FinishReason, nil)
+			decision.Usage = e.Usage
+			decision.Model = e.Model
+			decision.Blocks = thoughtBlocks(reasoningText, e.ThoughtSignature)
+			return decision, nil
+		case ErrorEvent:
+			if e.Err == nil {
+				return Decision{}, errors.New("vertex stream failed")
+			}
+			return Decision{}, e.Err
+		}
+	}
+
+	return Decision{}, errors.New("vertex stream ended without done event")
+}
@@ -0,0 +1,35 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/victorarias/agentic-weave/agentic/context/budget"
+)
+
+// CountTokens returns the prompt tokens the request for input would use,
+// via the countTokens method.
+func (c *Client) CountTokens(ctx context.Context, input Input) (int, error) {
+	resp, err := c.post(ctx, "countTokens", input)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var parsed struct {
+		TotalTokens int `json:"totalTokens"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return 0, fmt.Errorf("vertex count tokens: %w", err)
+	}
+	return parsed.TotalTokens, nil
+}
+
+// NewTokenCounter returns a budget counter that sends each text as a user
+// message to countTokens.
+func NewTokenCounter(client *Client, opts budget.RemoteOptions) *budget.RemoteCounter {
+	return budget.NewRemoteCounter(func(ctx context.Context, text string) (int, error) {
+		return client.CountTokens(ctx, Input{UserMessage: text})
+	}, opts)
+}
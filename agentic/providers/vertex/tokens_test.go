@@ -0,0 +1,36 @@
+package vertex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic/context/budget"
+)
+
+func TestTokenCounterUsesCountTokens(t *testing.T) {
+	var path string
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"totalTokens": 7}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		model:   "gemini-pro",
+		baseURL: server.URL,
+		client:  server.Client(),
+		apiKey:  "key",
+	}
+	counter := NewTokenCounter(client, budget.RemoteOptions{OnError: func(err error) { t.Fatalf("unexpected error: %v", err) }})
+	if got := counter.Count("hello there"); got != 7 {
+		t.Fatalf("expected 7 tokens, got %d", got)
+	}
+	if !strings.HasSuffix(path, "gemini-pro:countTokens") || len(body["contents"].([]any)) != 1 {
+		t.Fatalf("unexpected request %s %#v", path, body)
+	}
+}
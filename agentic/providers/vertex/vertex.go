@@ -15,6 +15,9 @@ import (
 
 	"github.com/victorarias/agentic-weave/agentic"
 	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/retry"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+	"github.com/victorarias/agentic-weave/capabilities"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -27,6 +30,8 @@ type Input struct {
 	History      []message.AgentMessage
 	Tools        []agentic.ToolDefinition
 	GoogleSearch bool // Enable grounding with Google Search
+	// Reasoning sets a thinking budget and asks for thought summaries.
+	Reasoning *capabilities.Reasoning
 }
 
 // Decision is the output from a single model call.
@@ -35,6 +40,12 @@ type Decision struct {
 	Reasoning    string
 	ToolCalls    []agentic.ToolCall
 	FinishReason string
+	StopReason   usage.StopReason
+	Usage        *usage.Usage
+	Model        string
+	// Blocks keeps reasoning and the thought signature of non-call parts for
+	// the assistant message; the signature is replayed on later requests.
+	Blocks []message.ProviderBlock
 }
 
 // Config controls a Vertex Gemini client.
@@ -45,7 +56,7 @@ type Config struct {
 	BaseURL     string
 	Temperature float64
 	MaxTokens   int
-	HTTPClient  *http.Client
+	HTTPClient  *http.Client // Timeout applies to non-streaming calls only
 	TokenSource oauth2.TokenSource
 	APIKey      string // Optional: use API key auth instead of OAuth2
 }
@@ -162,49 +173,12 @@ func envTrimmed(key string) string {
 
 // Decide calls Vertex AI generateContent.
 func (c *Client) Decide(ctx context.Context, input Input) (Decision, error) {
-	if c.cred == nil && c.apiKey == "" {
-		return Decision{}, errors.New("no auth configured (need token source or API key)")
-	}
-	reqBody, err := c.buildRequest(input)
-	if err != nil {
-		return Decision{}, err
-	}
-
-	var endpoint string
-	if c.apiKey != "" {
-		// API key auth uses the publishers endpoint
-		endpoint = fmt.Sprintf("%s/publishers/google/models/%s:generateContent?key=%s", c.baseURL, c.model, c.apiKey)
-	} else {
-		// OAuth2 auth uses the project/location endpoint
-		endpoint = fmt.Sprintf("%s/projects/%s/locations/%s/publishers/google/models/%s:generateContent", c.baseURL, c.project, c.location, c.model)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(reqBody))
-	if err != nil {
-		return Decision{}, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Set auth header for OAuth2 (not needed for API key - it's in the URL)
-	if c.cred != nil {
-		token, err := c.cred.Token()
-		if err != nil {
-			return Decision{}, fmt.Errorf("vertex token: %w", err)
-		}
-		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
-	}
-
-	resp, err := c.client.Do(req)
+	resp, err := c.post(ctx, "generateContent", input)
 	if err != nil {
 		return Decision{}, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := readResponseBody(resp)
-		return Decision{}, fmt.Errorf("vertex gemini error: status %d: %s", resp.StatusCode, body)
-	}
-
 	var parsed vertexResponse
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
 		return Decision{}, err
@@ -217,23 +191,18 @@ func (c *Client) Decide(ctx context.Context, input Input) (Decision, error) {
 	toolCalls := make([]agentic.ToolCall, 0)
 	var reply strings.Builder
 	var reasoning strings.Builder
+	signature := ""
 	for i, part := range parts {
 		if part.FunctionCall != nil {
-			args, err := json.Marshal(part.FunctionCall.Args)
-			if err != nil {
-				args = []byte("{}")
-			}
 			// Capture signature directly from each part. Per Vertex AI docs:
 			// - Parallel calls: only first functionCall part has signature
 			// - Sequential calls: each step has its own signature
-			toolCalls = append(toolCalls, agentic.ToolCall{
-				ID:               fmt.Sprintf("call-%d", i),
-				Name:             part.FunctionCall.Name,
-				Input:            json.RawMessage(args),
-				ThoughtSignature: part.ThoughtSignature,
-			})
+			toolCalls = append(toolCalls, toolCallFromPart(i, part))
 			continue
 		}
+		if part.ThoughtSignature != "" {
+			signature = part.ThoughtSignature
+		}
 		if part.Thought != "" {
 			reasoning.WriteString(part.Thought)
 			continue
@@ -241,24 +210,137 @@ func (c *Client) Decide(ctx context.Context, input Input) (Decision, error) {
 		reply.WriteString(part.Text)
 	}
 
+	reasoningText := strings.TrimSpace(reasoning.String())
+	if reasoningText == "" {
+		reasoningText = strings.TrimSpace(parsed.Candidates[0].Thoughts)
+	}
+	decision := buildDecision(reply.String(), reasoningText, toolCalls, parsed.Candidates[0].FinishReason, parsed.UsageMetadata)
+	decision.Model = c.modelName(parsed.ModelVersion)
+	decision.Blocks = thoughtBlocks(reasoningText, signature)
+	return decision, nil
+}
+
+// modelName prefers the model version reported by the API.
+func (c *Client) modelName(version string) string {
+	if version != "" {
+		return version
+	}
+	return c.model
+}
+
+// buildDecision assembles a Decision shared by Decide and CollectDecision.
+func buildDecision(reply, reasoning string, toolCalls []agentic.ToolCall, finishReason string, meta *vertexUsageMetadata) Decision {
+	decision := Decision{
+		Reasoning:    reasoning,
+		FinishReason: finishReason,
+		StopReason:   stopReasonFor(finishReason, len(toolCalls) > 0),
+	}
+	if meta != nil {
+		u := meta.normalized()
+		decision.Usage = &u
+	}
 	if len(toolCalls) > 0 {
-		return Decision{ToolCalls: toolCalls, Reasoning: strings.TrimSpace(reasoning.String()), FinishReason: parsed.Candidates[0].FinishReason}, nil
+		decision.ToolCalls = toolCalls
+		return decision
 	}
 
-	responseText := strings.TrimSpace(reply.String())
-	if parsed.Candidates[0].FinishReason == "MAX_TOKENS" {
+	responseText := strings.TrimSpace(reply)
+	if finishReason == "MAX_TOKENS" {
 		responseText += "\n\n(Reply may be truncated. Consider increasing VERTEX_MAX_TOKENS.)"
 	}
-	candidateThoughts := strings.TrimSpace(parsed.Candidates[0].Thoughts)
-	reasoningText := strings.TrimSpace(reasoning.String())
-	if reasoningText == "" {
-		reasoningText = candidateThoughts
+	decision.Reply = responseText
+	return decision
+}
+
+// stopReasonFor normalizes a Gemini finish reason. Gemini reports STOP for
+// function-call turns, so any tool calls map to usage.StopReasonTool.
+func stopReasonFor(finishReason string, hasToolCalls bool) usage.StopReason {
+	if hasToolCalls {
+		return usage.StopReasonTool
 	}
-	return Decision{
-		Reply:        responseText,
-		Reasoning:    reasoningText,
-		FinishReason: parsed.Candidates[0].FinishReason,
-	}, nil
+	if reason := capabilities.StopReasonFromFinish(finishReason); reason != "" {
+		return reason
+	}
+	return usage.StopReasonStop
+}
+
+func toolCallFromPart(index int, part vertexPart) agentic.ToolCall {
+	args, err := json.Marshal(part.FunctionCall.Args)
+	if err != nil {
+		args = []byte("{}")
+	}
+	return agentic.ToolCall{
+		ID:               fmt.Sprintf("call-%d", index),
+		Name:             part.FunctionCall.Name,
+		Input:            json.RawMessage(args),
+		ThoughtSignature: part.ThoughtSignature,
+	}
+}
+
+// post sends a request to the given model method (generateContent, streamGenerateContent).
+// Non-2xx responses are returned as errors; callers own closing the body on success.
+func (c *Client) post(ctx context.Context, method string, input Input) (*http.Response, error) {
+	if c.cred == nil && c.apiKey == "" {
+		return nil, errors.New("no auth configured (need token source or API key)")
+	}
+	reqBody, err := c.buildRequest(input)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(method), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Set auth header for OAuth2 (not needed for API key - it's in the URL)
+	if c.cred != nil {
+		token, err := c.cred.Token()
+		if err != nil {
+			return nil, fmt.Errorf("vertex token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	}
+
+	resp, err := c.httpClient(method).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, _ := readResponseBody(resp)
+		return nil, retry.NewHTTPError(resp.StatusCode, resp.Header, fmt.Errorf("vertex gemini error: status %d: %s", resp.StatusCode, body))
+	}
+	return resp, nil
+}
+
+// httpClient returns the client for method. http.Client.Timeout covers reading
+// the whole body, so streams use a copy without it and rely on ctx instead.
+func (c *Client) httpClient(method string) *http.Client {
+	if method != "streamGenerateContent" || c.client.Timeout == 0 {
+		return c.client
+	}
+	client := *c.client
+	client.Timeout = 0
+	return &client
+}
+
+func (c *Client) endpoint(method string) string {
+	query := ""
+	if method == "streamGenerateContent" {
+		query = "?alt=sse"
+	}
+	if c.apiKey != "" {
+		// API key auth uses the publishers endpoint
+		sep := "?"
+		if query != "" {
+			sep = "&"
+		}
+		return fmt.Sprintf("%s/publishers/google/models/%s:%s%s%skey=%s", c.baseURL, c.model, method, query, sep, c.apiKey)
+	}
+	// OAuth2 auth uses the project/location endpoint
+	return fmt.Sprintf("%s/projects/%s/locations/%s/publishers/google/models/%s:%s%s", c.baseURL, c.project, c.location, c.model, method, query)
 }
 
 func readResponseBody(resp *http.Response) (string, error) {
@@ -313,6 +395,12 @@ func (c *Client) buildRequest(input Input) ([]byte, error) {
 			MaxOutputTokens: c.maxTokens,
 		},
 	}
+	if input.Reasoning != nil {
+		request.GenerationConfig.ThinkingConfig = &vertexThinkingConfig{
+			ThinkingBudget:  input.Reasoning.Budget(),
+			IncludeThoughts: input.Reasoning.Budget() > 0,
+		}
+	}
 	if strings.TrimSpace(input.SystemPrompt) != "" {
 		request.SystemInstruction = vertexSystemInstruction{
 			Parts: []vertexPart{{Text: input.SystemPrompt}},
@@ -334,22 +422,26 @@ func appendHistory(contents []vertexContent, history []message.AgentMessage) []v
 	for _, msg := range history {
 		switch msg.Role {
 		case message.RoleUser:
+			var parts []vertexPart
 			if strings.TrimSpace(msg.Content) != "" {
+				parts = append(parts, vertexPart{Text: msg.Content})
+			}
+			parts = append(parts, contentParts(msg.Parts)...)
+			if len(parts) > 0 {
 				contents = append(contents, vertexContent{
-					Role: "user",
-					Parts: []vertexPart{{
-						Text: msg.Content,
-					}},
+					Role:  "user",
+					Parts: parts,
 				})
 			}
 
 		case message.RoleAssistant:
-			// Handle assistant text replies
+			// Handle assistant text replies, replaying their thought signature
 			if strings.TrimSpace(msg.Content) != "" {
 				contents = append(contents, vertexContent{
 					Role: "model",
 					Parts: []vertexPart{{
-						Text: msg.Content,
+						Text:             msg.Content,
+						ThoughtSignature: replaySignature(msg),
 					}},
 				})
 			}
@@ -370,17 +462,18 @@ func appendHistory(contents []vertexContent, history []message.AgentMessage) []v
 			}
 
 		case message.RoleTool:
-			// Tool results become function responses
+			// Tool results become function responses, followed by their content parts
 			for _, result := range msg.ToolResults {
 				response := singleToolResultPayload(result)
+				parts := []vertexPart{{
+					FunctionResponse: &vertexFunctionResponse{
+						Name:     result.Name,
+						Response: response,
+					},
+				}}
 				contents = append(contents, vertexContent{
-					Role: "user",
-					Parts: []vertexPart{{
-						FunctionResponse: &vertexFunctionResponse{
-							Name:     result.Name,
-							Response: response,
-						},
-					}},
+					Role:  "user",
+					Parts: append(parts, contentParts(result.Parts)...),
 				})
 			}
 
@@ -462,6 +555,8 @@ type vertexPart struct {
 	Thought          string                  `json:"thought,omitempty"`
 	FunctionCall     *vertexFunctionCall     `json:"functionCall,omitempty"`
 	FunctionResponse *vertexFunctionResponse `json:"functionResponse,omitempty"`
+	InlineData       *vertexBlob             `json:"inlineData,omitempty"`
+	FileData         *vertexFileData         `json:"fileData,omitempty"`
 	ThoughtSignature string                  `json:"thoughtSignature,omitempty"`
 }
 
@@ -473,11 +568,27 @@ func (p *vertexPart) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return err
 	}
-	var base alias
+	// Gemini marks thought parts with "thought": true and carries the text in
+	// "text"; older payloads put the thought text directly in "thought".
+	var base struct {
+		alias
+		Thought json.RawMessage `json:"thought,omitempty"`
+	}
 	if err := json.Unmarshal(data, &base); err != nil {
 		return err
 	}
-	*p = vertexPart(base)
+	*p = vertexPart(base.alias)
+	if len(base.Thought) > 0 {
+		var isThought bool
+		if err := json.Unmarshal(base.Thought, &isThought); err == nil {
+			if isThought {
+				p.Thought = p.Text
+				p.Text = ""
+			}
+		} else {
+			_ = json.Unmarshal(base.Thought, &p.Thought)
+		}
+	}
 	if p.ThoughtSignature == "" {
 		if v, ok := raw["thought_signature"]; ok {
 			_ = json.Unmarshal(v, &p.ThoughtSignature)
@@ -496,6 +607,16 @@ type vertexFunctionResponse struct {
 	Response map[string]any `json:"response"`
 }
 
+type vertexBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     []byte `json:"data"`
+}
+
+type vertexFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
 type vertexGoogleSearch struct{}
 
 type vertexTool struct {
@@ -512,10 +633,33 @@ type vertexFunctionDeclaration struct {
 type vertexGenerationConfig struct {
 	Temperature     float64 `json:"temperature,omitempty"`
 	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+	// ThinkingConfig is omitted unless reasoning is requested.
+	ThinkingConfig *vertexThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// vertexThinkingConfig sends a zero budget so reasoning can be turned off.
+type vertexThinkingConfig struct {
+	ThinkingBudget  int  `json:"thinkingBudget"`
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
 }
 
 type vertexResponse struct {
-	Candidates []vertexCandidate `json:"candidates"`
+	Candidates    []vertexCandidate    `json:"candidates"`
+	UsageMetadata *vertexUsageMetadata `json:"usageMetadata,omitempty"`
+	ModelVersion  string               `json:"modelVersion,omitempty"`
+}
+
+type vertexUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+// normalized maps Gemini token counts to usage.Usage. Thinking tokens are
+// billed as output, so they are folded into Output.
+func (m vertexUsageMetadata) normalized() usage.Usage {
+	return capabilities.NormalizeUsage(m.PromptTokenCount, m.CandidatesTokenCount+m.ThoughtsTokenCount, m.TotalTokenCount)
 }
 
 type vertexCandidate struct {
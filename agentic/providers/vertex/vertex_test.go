@@ -7,9 +7,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/victorarias/agentic-weave/agentic"
 	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/usage"
+	"github.com/victorarias/agentic-weave/capabilities"
 	"golang.org/x/oauth2"
 )
 
@@ -404,6 +407,47 @@ func TestAppendHistoryWithAgentMessage(t *testing.T) {
 	}
 }
 
+func TestAppendHistoryMapsContentParts(t *testing.T) {
+	history := []message.AgentMessage{
+		{Role: message.RoleUser, Content: "describe", Parts: []agentic.ContentPart{
+			agentic.ImagePart("image/png", []byte("png-bytes")),
+			agentic.DocumentURLPart("application/pdf", "gs://bucket/doc.pdf"),
+		}},
+		{Role: message.RoleTool, ToolResults: []agentic.ToolResult{{
+			ID:    "tc1",
+			Name:  "read",
+			Parts: []agentic.ContentPart{agentic.ImagePart("image/jpeg", []byte("jpeg-bytes"))},
+		}}},
+	}
+
+	contents := appendHistory(nil, history)
+	if len(contents) != 2 {
+		t.Fatalf("expected 2 contents, got %d", len(contents))
+	}
+	user := contents[0].Parts
+	if len(user) != 3 || user[0].Text != "describe" {
+		t.Fatalf("unexpected user parts: %+v", user)
+	}
+	if user[1].InlineData == nil || user[1].InlineData.MimeType != "image/png" || string(user[1].InlineData.Data) != "png-bytes" {
+		t.Fatalf("expected inline image, got %+v", user[1])
+	}
+	if user[2].FileData == nil || user[2].FileData.FileURI != "gs://bucket/doc.pdf" {
+		t.Fatalf("expected file data, got %+v", user[2])
+	}
+	tool := contents[1].Parts
+	if len(tool) != 2 || tool[0].FunctionResponse == nil || tool[1].InlineData == nil {
+		t.Fatalf("expected function response followed by inline image, got %+v", tool)
+	}
+
+	raw, err := json.Marshal(contents[0])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(raw), `"inlineData":{"mimeType":"image/png","data":"cG5nLWJ5dGVz"}`) {
+		t.Fatalf("unexpected inline data encoding: %s", raw)
+	}
+}
+
 func TestAppendHistoryPreservesAssistantTextWithToolCalls(t *testing.T) {
 	history := []message.AgentMessage{
 		{
@@ -745,3 +789,230 @@ func TestBuildRequestAddsUserMessageWhenProvided(t *testing.T) {
 			lastContent.Role, lastContent.Parts[0].Text)
 	}
 }
+
+func TestVertexPartUnmarshalThoughtFlag(t *testing.T) {
+	var part vertexPart
+	if err := json.Unmarshal([]byte(`{"text":"pondering","thought":true}`), &part); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if part.Thought != "pondering" || part.Text != "" {
+		t.Fatalf("expected thought text moved out of text, got %+v", part)
+	}
+}
+
+func TestDecidePopulatesUsageAndStopReason(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"candidates": [{
+				"content": {"role": "model", "parts": [{"text": "hello"}]},
+				"finishReason": "STOP"
+			}],
+			"usageMetadata": {"promptTokenCount": 12, "candidatesTokenCount": 3, "thoughtsTokenCount": 2, "totalTokenCount": 17}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		model:   "gemini-pro",
+		baseURL: server.URL,
+		client:  server.Client(),
+		apiKey:  "key",
+	}
+
+	decision, err := client.Decide(context.Background(), Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("Decide error: %v", err)
+	}
+	if decision.StopReason != usage.StopReasonStop {
+		t.Fatalf("expected stop reason, got %q", decision.StopReason)
+	}
+	if decision.Usage == nil || decision.Usage.Input != 12 || decision.Usage.Output != 5 || decision.Usage.Total != 17 {
+		t.Fatalf("unexpected usage: %+v", decision.Usage)
+	}
+}
+
+func TestStreamEmitsDeltasAndFunctionCalls(t *testing.T) {
+	chunks := []string{
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"let me think","thought":true}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"lo"}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"lookup","args":{"q":"x"}},"thoughtSignature":"sig-1"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":4,"candidatesTokenCount":6,"totalTokenCount":10}}`,
+	}
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path + "?" + r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			w.Write([]byte("data: " + chunk + "\r\n\r\n"))
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		project:  "test-project",
+		location: "us-central1",
+		model:    "gemini-pro",
+		baseURL:  server.URL,
+		client:   server.Client(),
+		cred:     &staticTokenSource{token: &oauth2.Token{AccessToken: "test-token"}},
+	}
+
+	events, err := client.Stream(context.Background(), Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("Stream error: %v", err)
+	}
+
+	var text, thoughts strings.Builder
+	var calls []agentic.ToolCall
+	var done *DoneEvent
+	for ev := range events {
+		switch e := ev.(type) {
+		case TextDeltaEvent:
+			text.WriteString(e.Delta)
+		case ThoughtDeltaEvent:
+			thoughts.WriteString(e.Delta)
+		case ToolUseEvent:
+			calls = append(calls, e.Call)
+		case DoneEvent:
+			done = &e
+		case ErrorEvent:
+			t.Fatalf("stream error: %v", e.Err)
+		}
+	}
+
+	if !strings.HasSuffix(path, ":streamGenerateContent?alt=sse") {
+		t.Fatalf("unexpected request path %q", path)
+	}
+	if text.String() != "Hello" || thoughts.String() != "let me think" {
+		t.Fatalf("unexpected deltas: text=%q thoughts=%q", text.String(), thoughts.String())
+	}
+	if len(calls) != 1 || calls[0].Name != "lookup" || calls[0].ThoughtSignature != "sig-1" {
+		t.Fatalf("unexpected calls: %+v", calls)
+	}
+	if done == nil || done.StopReason != usage.StopReasonTool || done.Usage == nil || done.Usage.Total != 10 {
+		t.Fatalf("unexpected done event: %+v", done)
+	}
+}
+
+func TestStreamOutlivesClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: " + `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}]}` + "\r\n\r\n"))
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("data: " + `{"candidates":[{"content":{"role":"model","parts":[{"text":"lo"}]},"finishReason":"STOP"}]}` + "\r\n\r\n"))
+	}))
+	defer server.Close()
+
+	httpClient := server.Client()
+	httpClient.Timeout = 50 * time.Millisecond
+	client := &Client{model: "gemini-pro", baseURL: server.URL, client: httpClient, apiKey: "key"}
+
+	events, err := client.Stream(context.Background(), Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("Stream error: %v", err)
+	}
+	decision, err := CollectDecision(events)
+	if err != nil {
+		t.Fatalf("expected the stream to outlive the client timeout, got %v", err)
+	}
+	if decision.Reply != "Hello" || httpClient.Timeout != 50*time.Millisecond {
+		t.Fatalf("unexpected reply %q or client timeout %s", decision.Reply, httpClient.Timeout)
+	}
+}
+
+func TestCollectDecisionFromStream(t *testing.T) {
+	events := make(chan StreamEvent, 4)
+	events <- ThoughtDeltaEvent{Delta: "hmm"}
+	events <- TextDeltaEvent{Delta: " answer "}
+	events <- DoneEvent{FinishReason: "STOP", StopReason: usage.StopReasonStop, Usage: &usage.Usage{Input: 1, Output: 1, Total: 2}}
+	close(events)
+
+	decision, err := CollectDecision(events)
+	if err != nil {
+		t.Fatalf("collect error: %v", err)
+	}
+	if decision.Reply != "answer" || decision.Reasoning != "hmm" || decision.Usage == nil {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+}
+
+func TestDecideStoresTextThoughtSignatureAndReplaysIt(t *testing.T) {
+	var second vertexRequest
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 2 {
+			_ = json.NewDecoder(r.Body).Decode(&second)
+		}
+		w.Write([]byte(`{
+			"candidates": [{
+				"content": {"role": "model", "parts": [
+					{"text": "weighing options", "thought": true},
+					{"text": "answer", "thoughtSignature": "sig-text"}
+				]},
+				"finishReason": "STOP"
+			}],
+			"modelVersion": "gemini-pro-002"
+		}`))
+	}))
+	defer server.Close()
+
+	client := &Client{model: "gemini-pro", baseURL: server.URL, client: server.Client(), apiKey: "key"}
+	decision, err := client.Decide(context.Background(), Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("Decide error: %v", err)
+	}
+	if decision.Model != "gemini-pro-002" || len(decision.Blocks) != 1 || decision.Blocks[0].Provider != ProviderName {
+		t.Fatalf("unexpected decision: %+v", decision)
+	}
+
+	history := []message.AgentMessage{
+		{Role: message.RoleUser, Content: "hi"},
+		{Role: message.RoleAssistant, Content: decision.Reply, ProviderBlocks: decision.Blocks},
+	}
+	if _, err := client.Decide(context.Background(), Input{History: history, UserMessage: "more"}); err != nil {
+		t.Fatalf("second Decide error: %v", err)
+	}
+	model := second.Contents[1]
+	if model.Role != "model" || model.Parts[0].Text != "answer" || model.Parts[0].ThoughtSignature != "sig-text" {
+		t.Fatalf("expected replayed signature, got %+v", model)
+	}
+}
+
+func TestBuildRequestIncludesThinkingConfig(t *testing.T) {
+	client := &Client{model: "gemini-pro", maxTokens: 1024}
+
+	body, err := client.buildRequest(Input{UserMessage: "hi", Reasoning: &capabilities.Reasoning{BudgetTokens: 512}})
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	if !strings.Contains(string(body), `"thinkingConfig":{"thinkingBudget":512,"includeThoughts":true}`) {
+		t.Fatalf("expected thinking config, got %s", body)
+	}
+
+	body, err = client.buildRequest(Input{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	if strings.Contains(string(body), "thinkingConfig") {
+		t.Fatalf("expected no thinking config without reasoning, got %s", body)
+	}
+}
+
+func TestCollectDecisionForwardsThoughtDeltas(t *testing.T) {
+	ch := make(chan StreamEvent, 3)
+	ch <- ThoughtDeltaEvent{Delta: "pondering"}
+	ch <- TextDeltaEvent{Delta: "answer"}
+	ch <- DoneEvent{FinishReason: "STOP"}
+	close(ch)
+
+	var thoughts []string
+	decision, err := collectDecision(ch, nil, func(delta string) { thoughts = append(thoughts, delta) })
+	if err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if len(thoughts) != 1 || thoughts[0] != "pondering" || decision.Reasoning != "pondering" {
+		t.Fatalf("unexpected thoughts %v / decision %#v", thoughts, decision)
+	}
+}
@@ -2,16 +2,22 @@ package agentic
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
+
+	"github.com/victorarias/agentic-weave/agentic/schema"
 )
 
 // Registry stores tool implementations and executes calls.
 type Registry struct {
-	mu     sync.RWMutex
-	tools  map[string]Tool
-	policy Policy
+	mu       sync.RWMutex
+	tools    map[string]Tool
+	policy   Policy
+	approver Approver
+	validate bool
+	onAdd    []func(ToolDefinition)
 }
 
 // RegistryOption configures a Registry.
@@ -26,6 +32,21 @@ func WithPolicy(policy Policy) RegistryOption {
 	}
 }
 
+// WithApprover sets the approver consulted when the policy returns ErrApprovalRequired.
+func WithApprover(approver Approver) RegistryOption {
+	return func(r *Registry) {
+		r.approver = approver
+	}
+}
+
+// WithSchemaValidation validates call inputs against each tool's InputSchema
+// before execution.
+func WithSchemaValidation() RegistryOption {
+	return func(r *Registry) {
+		r.validate = true
+	}
+}
+
 // NewRegistry creates an empty registry with optional policy.
 func NewRegistry(opts ...RegistryOption) *Registry {
 	r := &Registry{
@@ -50,14 +71,35 @@ func (r *Registry) Register(tools ...Tool) error {
 		}
 	}
 	r.mu.Lock()
-	defer r.mu.Unlock()
 	for _, tool := range tools {
 		def := tool.Definition()
 		r.tools[def.Name] = tool
 	}
+	listeners := append([]func(ToolDefinition){}, r.onAdd...)
+	r.mu.Unlock()
+	for _, tool := range tools {
+		def := tool.Definition()
+		if !r.listed(def) {
+			continue
+		}
+		for _, fn := range listeners {
+			fn(def)
+		}
+	}
 	return nil
 }
 
+// OnRegister calls fn with each tool definition registered from now on that
+// ListTools would return.
+func (r *Registry) OnRegister(fn func(ToolDefinition)) {
+	if fn == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onAdd = append(r.onAdd, fn)
+}
+
 // ListTools returns tool definitions in stable order.
 func (r *Registry) ListTools(ctx context.Context) ([]ToolDefinition, error) {
 	r.mu.RLock()
@@ -66,7 +108,7 @@ func (r *Registry) ListTools(ctx context.Context) ([]ToolDefinition, error) {
 	defs := make([]ToolDefinition, 0, len(r.tools))
 	for _, tool := range r.tools {
 		def := tool.Definition()
-		if err := r.policy.AllowTool(def); err != nil {
+		if !r.listed(def) {
 			continue
 		}
 		defs = append(defs, def)
@@ -84,6 +126,11 @@ func (r *Registry) ListTools(ctx context.Context) ([]ToolDefinition, error) {
 	return defs, nil
 }
 
+// listed reports whether the policy lets def be listed.
+func (r *Registry) listed(def ToolDefinition) bool {
+	return r.policy.AllowTool(def) == nil
+}
+
 // Execute routes a tool call to the registered tool.
 func (r *Registry) Execute(ctx context.Context, call ToolCall) (ToolResult, error) {
 	r.mu.RLock()
@@ -94,8 +141,12 @@ func (r *Registry) Execute(ctx context.Context, call ToolCall) (ToolResult, erro
 	}
 
 	def := tool.Definition()
-	if err := r.policy.AllowCall(def, call); err != nil {
-		return ToolResult{}, err
+	var approvalErr error
+	if err := allowCall(ctx, r.policy, def, call); err != nil {
+		if !errors.Is(err, ErrApprovalRequired) {
+			return ToolResult{}, err
+		}
+		approvalErr = err
 	}
 	if err := validateCaller(def, call); err != nil {
 		return ToolResult{}, err
@@ -103,6 +154,18 @@ func (r *Registry) Execute(ctx context.Context, call ToolCall) (ToolResult, erro
 	if def.SchemaHash != "" && call.SchemaHash != "" && def.SchemaHash != call.SchemaHash {
 		return ToolResult{}, ErrSchemaMismatch
 	}
+	if r.validate {
+		if err := schema.Validate(def.InputSchema, call.Input); err != nil {
+			return invalidInputResult(call, err)
+		}
+	}
+	// Ask only for calls that would run, so rejected calls never prompt or
+	// record an "allow always".
+	if approvalErr != nil {
+		if err := r.requestApproval(ctx, def, call, approvalErr); err != nil {
+			return ToolResult{}, err
+		}
+	}
 
 	select {
 	case <-ctx.Done():
@@ -117,6 +180,45 @@ func (r *Registry) Execute(ctx context.Context, call ToolCall) (ToolResult, erro
 	return result, nil
 }
 
+// invalidInputResult reports validation failures both as a structured result
+// and as an error wrapping ErrInvalidInput.
+func invalidInputResult(call ToolCall, err error) (ToolResult, error) {
+	var validationErr *schema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return ToolResult{}, err
+	}
+	message := fmt.Sprintf("%s: %s", ErrInvalidInput.Error(), validationErr.Error())
+	result := ToolResult{
+		ID:    call.ID,
+		Name:  call.Name,
+		Error: &ToolError{Message: message, Code: "invalid_input"},
+	}
+	return result, fmt.Errorf("%w: %w", ErrInvalidInput, validationErr)
+}
+
+// requestApproval pauses a call until the approver decides. Missing approvers
+// deny. cause is the policy error that asked for approval.
+func (r *Registry) requestApproval(ctx context.Context, def ToolDefinition, call ToolCall, cause error) error {
+	if r.approver == nil {
+		return fmt.Errorf("%w: %s (no approver configured)", ErrApprovalDenied, def.Name)
+	}
+	req := ApprovalRequest{Tool: def, Call: call}
+	var ruler ApprovalRuler
+	if errors.As(cause, &ruler) {
+		req.Rule, req.EveryTime = ruler.ApprovalRule()
+	}
+	decision, err := r.approver.RequestApproval(ctx, req)
+	if err != nil {
+		return err
+	}
+	switch decision {
+	case ApprovalAllowOnce, ApprovalAllowAlways:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrApprovalDenied, def.Name)
+	}
+}
+
 func validateCaller(def ToolDefinition, call ToolCall) error {
 	if len(def.AllowedCallers) == 0 {
 		return nil
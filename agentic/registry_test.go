@@ -3,6 +3,8 @@ package agentic
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -70,3 +72,37 @@ func TestRegistryRegisterValidation(t *testing.T) {
 		t.Fatalf("expected error for empty tool name")
 	}
 }
+
+func TestRegistrySchemaValidation(t *testing.T) {
+	def := ToolDefinition{
+		Name:        "echo",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]}`),
+	}
+	reg := NewRegistry(WithSchemaValidation())
+	if err := reg.Register(echoTool{def: def}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	result, err := reg.Execute(context.Background(), ToolCall{ID: "1", Name: "echo", Input: json.RawMessage(`{"text":1}`)})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("expected invalid input error, got %v", err)
+	}
+	if result.Error == nil || result.Error.Code != "invalid_input" || !strings.Contains(result.Error.Message, "$.text: expected string, got number") {
+		t.Fatalf("unexpected tool error: %#v", result.Error)
+	}
+	if result.ID != "1" || result.Name != "echo" {
+		t.Fatalf("expected result to carry call identity, got %#v", result)
+	}
+
+	if _, err := reg.Execute(context.Background(), ToolCall{Name: "echo", Input: json.RawMessage(`{"text":"ok"}`)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unvalidated := NewRegistry()
+	if err := unvalidated.Register(echoTool{def: def}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := unvalidated.Execute(context.Background(), ToolCall{Name: "echo", Input: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("expected validation to be opt-in, got %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+// Package retry provides a loop.Decider wrapper that retries transient
+// provider failures with backoff and fails over to alternate deciders.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/victorarias/agentic-weave/agentic/events"
+	"github.com/victorarias/agentic-weave/agentic/loop"
+)
+
+// Class describes how the Decider reacts to an error.
+type Class int
+
+const (
+	// Fatal errors are returned immediately (bad requests, cancellation).
+	Fatal Class = iota
+	// Retryable errors are retried on the same decider with backoff.
+	Retryable
+	// Failover errors skip straight to the next decider without retrying.
+	Failover
+)
+
+// HTTPError is returned by provider clients for non-2xx responses.
+// RetryAfter is parsed from the Retry-After header when present.
+type HTTPError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("http status %d", e.StatusCode)
+	}
+	return e.Err.Error()
+}
+
+func (e *HTTPError) Unwrap() error { return e.Err }
+
+// NewHTTPError builds an HTTPError from a response status and headers.
+func NewHTTPError(statusCode int, header http.Header, err error) *HTTPError {
+	out := &HTTPError{StatusCode: statusCode, Err: err}
+	if header != nil {
+		out.RetryAfter = ParseRetryAfter(header.Get("Retry-After"), time.Now())
+	}
+	return out
+}
+
+// ParseRetryAfter parses a Retry-After value in delay-seconds or HTTP-date form.
+// It returns 0 when the value is empty, invalid or already in the past.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		return time.Duration(secs * float64(time.Second))
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Classify is the default error classifier.
+//
+//   - 408, 409, 425, 429, 5xx and 529 (overloaded) responses are Retryable
+//   - 401, 403 and 404 responses are Failover (another provider may work)
+//   - other HTTP statuses are Fatal
+//   - timeouts (including http.Client.Timeout), temporary DNS failures,
+//     refused or reset connections and unexpected EOFs are Retryable
+//   - context cancellation, TLS, DNS-not-found, invalid URL and unknown
+//     errors are Fatal
+//
+// Decider checks the caller's context before classifying, so a deadline seen
+// here belongs to the request, not the caller.
+func Classify(err error) Class {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return Fatal
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Retryable
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch code := httpErr.StatusCode; {
+		case code == http.StatusRequestTimeout, code == http.StatusConflict, code == http.StatusTooEarly,
+			code == http.StatusTooManyRequests, code >= 500:
+			return Retryable
+		case code == http.StatusUnauthorized, code == http.StatusForbidden, code == http.StatusNotFound:
+			return Failover
+		default:
+			return Fatal
+		}
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return Retryable
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout || dnsErr.IsTemporary {
+			return Retryable
+		}
+		return Fatal
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Retryable
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return Retryable
+	}
+	// The server closed the connection before responding.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && errors.Is(urlErr.Err, io.EOF) {
+		return Retryable
+	}
+	return Fatal
+}
+
+// Config controls retry and failover behavior.
+type Config struct {
+	MaxAttempts int           // attempts per decider (default 3)
+	BaseDelay   time.Duration // first backoff delay (default 500ms)
+	MaxDelay    time.Duration // backoff cap (default 30s); a longer Retry-After fails over
+	Jitter      float64       // +/- fraction applied to each delay (default 0.2, negative disables)
+	Classifier  func(error) Class
+	Events      events.Sink
+
+	// Sleep waits between attempts; tests can replace it. Defaults to a
+	// context-aware timer.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Decider wraps an ordered list of deciders. Each decider is retried on
+// Retryable errors before the next one is tried.
+type Decider struct {
+	deciders []loop.Decider
+	cfg      Config
+	rand     func() float64
+}
+
+// NewDecider wraps primary with retries and an optional failover list.
+func NewDecider(cfg Config, primary loop.Decider, fallbacks ...loop.Decider) *Decider {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = 500 * time.Millisecond
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = 30 * time.Second
+	}
+	if cfg.Jitter == 0 {
+		cfg.Jitter = 0.2
+	}
+	if cfg.Classifier == nil {
+		cfg.Classifier = Classify
+	}
+	if cfg.Sleep == nil {
+		cfg.Sleep = sleep
+	}
+
+	deciders := make([]loop.Decider, 0, 1+len(fallbacks))
+	for _, d := range append([]loop.Decider{primary}, fallbacks...) {
+		if d != nil {
+			deciders = append(deciders, d)
+		}
+	}
+	return &Decider{deciders: deciders, cfg: cfg, rand: rand.Float64}
+}
+
+// Decide implements loop.Decider.
+func (d *Decider) Decide(ctx context.Context, in loop.Input) (loop.Decision, error) {
+	return d.run(ctx, func(target loop.Decider) (loop.Decision, error) {
+		return target.Decide(ctx, in)
+	})
+}
+
+// DecideStream implements loop.StreamingDecider. Deciders that do not stream
+// fall back to Decide. A retry after partial output re-streams from the start,
+// so consumers should discard partial text on DeciderRetry/DeciderFailover events.
+func (d *Decider) DecideStream(ctx context.Context, in loop.Input, onDelta func(loop.StreamDelta)) (loop.Decision, error) {
+	return d.run(ctx, func(target loop.Decider) (loop.Decision, error) {
+		if streamer, ok := target.(loop.StreamingDecider); ok {
+			return streamer.DecideStream(ctx, in, onDelta)
+		}
+		return target.Decide(ctx, in)
+	})
+}
+
+func (d *Decider) run(ctx context.Context, call func(loop.Decider) (loop.Decision, error)) (loop.Decision, error) {
+	if len(d.deciders) == 0 {
+		return loop.Decision{}, errors.New("retry: no deciders configured")
+	}
+
+	var lastErr error
+	for idx, target := range d.deciders {
+		if idx > 0 {
+			d.emit(events.Event{
+				Type:    events.DeciderFailover,
+				Content: fmt.Sprintf("failing over to decider %d: %v", idx, lastErr),
+			})
+		}
+		for attempt := 1; ; attempt++ {
+			decision, err := call(target)
+			if err == nil {
+				return decision, nil
+			}
+			lastErr = err
+			if ctx.Err() != nil {
+				return loop.Decision{}, err
+			}
+
+			class := d.cfg.Classifier(err)
+			if class == Fatal {
+				return loop.Decision{}, err
+			}
+			if class == Failover || attempt >= d.cfg.MaxAttempts {
+				break
+			}
+
+			delay, ok := d.delay(attempt, err)
+			if !ok {
+				// The server asked for a longer wait than MaxDelay.
+				break
+			}
+			d.emit(events.Event{
+				Type:    events.DeciderRetry,
+				Content: fmt.Sprintf("decider %d attempt %d/%d failed, retrying in %s: %v", idx, attempt, d.cfg.MaxAttempts, delay.Round(time.Millisecond), err),
+			})
+			if err := d.cfg.Sleep(ctx, delay); err != nil {
+				return loop.Decision{}, err
+			}
+		}
+	}
+	return loop.Decision{}, lastErr
+}
+
+// delay computes the wait before the next attempt. Retry-After wins when set;
+// it reports false when Retry-After exceeds MaxDelay.
+func (d *Decider) delay(attempt int, err error) (time.Duration, bool) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		return httpErr.RetryAfter, httpErr.RetryAfter <= d.cfg.MaxDelay
+	}
+
+	delay := d.cfg.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > d.cfg.MaxDelay {
+		delay = d.cfg.MaxDelay
+	}
+	if d.cfg.Jitter > 0 {
+		factor := 1 - d.cfg.Jitter + 2*d.cfg.Jitter*d.rand()
+		delay = time.Duration(float64(delay) * factor)
+	}
+	return delay, true
+}
+
+func (d *Decider) emit(e events.Event) {
+	if d.cfg.Events != nil {
+		d.cfg.Events.Emit(e)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,261 @@
+package retry
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/victorarias/agentic-weave/agentic/events"
+	"github.com/victorarias/agentic-weave/agentic/loop"
+)
+
+type scriptedDecider struct {
+	errs  []error
+	reply string
+	calls int
+}
+
+func (d *scriptedDecider) Decide(ctx context.Context, in loop.Input) (loop.Decision, error) {
+	d.calls++
+	if d.calls <= len(d.errs) {
+		return loop.Decision{}, d.errs[d.calls-1]
+	}
+	return loop.Decision{Reply: d.reply}, nil
+}
+
+type sleepRecorder struct {
+	delays []time.Duration
+}
+
+func (s *sleepRecorder) Sleep(ctx context.Context, d time.Duration) error {
+	s.delays = append(s.delays, d)
+	return nil
+}
+
+func TestDecideRetriesWithBackoffAndRetryAfter(t *testing.T) {
+	primary := &scriptedDecider{
+		errs: []error{
+			&HTTPError{StatusCode: http.StatusServiceUnavailable},
+			&HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 7 * time.Second},
+		},
+		reply: "ok",
+	}
+	sleeper := &sleepRecorder{}
+	var seen []string
+	d := NewDecider(Config{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		Jitter:      -1,
+		Sleep:       sleeper.Sleep,
+		Events:      events.SinkFunc(func(e events.Event) { seen = append(seen, e.Type) }),
+	}, primary)
+
+	decision, err := d.Decide(context.Background(), loop.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decision.Reply != "ok" || primary.calls != 3 {
+		t.Fatalf("expected success on third call, got %q after %d calls", decision.Reply, primary.calls)
+	}
+	if len(sleeper.delays) != 2 || sleeper.delays[0] != time.Second || sleeper.delays[1] != 7*time.Second {
+		t.Fatalf("unexpected delays: %v", sleeper.delays)
+	}
+	if len(seen) != 2 || seen[0] != events.DeciderRetry || seen[1] != events.DeciderRetry {
+		t.Fatalf("expected two retry events, got %v", seen)
+	}
+}
+
+func TestDecideFailsFastOnLongRetryAfter(t *testing.T) {
+	slow := &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+	primary := &scriptedDecider{errs: []error{slow}}
+	secondary := &scriptedDecider{reply: "from secondary"}
+	sleeper := &sleepRecorder{}
+	d := NewDecider(Config{MaxDelay: time.Minute, Sleep: sleeper.Sleep}, primary, secondary)
+
+	decision, err := d.Decide(context.Background(), loop.Input{})
+	if err != nil || decision.Reply != "from secondary" {
+		t.Fatalf("expected failover, got %q %v", decision.Reply, err)
+	}
+	if primary.calls != 1 || len(sleeper.delays) != 0 {
+		t.Fatalf("expected no wait for a Retry-After over MaxDelay, got %d calls and delays %v", primary.calls, sleeper.delays)
+	}
+
+	alone := NewDecider(Config{MaxDelay: time.Minute, Sleep: sleeper.Sleep}, &scriptedDecider{errs: []error{slow}})
+	if _, err := alone.Decide(context.Background(), loop.Input{}); !errors.Is(err, slow) {
+		t.Fatalf("expected the rate limit error, got %v", err)
+	}
+}
+
+func TestDecideFailsOverAfterExhaustingRetries(t *testing.T) {
+	overloaded := &HTTPError{StatusCode: 529}
+	primary := &scriptedDecider{errs: []error{overloaded, overloaded}}
+	secondary := &scriptedDecider{reply: "from secondary"}
+	var seen []string
+	d := NewDecider(Config{
+		MaxAttempts: 2,
+		Sleep:       (&sleepRecorder{}).Sleep,
+		Events:      events.SinkFunc(func(e events.Event) { seen = append(seen, e.Type) }),
+	}, primary, secondary)
+
+	decision, err := d.Decide(context.Background(), loop.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decision.Reply != "from secondary" || primary.calls != 2 || secondary.calls != 1 {
+		t.Fatalf("unexpected calls primary=%d secondary=%d reply=%q", primary.calls, secondary.calls, decision.Reply)
+	}
+	if len(seen) != 2 || seen[0] != events.DeciderRetry || seen[1] != events.DeciderFailover {
+		t.Fatalf("unexpected events: %v", seen)
+	}
+}
+
+func TestDecideFailoverClassSkipsRetries(t *testing.T) {
+	primary := &scriptedDecider{errs: []error{&HTTPError{StatusCode: http.StatusUnauthorized}}}
+	secondary := &scriptedDecider{reply: "ok"}
+	d := NewDecider(Config{Sleep: (&sleepRecorder{}).Sleep}, primary, secondary)
+
+	if _, err := d.Decide(context.Background(), loop.Input{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if primary.calls != 1 {
+		t.Fatalf("expected no retries on auth failure, got %d calls", primary.calls)
+	}
+}
+
+func TestDecideReturnsFatalErrorImmediately(t *testing.T) {
+	bad := &HTTPError{StatusCode: http.StatusBadRequest, Err: errors.New("bad request")}
+	primary := &scriptedDecider{errs: []error{bad}}
+	secondary := &scriptedDecider{reply: "unused"}
+	d := NewDecider(Config{Sleep: (&sleepRecorder{}).Sleep}, primary, secondary)
+
+	_, err := d.Decide(context.Background(), loop.Input{})
+	if !errors.Is(err, bad) {
+		t.Fatalf("expected fatal error, got %v", err)
+	}
+	if primary.calls != 1 || secondary.calls != 0 {
+		t.Fatalf("unexpected calls primary=%d secondary=%d", primary.calls, secondary.calls)
+	}
+}
+
+func TestDecideStopsWhenContextCanceledDuringBackoff(t *testing.T) {
+	primary := &scriptedDecider{errs: []error{&HTTPError{StatusCode: 500}, &HTTPError{StatusCode: 500}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := NewDecider(Config{Sleep: func(ctx context.Context, _ time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}}, primary)
+
+	_, err := d.Decide(ctx, loop.Input{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+}
+
+type httpDecider struct {
+	client *http.Client
+	url    string
+	calls  int
+}
+
+func (d *httpDecider) Decide(ctx context.Context, in loop.Input) (loop.Decision, error) {
+	d.calls++
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return loop.Decision{}, err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return loop.Decision{}, err
+	}
+	resp.Body.Close()
+	return loop.Decision{Reply: "ok"}, nil
+}
+
+func TestDecideRetriesClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	primary := &httpDecider{client: &http.Client{Timeout: 20 * time.Millisecond}, url: server.URL}
+	d := NewDecider(Config{MaxAttempts: 2, Sleep: func(context.Context, time.Duration) error { return nil }}, primary)
+
+	_, err := d.Decide(context.Background(), loop.Input{})
+	if err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected client timeout, got %v", err)
+	}
+	if primary.calls != 2 {
+		t.Fatalf("expected the timeout to be retried, got %d calls", primary.calls)
+	}
+}
+
+func TestDecideDoesNotRetryCallerDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	primary := &scriptedDecider{errs: []error{ctx.Err(), ctx.Err()}}
+	d := NewDecider(Config{Sleep: func(context.Context, time.Duration) error { return nil }}, primary)
+
+	if _, err := d.Decide(ctx, loop.Input{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected caller deadline, got %v", err)
+	}
+	if primary.calls != 1 {
+		t.Fatalf("expected no retry after the caller deadline, got %d calls", primary.calls)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		err  error
+		want Class
+	}{
+		{&HTTPError{StatusCode: 429}, Retryable},
+		{&HTTPError{StatusCode: 503}, Retryable},
+		{fmt.Errorf("wrapped: %w", &HTTPError{StatusCode: 529}), Retryable},
+		{&HTTPError{StatusCode: 403}, Failover},
+		{&HTTPError{StatusCode: 400}, Fatal},
+		{&url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, Retryable},
+		{&url.Error{Op: "Post", URL: "http://x", Err: io.EOF}, Retryable},
+		{&url.Error{Op: "Post", URL: "http://x", Err: &net.DNSError{Err: "no such host", Name: "x", IsNotFound: true}}, Fatal},
+		{&url.Error{Op: "Post", URL: "http://x", Err: &net.DNSError{Err: "server misbehaving", Name: "x", IsTemporary: true}}, Retryable},
+		{&url.Error{Op: "Post", URL: "https://x", Err: x509.UnknownAuthorityError{}}, Fatal},
+		{&url.Error{Op: "Post", URL: "ftp://x", Err: errors.New("unsupported protocol scheme \"ftp\"")}, Fatal},
+		{io.ErrUnexpectedEOF, Retryable},
+		{context.Canceled, Fatal},
+		{fmt.Errorf("request: %w", context.DeadlineExceeded), Retryable},
+		{errors.New("unknown"), Fatal},
+	}
+	for _, tc := range cases {
+		if got := Classify(tc.err); got != tc.want {
+			t.Fatalf("Classify(%v)=%v want %v", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := ParseRetryAfter("3", now); got != 3*time.Second {
+		t.Fatalf("expected 3s, got %v", got)
+	}
+	if got := ParseRetryAfter(now.Add(10*time.Second).Format(http.TimeFormat), now); got != 10*time.Second {
+		t.Fatalf("expected 10s, got %v", got)
+	}
+	if got := ParseRetryAfter("soon", now); got != 0 {
+		t.Fatalf("expected 0 for invalid value, got %v", got)
+	}
+}
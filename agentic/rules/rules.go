@@ -0,0 +1,368 @@
+// Package rules provides a declarative, rule-based agentic.Policy.
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/toolscope"
+)
+
+// Effect is the outcome of a matching rule.
+type Effect string
+
+const (
+	Allow Effect = "allow"
+	Deny  Effect = "deny"
+	Ask   Effect = "ask"
+)
+
+// RuleSet is the file format for rules. Rules are evaluated in order and the
+// first match decides; Default applies when nothing matches (allow if empty).
+type RuleSet struct {
+	Default Effect `json:"default,omitempty" yaml:"default,omitempty"`
+	Rules   []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule matches tool calls. Empty fields match anything; all set fields must match.
+type Rule struct {
+	Name    string       `json:"name,omitempty" yaml:"name,omitempty"`
+	Effect  Effect       `json:"effect" yaml:"effect"`
+	Reason  string       `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Tools   []string     `json:"tools,omitempty" yaml:"tools,omitempty"`     // names or path.Match globs
+	Callers []string     `json:"callers,omitempty" yaml:"callers,omitempty"` // ToolCaller.Type; "direct" matches a nil caller
+	Scope   *ScopeMatch  `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Input   []InputMatch `json:"input,omitempty" yaml:"input,omitempty"`
+}
+
+// ScopeMatch matches toolscope.ToolScope fields from the call context.
+type ScopeMatch struct {
+	UserIDs         []int64  `json:"user_ids,omitempty" yaml:"user_ids,omitempty"`
+	ConversationIDs []string `json:"conversation_ids,omitempty" yaml:"conversation_ids,omitempty"`
+	Platforms       []string `json:"platforms,omitempty" yaml:"platforms,omitempty"`
+}
+
+// InputMatch is a predicate over a JSON path in ToolCall.Input.
+// Paths use dot and index notation, e.g. "command", "$.args[0]", "opts.mode".
+type InputMatch struct {
+	Path    string `json:"path" yaml:"path"`
+	Equals  any    `json:"equals,omitempty" yaml:"equals,omitempty"`
+	Matches string `json:"matches,omitempty" yaml:"matches,omitempty"` // regexp over string values
+	Exists  *bool  `json:"exists,omitempty" yaml:"exists,omitempty"`
+}
+
+// Decision reports which rule decided a call.
+type Decision struct {
+	Effect Effect
+	Rule   string // rule name, "#<index>" for unnamed rules, or "default"
+	Reason string
+}
+
+// DecisionError is returned for deny and ask decisions. It unwraps to
+// agentic.ErrPolicyDenied or agentic.ErrApprovalRequired.
+type DecisionError struct {
+	Tool     string
+	Decision Decision
+}
+
+func (e *DecisionError) Error() string {
+	msg := fmt.Sprintf("%s: %s (rule %s)", e.Unwrap().Error(), e.Tool, e.Decision.Rule)
+	if e.Decision.Reason != "" {
+		msg += ": " + e.Decision.Reason
+	}
+	return msg
+}
+
+// ApprovalRule implements agentic.ApprovalRuler. An explicit ask rule asks
+// for every call; only the default effect may be approved for the session.
+func (e *DecisionError) ApprovalRule() (string, bool) {
+	return e.Decision.Rule, e.Decision.Rule != "default"
+}
+
+func (e *DecisionError) Unwrap() error {
+	if e.Decision.Effect == Ask {
+		return agentic.ErrApprovalRequired
+	}
+	return agentic.ErrPolicyDenied
+}
+
+// Engine evaluates a compiled RuleSet. It implements agentic.Policy and
+// agentic.ContextPolicy.
+type Engine struct {
+	def   Effect
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	label   string
+	matches []*regexp.Regexp
+}
+
+// New validates and compiles a RuleSet.
+func New(set RuleSet) (*Engine, error) {
+	def := set.Default
+	if def == "" {
+		def = Allow
+	}
+	if !validEffect(def) {
+		return nil, fmt.Errorf("rules: invalid default effect %q", def)
+	}
+	engine := &Engine{def: def, rules: make([]compiledRule, 0, len(set.Rules))}
+	for i, rule := range set.Rules {
+		label := rule.Name
+		if label == "" {
+			label = "#" + strconv.Itoa(i)
+		}
+		if !validEffect(rule.Effect) {
+			return nil, fmt.Errorf("rules: rule %s: invalid effect %q", label, rule.Effect)
+		}
+		for _, pattern := range rule.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rules: rule %s: invalid tool pattern %q: %w", label, pattern, err)
+			}
+		}
+		compiled := compiledRule{Rule: rule, label: label, matches: make([]*regexp.Regexp, len(rule.Input))}
+		for j, pred := range rule.Input {
+			if strings.TrimSpace(pred.Path) == "" {
+				return nil, fmt.Errorf("rules: rule %s: input predicate %d has no path", label, j)
+			}
+			if pred.Matches == "" {
+				continue
+			}
+			re, err := regexp.Compile(pred.Matches)
+			if err != nil {
+				return nil, fmt.Errorf("rules: rule %s: %w", label, err)
+			}
+			compiled.matches[j] = re
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+// Parse decodes a YAML or JSON rule set and compiles it.
+func Parse(data []byte) (*Engine, error) {
+	var set RuleSet
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&set); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("rules: parse: %w", err)
+	}
+	return New(set)
+}
+
+// LoadFile reads a YAML or JSON rule file.
+func LoadFile(filename string) (*Engine, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("rules: %w", err)
+	}
+	return Parse(data)
+}
+
+// Evaluate returns the decision for a call.
+func (e *Engine) Evaluate(ctx context.Context, def agentic.ToolDefinition, call agentic.ToolCall) Decision {
+	var input any
+	decoded := false
+	for _, rule := range e.rules {
+		if !rule.matchesStatic(ctx, def.Name, call) {
+			continue
+		}
+		if len(rule.Input) > 0 && !decoded {
+			decoded = true
+			if len(call.Input) > 0 {
+				_ = json.Unmarshal(call.Input, &input)
+			}
+		}
+		if rule.matchesInput(input) {
+			return Decision{Effect: rule.Effect, Rule: rule.label, Reason: rule.Reason}
+		}
+	}
+	return Decision{Effect: e.def, Rule: "default"}
+}
+
+// AllowTool hides tools that can never run: the first rule covering the tool
+// is an unconditional deny, or no rule covers it and the default is deny.
+func (e *Engine) AllowTool(def agentic.ToolDefinition) error {
+	for _, rule := range e.rules {
+		if len(rule.Tools) > 0 && !matchAny(rule.Tools, def.Name) {
+			continue
+		}
+		if rule.Effect == Deny && rule.unconditional() {
+			return &DecisionError{Tool: def.Name, Decision: Decision{Effect: Deny, Rule: rule.label, Reason: rule.Reason}}
+		}
+		return nil
+	}
+	if e.def == Deny {
+		return &DecisionError{Tool: def.Name, Decision: Decision{Effect: Deny, Rule: "default"}}
+	}
+	return nil
+}
+
+// AllowCall evaluates the call without context; scope rules will not match.
+func (e *Engine) AllowCall(def agentic.ToolDefinition, call agentic.ToolCall) error {
+	return e.AllowCallContext(context.Background(), def, call)
+}
+
+// AllowCallContext implements agentic.ContextPolicy.
+func (e *Engine) AllowCallContext(ctx context.Context, def agentic.ToolDefinition, call agentic.ToolCall) error {
+	decision := e.Evaluate(ctx, def, call)
+	if decision.Effect == Allow {
+		return nil
+	}
+	return &DecisionError{Tool: def.Name, Decision: decision}
+}
+
+func (r compiledRule) unconditional() bool {
+	return len(r.Callers) == 0 && r.Scope == nil && len(r.Input) == 0
+}
+
+func (r compiledRule) matchesStatic(ctx context.Context, name string, call agentic.ToolCall) bool {
+	if len(r.Tools) > 0 && !matchAny(r.Tools, name) {
+		return false
+	}
+	if len(r.Callers) > 0 {
+		caller := "direct"
+		if call.Caller != nil && call.Caller.Type != "" {
+			caller = call.Caller.Type
+		}
+		if !slices.Contains(r.Callers, caller) {
+			return false
+		}
+	}
+	if r.Scope != nil {
+		scope, ok := toolscope.ScopeFromContext(ctx)
+		if !ok {
+			return false
+		}
+		if len(r.Scope.UserIDs) > 0 && !slices.Contains(r.Scope.UserIDs, scope.UserID) {
+			return false
+		}
+		if len(r.Scope.ConversationIDs) > 0 && !slices.Contains(r.Scope.ConversationIDs, scope.ConversationID) {
+			return false
+		}
+		if len(r.Scope.Platforms) > 0 && !slices.Contains(r.Scope.Platforms, scope.Platform) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r compiledRule) matchesInput(input any) bool {
+	for i, pred := range r.Input {
+		value, found := lookup(input, pred.Path)
+		if pred.Exists != nil && *pred.Exists != found {
+			return false
+		}
+		if pred.Equals != nil && (!found || !jsonEqual(value, pred.Equals)) {
+			return false
+		}
+		if re := r.matches[i]; re != nil {
+			text, ok := value.(string)
+			if !found || !ok || !re.MatchString(text) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func validEffect(effect Effect) bool {
+	return effect == Allow || effect == Deny || effect == Ask
+}
+
+// lookup resolves a dot/index path against decoded JSON.
+func lookup(value any, p string) (any, bool) {
+	p = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(p), "$"), ".")
+	if p == "" {
+		return value, true
+	}
+	for _, segment := range strings.Split(p, ".") {
+		key, rest, _ := strings.Cut(segment, "[")
+		if key != "" {
+			obj, ok := value.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if value, ok = obj[key]; !ok {
+				return nil, false
+			}
+		}
+		for rest != "" {
+			idxText, tail, ok := strings.Cut(rest, "]")
+			if !ok {
+				return nil, false
+			}
+			idx, err := strconv.Atoi(idxText)
+			arr, isArr := value.([]any)
+			if err != nil || !isArr || idx < 0 || idx >= len(arr) {
+				return nil, false
+			}
+			value = arr[idx]
+			rest = strings.TrimPrefix(tail, "[")
+		}
+	}
+	return value, true
+}
+
+// jsonEqual compares values by their JSON encoding so YAML ints match JSON numbers.
+func jsonEqual(a, b any) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(normalizeYAML(b))
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(left, right)
+}
+
+// normalizeYAML converts map[any]any values into JSON-encodable maps.
+func normalizeYAML(v any) any {
+	switch val := v.(type) {
+	case map[any]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[fmt.Sprint(k)] = normalizeYAML(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = normalizeYAML(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = normalizeYAML(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
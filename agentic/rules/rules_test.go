@@ -0,0 +1,208 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/toolscope"
+)
+
+const bashRules = `
+default: deny
+rules:
+  - name: go-test
+    effect: allow
+    tools: [bash]
+    input:
+      - path: command
+        matches: "^go test"
+  - name: bash-ask
+    effect: ask
+    tools: [bash]
+    reason: shell commands need review
+  - name: readers
+    effect: allow
+    tools: ["read", "grep*"]
+`
+
+func call(name, input string) agentic.ToolCall {
+	return agentic.ToolCall{Name: name, Input: []byte(input)}
+}
+
+func TestEngineFirstMatchWinsAndReportsRule(t *testing.T) {
+	engine, err := Parse([]byte(bashRules))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ctx := context.Background()
+	bash := agentic.ToolDefinition{Name: "bash"}
+
+	got := engine.Evaluate(ctx, bash, call("bash", `{"command":"go test ./..."}`))
+	if got.Effect != Allow || got.Rule != "go-test" {
+		t.Fatalf("unexpected decision: %#v", got)
+	}
+
+	err = engine.AllowCallContext(ctx, bash, call("bash", `{"command":"rm -rf /"}`))
+	if !errors.Is(err, agentic.ErrApprovalRequired) {
+		t.Fatalf("expected approval required, got %v", err)
+	}
+	var decisionErr *DecisionError
+	if !errors.As(err, &decisionErr) || decisionErr.Decision.Rule != "bash-ask" || decisionErr.Decision.Reason == "" {
+		t.Fatalf("expected bash-ask decision, got %#v", err)
+	}
+
+	if err := engine.AllowCall(agentic.ToolDefinition{Name: "grep_files"}, call("grep_files", `{}`)); err != nil {
+		t.Fatalf("expected glob allow, got %v", err)
+	}
+	err = engine.AllowCall(agentic.ToolDefinition{Name: "write"}, call("write", `{}`))
+	if !errors.Is(err, agentic.ErrPolicyDenied) || !errors.As(err, &decisionErr) || decisionErr.Decision.Rule != "default" {
+		t.Fatalf("expected default deny, got %v", err)
+	}
+}
+
+func TestEngineAllowToolHidesUnreachableTools(t *testing.T) {
+	engine, err := New(RuleSet{
+		Default: Deny,
+		Rules: []Rule{
+			{Name: "no-delete", Effect: Deny, Tools: []string{"delete"}},
+			{Effect: Ask, Tools: []string{"bash"}, Callers: []string{"direct"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	if err := engine.AllowTool(agentic.ToolDefinition{Name: "delete"}); err == nil {
+		t.Fatal("expected unconditional deny to hide tool")
+	}
+	if err := engine.AllowTool(agentic.ToolDefinition{Name: "bash"}); err != nil {
+		t.Fatalf("expected conditional rule to keep tool visible, got %v", err)
+	}
+	if err := engine.AllowTool(agentic.ToolDefinition{Name: "other"}); err == nil {
+		t.Fatal("expected default deny to hide unnamed tool")
+	}
+}
+
+func TestEngineMatchesCallerScopeAndInput(t *testing.T) {
+	exists := false
+	engine, err := New(RuleSet{
+		Rules: []Rule{
+			{Name: "programmatic", Effect: Deny, Callers: []string{"code_execution"}},
+			{Name: "web", Effect: Deny, Scope: &ScopeMatch{Platforms: []string{"web"}}},
+			{Name: "mode", Effect: Ask, Input: []InputMatch{{Path: "$.opts.mode", Equals: 7}, {Path: "args[1]", Equals: "x"}}},
+			{Name: "no-force", Effect: Allow, Input: []InputMatch{{Path: "force", Exists: &exists}}},
+		},
+		Default: Deny,
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	def := agentic.ToolDefinition{Name: "tool"}
+	ctx := context.Background()
+
+	programmatic := call("tool", `{}`)
+	programmatic.Caller = &agentic.ToolCaller{Type: "code_execution"}
+	if got := engine.Evaluate(ctx, def, programmatic); got.Rule != "programmatic" {
+		t.Fatalf("expected caller rule, got %#v", got)
+	}
+
+	webCtx := toolscope.WithScope(ctx, toolscope.ToolScope{Platform: "web"})
+	if got := engine.Evaluate(webCtx, def, call("tool", `{}`)); got.Rule != "web" {
+		t.Fatalf("expected scope rule, got %#v", got)
+	}
+
+	if got := engine.Evaluate(ctx, def, call("tool", `{"opts":{"mode":7},"args":["a","x"],"force":true}`)); got.Rule != "mode" {
+		t.Fatalf("expected input rule, got %#v", got)
+	}
+	if got := engine.Evaluate(ctx, def, call("tool", `{"opts":{"mode":8}}`)); got.Rule != "no-force" {
+		t.Fatalf("expected exists rule, got %#v", got)
+	}
+	if got := engine.Evaluate(ctx, def, call("tool", `{"force":true}`)); got.Rule != "default" {
+		t.Fatalf("expected default, got %#v", got)
+	}
+}
+
+func TestLoadFileJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	data := `{"default":"allow","rules":[{"name":"block","effect":"deny","tools":["bash"]}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	engine, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := engine.Evaluate(context.Background(), agentic.ToolDefinition{Name: "bash"}, call("bash", `{}`)); got.Effect != Deny || got.Rule != "block" {
+		t.Fatalf("unexpected decision: %#v", got)
+	}
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	cases := []string{
+		"rules:\n  - effect: maybe\n",
+		"default: nope\n",
+		"rules:\n  - effect: allow\n    input:\n      - path: x\n        matches: \"(\"\n",
+		"rules:\n  - effect: allow\n    tool: [bash]\n",
+	}
+	for _, tc := range cases {
+		if _, err := Parse([]byte(tc)); err == nil {
+			t.Fatalf("expected error for %q", tc)
+		}
+	}
+}
+
+func TestRegistryUsesContextPolicy(t *testing.T) {
+	engine, err := New(RuleSet{Rules: []Rule{{Name: "web", Effect: Deny, Scope: &ScopeMatch{Platforms: []string{"web"}}}}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	reg := agentic.NewRegistry(agentic.WithPolicy(engine))
+	if err := reg.Register(echoTool{}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	ctx := toolscope.WithScope(context.Background(), toolscope.ToolScope{Platform: "web"})
+	if _, err := reg.Execute(ctx, call("echo", `{}`)); !errors.Is(err, agentic.ErrPolicyDenied) {
+		t.Fatalf("expected scope deny, got %v", err)
+	}
+	if _, err := reg.Execute(context.Background(), call("echo", `{}`)); err != nil {
+		t.Fatalf("expected allow without scope, got %v", err)
+	}
+}
+
+func TestAskRuleApprovalIsNotRemembered(t *testing.T) {
+	engine, err := New(RuleSet{Rules: []Rule{
+		{Name: "safe", Effect: Allow, Input: []InputMatch{{Path: "command", Matches: "^ls$"}}},
+		{Name: "shell", Effect: Ask},
+	}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	var prompts []agentic.ApprovalRequest
+	approver := agentic.NewSessionApprover(agentic.ApproverFunc(func(_ context.Context, req agentic.ApprovalRequest) (agentic.ApprovalDecision, error) {
+		prompts = append(prompts, req)
+		return agentic.ApprovalAllowAlways, nil
+	}))
+	reg := agentic.NewRegistry(agentic.WithPolicy(engine), agentic.WithApprover(approver))
+	if err := reg.Register(echoTool{}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	for _, input := range []string{`{"command":"pwd"}`, `{"command":"rm -rf /"}`} {
+		if _, err := reg.Execute(context.Background(), call("echo", input)); err != nil {
+			t.Fatalf("execute %s: %v", input, err)
+		}
+	}
+	if len(prompts) != 2 || prompts[1].Rule != "shell" || !prompts[1].EveryTime {
+		t.Fatalf("expected the ask rule to prompt for each call, got %+v", prompts)
+	}
+}
+
+type echoTool struct{}
+
+func (echoTool) Definition() agentic.ToolDefinition { return agentic.ToolDefinition{Name: "echo"} }
+
+func (echoTool) Execute(_ context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+	return agentic.ToolResult{ID: call.ID, Name: call.Name, Output: call.Input}, nil
+}
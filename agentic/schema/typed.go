@@ -4,10 +4,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// SchemaFromStruct derives a minimal JSON schema from a struct type.
+// Schemer lets a type supply its own JSON schema to SchemaFromStruct.
+type Schemer interface {
+	JSONSchema() json.RawMessage
+}
+
+// SchemaFromStruct derives a JSON schema from a struct type.
+//
+// Field tags:
+//   - desc:"..." sets the description
+//   - enum:"a,b,c" restricts values (applied to items for slices)
+//   - min:"1" / max:"10" bound numbers, string length or array length
+//   - pattern:"^x" constrains strings (applied to items for slices)
+//   - default:"..." sets a default parsed to the field's type
+//
+// Maps become objects with additionalProperties, embedded structs are
+// flattened, json.RawMessage and interfaces accept any value, and recursive
+// types are emitted under $defs and referenced with $ref.
 func SchemaFromStruct(value any) (json.RawMessage, error) {
 	if value == nil {
 		return nil, fmt.Errorf("nil value")
@@ -20,7 +38,19 @@ func SchemaFromStruct(value any) (json.RawMessage, error) {
 		return nil, fmt.Errorf("expected struct, got %s", typeOf.Kind())
 	}
 
-	schema := buildSchemaFromStruct(typeOf)
+	// The first pass discovers recursive types; the second emits them as $defs.
+	gen := newGenerator(typeOf, nil)
+	schema := gen.generate(typeOf)
+	if gen.err != nil {
+		return nil, gen.err
+	}
+	if len(gen.recursive) > 0 {
+		gen = newGenerator(typeOf, gen.recursive)
+		schema = gen.generate(typeOf)
+		if len(gen.defs) > 0 {
+			schema.Defs = gen.defs
+		}
+	}
 	payload, err := json.Marshal(schema)
 	if err != nil {
 		return nil, err
@@ -29,20 +59,174 @@ func SchemaFromStruct(value any) (json.RawMessage, error) {
 }
 
 type jsonSchema struct {
-	Type        string                `json:"type,omitempty"`
-	Properties  map[string]jsonSchema `json:"properties,omitempty"`
-	Required    []string              `json:"required,omitempty"`
-	Items       *jsonSchema           `json:"items,omitempty"`
-	Format      string                `json:"format,omitempty"`
-	Description string                `json:"description,omitempty"`
+	Ref                  string                `json:"$ref,omitempty"`
+	Type                 string                `json:"type,omitempty"`
+	Properties           map[string]jsonSchema `json:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty"`
+	AdditionalProperties *jsonSchema           `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema           `json:"items,omitempty"`
+	Format               string                `json:"format,omitempty"`
+	Description          string                `json:"description,omitempty"`
+	Enum                 []any                 `json:"enum,omitempty"`
+	Default              any                   `json:"default,omitempty"`
+	Minimum              *float64              `json:"minimum,omitempty"`
+	Maximum              *float64              `json:"maximum,omitempty"`
+	MinLength            *int                  `json:"minLength,omitempty"`
+	MaxLength            *int                  `json:"maxLength,omitempty"`
+	MinItems             *int                  `json:"minItems,omitempty"`
+	MaxItems             *int                  `json:"maxItems,omitempty"`
+	Pattern              string                `json:"pattern,omitempty"`
+	Defs                 map[string]jsonSchema `json:"$defs,omitempty"`
+
+	// Raw replaces the generated schema (Schemer types).
+	Raw json.RawMessage `json:"-"`
+}
+
+func (s jsonSchema) MarshalJSON() ([]byte, error) {
+	if len(s.Raw) > 0 {
+		return s.Raw, nil
+	}
+	type plain jsonSchema
+	return json.Marshal(plain(s))
+}
+
+var (
+	schemerType    = reflect.TypeOf((*Schemer)(nil)).Elem()
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+type generator struct {
+	root      reflect.Type
+	recursive map[reflect.Type]bool
+	visiting  map[reflect.Type]bool
+	defs      map[string]jsonSchema
+	defNames  map[reflect.Type]string
+	err       error
+}
+
+func newGenerator(root reflect.Type, recursive map[reflect.Type]bool) *generator {
+	if recursive == nil {
+		recursive = make(map[reflect.Type]bool)
+	}
+	return &generator{
+		root:      root,
+		recursive: recursive,
+		visiting:  make(map[reflect.Type]bool),
+		defs:      make(map[string]jsonSchema),
+		defNames:  make(map[reflect.Type]string),
+	}
+}
+
+func (g *generator) generate(t reflect.Type) jsonSchema {
+	for t.Kind() == reflect.Pointer {
+		if raw, ok := customSchema(t); ok {
+			return jsonSchema{Raw: raw}
+		}
+		t = t.Elem()
+	}
+	if raw, ok := customSchema(t); ok {
+		return jsonSchema{Raw: raw}
+	}
+	if t == rawMessageType {
+		return jsonSchema{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return jsonSchema{Type: "string"}
+	case reflect.Bool:
+		return jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return jsonSchema{Type: "string", Format: "byte"}
+		}
+		itemSchema := g.generate(t.Elem())
+		return jsonSchema{Type: "array", Items: &itemSchema}
+	case reflect.Map:
+		valueSchema := g.generate(t.Elem())
+		return jsonSchema{Type: "object", AdditionalProperties: &valueSchema}
+	case reflect.Interface:
+		return jsonSchema{}
+	case reflect.Struct:
+		if t == timeType {
+			return jsonSchema{Type: "string", Format: "date-time"}
+		}
+		return g.structSchema(t)
+	default:
+		return jsonSchema{Type: "string"}
+	}
+}
+
+func (g *generator) structSchema(t reflect.Type) jsonSchema {
+	if g.visiting[t] {
+		g.recursive[t] = true
+		return jsonSchema{Ref: g.refFor(t)}
+	}
+	if g.recursive[t] && t != g.root {
+		ref := g.refFor(t)
+		name := g.defNames[t]
+		if _, done := g.defs[name]; !done {
+			g.visiting[t] = true
+			g.defs[name] = g.buildStruct(t)
+			delete(g.visiting, t)
+		}
+		return jsonSchema{Ref: ref}
+	}
+	g.visiting[t] = true
+	defer delete(g.visiting, t)
+	return g.buildStruct(t)
 }
 
-func buildSchemaFromStruct(t reflect.Type) jsonSchema {
+func (g *generator) refFor(t reflect.Type) string {
+	if t == g.root {
+		return "#"
+	}
+	name, ok := g.defNames[t]
+	if !ok {
+		name = t.Name()
+		if name == "" {
+			name = "T" + strconv.Itoa(len(g.defNames))
+		}
+		for _, existing := range g.defNames {
+			if existing == name {
+				name = strings.NewReplacer("/", "_", ".", "_").Replace(t.PkgPath()) + "_" + name
+				break
+			}
+		}
+		g.defNames[t] = name
+	}
+	return "#/$defs/" + name
+}
+
+func (g *generator) buildStruct(t reflect.Type) jsonSchema {
 	properties := make(map[string]jsonSchema)
 	required := make([]string, 0)
+	g.collectFields(t, properties, &required)
+	return jsonSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   required,
+	}
+}
 
+func (g *generator) collectFields(t reflect.Type, properties map[string]jsonSchema, required *[]string) {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.collectFields(embedded, properties, required)
+				continue
+			}
+		}
 		if field.PkgPath != "" { // unexported
 			continue
 		}
@@ -50,21 +234,118 @@ func buildSchemaFromStruct(t reflect.Type) jsonSchema {
 		if name == "" {
 			continue
 		}
-		fieldSchema := schemaForType(field.Type)
-		desc := field.Tag.Get("desc")
-		if desc != "" {
-			fieldSchema.Description = desc
+		fieldSchema := g.generate(field.Type)
+		if err := applyTags(&fieldSchema, field); err != nil && g.err == nil {
+			g.err = fmt.Errorf("field %s: %w", field.Name, err)
 		}
 		properties[name] = fieldSchema
 		if !omitEmpty {
-			required = append(required, name)
+			*required = append(*required, name)
 		}
 	}
+}
 
-	return jsonSchema{
-		Type:       "object",
-		Properties: properties,
-		Required:   required,
+func customSchema(t reflect.Type) (json.RawMessage, bool) {
+	if !t.Implements(schemerType) {
+		return nil, false
+	}
+	var value reflect.Value
+	if t.Kind() == reflect.Pointer {
+		value = reflect.New(t.Elem())
+	} else {
+		value = reflect.Zero(t)
+	}
+	raw := value.Interface().(Schemer).JSONSchema()
+	if len(raw) == 0 {
+		return nil, false
+	}
+	return raw, true
+}
+
+func applyTags(schema *jsonSchema, field reflect.StructField) error {
+	if desc := field.Tag.Get("desc"); desc != "" {
+		schema.Description = desc
+	}
+	if len(schema.Raw) > 0 {
+		return nil
+	}
+	target := schema
+	if schema.Type == "array" && schema.Items != nil {
+		target = schema.Items
+	}
+	if enum := field.Tag.Get("enum"); enum != "" {
+		for _, part := range strings.Split(enum, ",") {
+			value, err := parseTagValue(target.Type, strings.TrimSpace(part))
+			if err != nil {
+				return fmt.Errorf("enum: %w", err)
+			}
+			target.Enum = append(target.Enum, value)
+		}
+	}
+	if pattern := field.Tag.Get("pattern"); pattern != "" {
+		target.Pattern = pattern
+	}
+	if def, ok := field.Tag.Lookup("default"); ok {
+		value, err := parseTagValue(schema.Type, def)
+		if err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+		schema.Default = value
+	}
+	for _, key := range []string{"min", "max"} {
+		text := field.Tag.Get(key)
+		if text == "" {
+			continue
+		}
+		bound, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		setBound(schema, key == "min", bound)
+	}
+	return nil
+}
+
+func setBound(schema *jsonSchema, isMin bool, bound float64) {
+	count := int(bound)
+	switch schema.Type {
+	case "string":
+		if isMin {
+			schema.MinLength = &count
+		} else {
+			schema.MaxLength = &count
+		}
+	case "array":
+		if isMin {
+			schema.MinItems = &count
+		} else {
+			schema.MaxItems = &count
+		}
+	default:
+		if isMin {
+			schema.Minimum = &bound
+		} else {
+			schema.Maximum = &bound
+		}
+	}
+}
+
+func parseTagValue(schemaType, text string) (any, error) {
+	switch schemaType {
+	case "integer":
+		return strconv.ParseInt(text, 10, 64)
+	case "number":
+		return strconv.ParseFloat(text, 64)
+	case "boolean":
+		return strconv.ParseBool(text)
+	case "string":
+		return text, nil
+	default:
+		var value any
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return text, nil
+		}
+		return value, nil
 	}
 }
 
@@ -90,31 +371,3 @@ func jsonFieldName(field reflect.StructField) (string, bool) {
 	}
 	return name, omitEmpty
 }
-
-func schemaForType(t reflect.Type) jsonSchema {
-	for t.Kind() == reflect.Pointer {
-		t = t.Elem()
-	}
-	switch t.Kind() {
-	case reflect.String:
-		return jsonSchema{Type: "string"}
-	case reflect.Bool:
-		return jsonSchema{Type: "boolean"}
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return jsonSchema{Type: "integer"}
-	case reflect.Float32, reflect.Float64:
-		return jsonSchema{Type: "number"}
-	case reflect.Slice, reflect.Array:
-		itemSchema := schemaForType(t.Elem())
-		return jsonSchema{Type: "array", Items: &itemSchema}
-	case reflect.Struct:
-		if t.PkgPath() == "time" && t.Name() == "Time" {
-			return jsonSchema{Type: "string", Format: "date-time"}
-		}
-		nested := buildSchemaFromStruct(t)
-		return nested
-	default:
-		return jsonSchema{Type: "string"}
-	}
-}
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -28,3 +29,129 @@ func TestSchemaFromStruct(t *testing.T) {
 		t.Fatalf("missing name property")
 	}
 }
+
+type baseInput struct {
+	Path string `json:"path" desc:"File path."`
+}
+
+type richInput struct {
+	baseInput
+	Mode    string            `json:"mode" enum:"read,write" default:"read"`
+	Limit   int               `json:"limit,omitempty" min:"1" max:"100"`
+	Name    string            `json:"name,omitempty" pattern:"^[a-z]+$" min:"2"`
+	Tags    []string          `json:"tags,omitempty" enum:"x,y" max:"2"`
+	Labels  map[string]int    `json:"labels,omitempty"`
+	Payload json.RawMessage   `json:"payload,omitempty"`
+	Any     any               `json:"any,omitempty"`
+	Color   color             `json:"color,omitempty"`
+	Meta    map[string]string `json:"-"`
+}
+
+type color string
+
+func (color) JSONSchema() json.RawMessage {
+	return json.RawMessage(`{"type":"string","pattern":"^#[0-9a-f]{6}$"}`)
+}
+
+type treeNode struct {
+	Value    string     `json:"value"`
+	Children []treeNode `json:"children,omitempty"`
+	Meta     *nodeMeta  `json:"meta,omitempty"`
+}
+
+type nodeMeta struct {
+	Link *nodeMeta `json:"link,omitempty"`
+}
+
+func decodeSchema(t *testing.T, value any) map[string]any {
+	t.Helper()
+	payload, err := SchemaFromStruct(value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("invalid JSON schema: %v", err)
+	}
+	return decoded
+}
+
+func TestSchemaFromStructTagsAndTypes(t *testing.T) {
+	decoded := decodeSchema(t, richInput{})
+	props := decoded["properties"].(map[string]any)
+
+	path := props["path"].(map[string]any)
+	if path["description"] != "File path." {
+		t.Fatalf("expected embedded field to be flattened, got %#v", props)
+	}
+	mode := props["mode"].(map[string]any)
+	if len(mode["enum"].([]any)) != 2 || mode["default"] != "read" {
+		t.Fatalf("unexpected mode schema: %#v", mode)
+	}
+	limit := props["limit"].(map[string]any)
+	if limit["minimum"] != 1.0 || limit["maximum"] != 100.0 {
+		t.Fatalf("unexpected limit schema: %#v", limit)
+	}
+	name := props["name"].(map[string]any)
+	if name["pattern"] != "^[a-z]+$" || name["minLength"] != 2.0 {
+		t.Fatalf("unexpected name schema: %#v", name)
+	}
+	tags := props["tags"].(map[string]any)
+	if tags["maxItems"] != 2.0 || len(tags["items"].(map[string]any)["enum"].([]any)) != 2 {
+		t.Fatalf("unexpected tags schema: %#v", tags)
+	}
+	labels := props["labels"].(map[string]any)
+	if labels["type"] != "object" || labels["additionalProperties"].(map[string]any)["type"] != "integer" {
+		t.Fatalf("unexpected labels schema: %#v", labels)
+	}
+	if len(props["payload"].(map[string]any)) != 0 || len(props["any"].(map[string]any)) != 0 {
+		t.Fatalf("expected raw and interface fields to accept anything: %#v %#v", props["payload"], props["any"])
+	}
+	if props["color"].(map[string]any)["pattern"] != "^#[0-9a-f]{6}$" {
+		t.Fatalf("expected custom schema, got %#v", props["color"])
+	}
+	if _, ok := props["Meta"]; ok {
+		t.Fatalf("expected json:\"-\" field to be skipped")
+	}
+
+	payload, _ := SchemaFromStruct(richInput{})
+	if err := Validate(payload, json.RawMessage(`{"path":"a","mode":"write","color":"#00ff00"}`)); err != nil {
+		t.Fatalf("expected valid input, got %v", err)
+	}
+	if err := Validate(payload, json.RawMessage(`{"path":"a","mode":"delete","limit":0}`)); err == nil {
+		t.Fatal("expected generated schema to reject invalid input")
+	}
+}
+
+func TestSchemaFromStructRecursiveTypes(t *testing.T) {
+	decoded := decodeSchema(t, treeNode{})
+	props := decoded["properties"].(map[string]any)
+	children := props["children"].(map[string]any)
+	if children["items"].(map[string]any)["$ref"] != "#" {
+		t.Fatalf("expected root recursion to use #, got %#v", children)
+	}
+	if props["meta"].(map[string]any)["$ref"] != "#/$defs/nodeMeta" {
+		t.Fatalf("expected nested recursion to use $defs, got %#v", props["meta"])
+	}
+	defs := decoded["$defs"].(map[string]any)
+	meta := defs["nodeMeta"].(map[string]any)
+	link := meta["properties"].(map[string]any)["link"].(map[string]any)
+	if link["$ref"] != "#/$defs/nodeMeta" {
+		t.Fatalf("unexpected nodeMeta def: %#v", meta)
+	}
+
+	payload, _ := SchemaFromStruct(treeNode{})
+	if err := Validate(payload, json.RawMessage(`{"value":"a","children":[{"value":"b"},{"children":[]}]}`)); err == nil ||
+		!strings.Contains(err.Error(), "$.children[1].value: required property is missing") {
+		t.Fatalf("expected recursive validation error, got %v", err)
+	}
+}
+
+func TestSchemaFromStructRejectsBadTags(t *testing.T) {
+	type bad struct {
+		Count int `json:"count" enum:"one"`
+	}
+	if _, err := SchemaFromStruct(bad{}); err == nil {
+		t.Fatal("expected invalid enum tag to fail")
+	}
+}
@@ -0,0 +1,342 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Issue is a single validation failure at a JSON path such as "$.items[0].name".
+type Issue struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return i.Path + ": " + i.Message
+}
+
+// ValidationError lists every issue found in an input.
+type ValidationError struct {
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		parts[i] = issue.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validate checks input against a JSON schema. It supports the subset used for
+// tool inputs: type, required, properties, additionalProperties, items, enum,
+// const, numeric bounds, string length and pattern, array length, anyOf and
+// local $ref ("#" or "#/$defs/<name>").
+// An empty schema accepts anything. Failures are returned as *ValidationError.
+func Validate(schema, input json.RawMessage) error {
+	if len(bytes.TrimSpace(schema)) == 0 {
+		return nil
+	}
+	var node map[string]any
+	if err := json.Unmarshal(schema, &node); err != nil {
+		return fmt.Errorf("schema: invalid schema: %w", err)
+	}
+	var value any
+	if len(bytes.TrimSpace(input)) == 0 {
+		value = map[string]any{}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(input))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return &ValidationError{Issues: []Issue{{Path: "$", Message: "invalid JSON: " + err.Error()}}}
+		}
+	}
+	v := &validator{root: node}
+	v.validate(node, value, "$")
+	if len(v.issues) == 0 {
+		return nil
+	}
+	return &ValidationError{Issues: v.issues}
+}
+
+type validator struct {
+	root   map[string]any
+	issues []Issue
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.issues = append(v.issues, Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(node map[string]any, value any, path string) {
+	node = v.resolve(node, path)
+	if node == nil {
+		return
+	}
+	if types := schemaTypes(node["type"]); len(types) > 0 {
+		actual := jsonType(value)
+		if !typeAllowed(types, actual, value) {
+			v.fail(path, "expected %s, got %s", strings.Join(types, " or "), actual)
+			return
+		}
+	}
+	if enum, ok := node["enum"].([]any); ok && !containsValue(enum, value) {
+		v.fail(path, "must be one of %s", formatValues(enum))
+	}
+	if constant, ok := node["const"]; ok && !equalValues(constant, value) {
+		v.fail(path, "must equal %s", formatValue(constant))
+	}
+	if anyOf, ok := node["anyOf"].([]any); ok && len(anyOf) > 0 && !v.matchesAny(anyOf, value, path) {
+		v.fail(path, "does not match any allowed schema")
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.validateObject(node, val, path)
+	case []any:
+		v.validateArray(node, val, path)
+	case string:
+		v.validateString(node, val, path)
+	case json.Number:
+		v.validateNumber(node, val, path)
+	}
+}
+
+// resolve follows local $ref chains. Unresolvable references are reported.
+func (v *validator) resolve(node map[string]any, path string) map[string]any {
+	for range 32 {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node
+		}
+		var target map[string]any
+		switch {
+		case ref == "#":
+			target = v.root
+		case strings.HasPrefix(ref, "#/$defs/"):
+			defs, _ := v.root["$defs"].(map[string]any)
+			target, _ = defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		}
+		if target == nil {
+			v.fail(path, "unresolvable schema reference %q", ref)
+			return nil
+		}
+		node = target
+	}
+	v.fail(path, "schema reference chain too deep")
+	return nil
+}
+
+func (v *validator) matchesAny(options []any, value any, path string) bool {
+	for _, option := range options {
+		sub, ok := option.(map[string]any)
+		if !ok {
+			continue
+		}
+		probe := &validator{root: v.root}
+		probe.validate(sub, value, path)
+		if len(probe.issues) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) validateObject(node map[string]any, obj map[string]any, path string) {
+	if required, ok := node["required"].([]any); ok {
+		for _, name := range required {
+			key, _ := name.(string)
+			if _, present := obj[key]; key != "" && !present {
+				v.fail(childPath(path, key), "required property is missing")
+			}
+		}
+	}
+	properties, _ := node["properties"].(map[string]any)
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if sub, ok := properties[key].(map[string]any); ok {
+			v.validate(sub, obj[key], childPath(path, key))
+			continue
+		}
+		switch extra := node["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				v.fail(childPath(path, key), "unknown property")
+			}
+		case map[string]any:
+			v.validate(extra, obj[key], childPath(path, key))
+		}
+	}
+}
+
+func (v *validator) validateArray(node map[string]any, arr []any, path string) {
+	if limit, ok := intKeyword(node, "minItems"); ok && len(arr) < limit {
+		v.fail(path, "must have at least %d items", limit)
+	}
+	if limit, ok := intKeyword(node, "maxItems"); ok && len(arr) > limit {
+		v.fail(path, "must have at most %d items", limit)
+	}
+	items, ok := node["items"].(map[string]any)
+	if !ok {
+		return
+	}
+	for i, item := range arr {
+		v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))
+	}
+}
+
+func (v *validator) validateString(node map[string]any, s string, path string) {
+	length := utf8.RuneCountInString(s)
+	if limit, ok := intKeyword(node, "minLength"); ok && length < limit {
+		v.fail(path, "must be at least %d characters", limit)
+	}
+	if limit, ok := intKeyword(node, "maxLength"); ok && length > limit {
+		v.fail(path, "must be at most %d characters", limit)
+	}
+	if pattern, ok := node["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err == nil && !re.MatchString(s) {
+			v.fail(path, "must match pattern %q", pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(node map[string]any, n json.Number, path string) {
+	value, err := n.Float64()
+	if err != nil {
+		return
+	}
+	if limit, ok := floatKeyword(node, "minimum"); ok && value < limit {
+		v.fail(path, "must be >= %v", limit)
+	}
+	if limit, ok := floatKeyword(node, "maximum"); ok && value > limit {
+		v.fail(path, "must be <= %v", limit)
+	}
+	if limit, ok := floatKeyword(node, "exclusiveMinimum"); ok && value <= limit {
+		v.fail(path, "must be > %v", limit)
+	}
+	if limit, ok := floatKeyword(node, "exclusiveMaximum"); ok && value >= limit {
+		v.fail(path, "must be < %v", limit)
+	}
+}
+
+func schemaTypes(raw any) []string {
+	switch t := raw.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+func typeAllowed(types []string, actual string, value any) bool {
+	for _, t := range types {
+		if t == actual {
+			return true
+		}
+		if t == "integer" && actual == "number" {
+			f, err := value.(json.Number).Float64()
+			if err == nil && f == math.Trunc(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func intKeyword(node map[string]any, key string) (int, bool) {
+	f, ok := floatKeyword(node, key)
+	return int(f), ok
+}
+
+func floatKeyword(node map[string]any, key string) (float64, bool) {
+	f, ok := node[key].(float64)
+	return f, ok
+}
+
+func childPath(path, key string) string {
+	return path + "." + key
+}
+
+func containsValue(values []any, value any) bool {
+	for _, candidate := range values {
+		if equalValues(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares by JSON encoding so json.Number and float64 agree.
+func equalValues(a, b any) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return normalizeNumber(left) == normalizeNumber(right)
+}
+
+func normalizeNumber(raw []byte) string {
+	var f float64
+	if err := json.Unmarshal(raw, &f); err == nil {
+		return fmt.Sprint(f)
+	}
+	return string(raw)
+}
+
+func formatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = formatValue(value)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func formatValue(value any) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const toolSchema = `{
+	"type": "object",
+	"properties": {
+		"path": {"type": "string", "minLength": 1},
+		"mode": {"type": "string", "enum": ["read", "write"]},
+		"limit": {"type": "integer", "minimum": 1, "maximum": 100},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+		"opts": {
+			"type": "object",
+			"properties": {"depth": {"type": "number", "exclusiveMinimum": 0}},
+			"required": ["depth"],
+			"additionalProperties": false
+		}
+	},
+	"required": ["path"]
+}`
+
+func TestValidateAcceptsValidInput(t *testing.T) {
+	input := `{"path":"a.go","mode":"read","limit":10,"tags":["x"],"opts":{"depth":1.5}}`
+	if err := Validate(json.RawMessage(toolSchema), json.RawMessage(input)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateReportsPathLevelIssues(t *testing.T) {
+	input := `{"mode":"delete","limit":1.5,"tags":["x",2,"z"],"opts":{"extra":true}}`
+	err := Validate(json.RawMessage(toolSchema), json.RawMessage(input))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	want := []string{
+		`$.path: required property is missing`,
+		`$.mode: must be one of ["read", "write"]`,
+		`$.limit: expected integer, got number`,
+		`$.tags: must have at most 2 items`,
+		`$.tags[1]: expected string, got number`,
+		`$.opts.depth: required property is missing`,
+		`$.opts.extra: unknown property`,
+	}
+	got := err.Error()
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Fatalf("expected %q in %q", w, got)
+		}
+	}
+	if len(validationErr.Issues) != len(want) {
+		t.Fatalf("expected %d issues, got %#v", len(want), validationErr.Issues)
+	}
+}
+
+func TestValidateBoundsAndEmptyInput(t *testing.T) {
+	if err := Validate(json.RawMessage(toolSchema), json.RawMessage(`{"path":"","limit":0}`)); err == nil ||
+		!strings.Contains(err.Error(), "$.path: must be at least 1 characters") ||
+		!strings.Contains(err.Error(), "$.limit: must be >= 1") {
+		t.Fatalf("expected bound errors, got %v", err)
+	}
+	if err := Validate(json.RawMessage(toolSchema), nil); err == nil || !strings.Contains(err.Error(), "$.path") {
+		t.Fatalf("expected empty input to be treated as an object, got %v", err)
+	}
+	if err := Validate(nil, json.RawMessage(`"anything"`)); err != nil {
+		t.Fatalf("expected empty schema to accept input, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+// Package spill keeps the full output of truncated tool results so the model
+// can read past the truncation with a follow-up tool call.
+package spill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by Load for unknown references.
+var ErrNotFound = errors.New("spill: output not found")
+
+// Store saves full tool outputs and returns references to them.
+type Store interface {
+	Save(ctx context.Context, toolName string, data []byte) (ref string, err error)
+	Load(ctx context.Context, ref string) ([]byte, error)
+}
+
+// MemoryStore keeps outputs in memory for the life of the process.
+type MemoryStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+	next  int
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{blobs: make(map[string][]byte)}
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(_ context.Context, toolName string, data []byte) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	ref := fmt.Sprintf("%s-%d", refPrefix(toolName), s.next)
+	s.blobs[ref] = append([]byte(nil), data...)
+	return ref, nil
+}
+
+// Load implements Store.
+func (s *MemoryStore) Load(_ context.Context, ref string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blobs[ref]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+// DirStore writes each output to its own file in a directory. References are
+// file names, so outputs outlive the process.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore creates dir if needed and stores outputs in it.
+func NewDirStore(dir string) (*DirStore, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, errors.New("spill: directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("spill: %w", err)
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+// Save implements Store.
+func (s *DirStore) Save(_ context.Context, toolName string, data []byte) (string, error) {
+	f, err := os.CreateTemp(s.dir, refPrefix(toolName)+"-*.out")
+	if err != nil {
+		return "", fmt.Errorf("spill: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("spill: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("spill: %w", err)
+	}
+	return filepath.Base(f.Name()), nil
+}
+
+// Load implements Store. References that are not plain file names are
+// rejected, so a model cannot read outside the directory.
+func (s *DirStore) Load(_ context.Context, ref string) ([]byte, error) {
+	if ref == "" || ref != filepath.Base(ref) || ref == "." || ref == ".." {
+		return nil, fmt.Errorf("spill: invalid reference %q", ref)
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, ref))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("spill: %w", err)
+	}
+	return data, nil
+}
+
+// refPrefix turns a tool name into a safe reference prefix.
+func refPrefix(toolName string) string {
+	prefix := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, toolName)
+	if prefix == "" {
+		return "output"
+	}
+	return prefix
+}
@@ -0,0 +1,115 @@
+package spill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+func TestStoresRoundTrip(t *testing.T) {
+	dir, err := NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new dir store: %v", err)
+	}
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "dir": dir} {
+		ref, err := store.Save(context.Background(), "run/bash", []byte("full output"))
+		if err != nil {
+			t.Fatalf("%s save: %v", name, err)
+		}
+		if !strings.HasPrefix(ref, "run_bash-") {
+			t.Fatalf("%s: unexpected ref %q", name, ref)
+		}
+		data, err := store.Load(context.Background(), ref)
+		if err != nil || string(data) != "full output" {
+			t.Fatalf("%s load: %q, %v", name, data, err)
+		}
+		if _, err := store.Load(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("%s: expected ErrNotFound, got %v", name, err)
+		}
+	}
+	if _, err := dir.Load(context.Background(), "../secret"); err == nil {
+		t.Fatal("expected a path outside the directory to be rejected")
+	}
+}
+
+func TestToolPagesThroughOutput(t *testing.T) {
+	store := NewMemoryStore()
+	ref, _ := store.Save(context.Background(), "bash", []byte("one\ntwo\nthree\nfour\nfive"))
+	tool, err := NewTool(store)
+	if err != nil {
+		t.Fatalf("new tool: %v", err)
+	}
+
+	input, _ := json.Marshal(map[string]any{"ref": ref, "offset": 2, "limit": 2})
+	result, err := tool.Execute(context.Background(), agentic.ToolCall{Name: ToolName, Input: input})
+	if err != nil || result.Error != nil {
+		t.Fatalf("execute: %v %v", err, result.Error)
+	}
+	var out readOutput
+	if err := json.Unmarshal(result.Output, &out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := readOutput{Ref: ref, Content: "two\nthree", StartLine: 2, EndLine: 3, TotalLines: 5, NextOffset: 4}
+	if out != want {
+		t.Fatalf("expected %+v, got %+v", want, out)
+	}
+
+	input, _ = json.Marshal(map[string]any{"ref": "missing"})
+	result, _ = tool.Execute(context.Background(), agentic.ToolCall{Name: ToolName, Input: input})
+	if result.Error == nil {
+		t.Fatal("expected an error result for an unknown ref")
+	}
+}
+
+func TestToolPagesThroughLongLine(t *testing.T) {
+	items := make([]string, 0, 12000)
+	for i := range 12000 {
+		items = append(items, strings.Repeat("é", i%3)+"item")
+	}
+	line, _ := json.Marshal(items)
+	content := string(line) + "\ntail"
+	if len(line) <= 2*maxPageBytes {
+		t.Fatalf("test line too short: %d bytes", len(line))
+	}
+	store := NewMemoryStore()
+	ref, _ := store.Save(context.Background(), "query", []byte(content))
+	tool, err := NewTool(store)
+	if err != nil {
+		t.Fatalf("new tool: %v", err)
+	}
+
+	var got strings.Builder
+	next := map[string]any{"ref": ref, "offset": 1}
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatalf("too many pages, read %d of %d bytes", got.Len(), len(content))
+		}
+		input, _ := json.Marshal(next)
+		result, err := tool.Execute(context.Background(), agentic.ToolCall{Name: ToolName, Input: input})
+		if err != nil || result.Error != nil {
+			t.Fatalf("execute: %v %v", err, result.Error)
+		}
+		var out readOutput
+		if err := json.Unmarshal(result.Output, &out); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(out.Content) > maxPageBytes {
+			t.Fatalf("page of %d bytes exceeds the limit", len(out.Content))
+		}
+		got.WriteString(out.Content)
+		if out.NextOffset == 0 {
+			break
+		}
+		if out.NextByte == 0 {
+			got.WriteByte('\n')
+		}
+		next = map[string]any{"ref": ref, "offset": out.NextOffset, "byte": out.NextByte}
+	}
+	if got.String() != content {
+		t.Fatalf("paged content differs: got %d bytes, want %d", got.Len(), len(content))
+	}
+}
@@ -0,0 +1,101 @@
+package spill
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+// ToolName is the name of the tool returned by NewTool.
+const ToolName = "read_tool_output"
+
+const (
+	defaultPageLines = 200
+	maxPageBytes     = 32 * 1024
+)
+
+type readInput struct {
+	Ref    string `json:"ref" desc:"Reference from a truncated tool output"`
+	Offset int    `json:"offset,omitempty" desc:"First line to read, starting at 1" min:"1"`
+	Limit  int    `json:"limit,omitempty" desc:"Number of lines to read (default 200)" min:"1" max:"1000"`
+	Byte   int    `json:"byte,omitempty" desc:"Byte within the first line to start from, for lines longer than a page" min:"0"`
+}
+
+type readOutput struct {
+	Ref        string `json:"ref"`
+	Content    string `json:"content"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	TotalLines int    `json:"total_lines"`
+	NextOffset int    `json:"next_offset,omitempty"`
+	NextByte   int    `json:"next_byte,omitempty"`
+}
+
+// NewTool returns a tool that pages through outputs saved in store by line.
+// Pages stop early at 32KB; NextOffset tells the model where to continue.
+// A line longer than a page, such as compact JSON, is read in 32KB pieces:
+// NextByte is then set and goes with NextOffset in the next call.
+func NewTool(store Store) (agentic.Tool, error) {
+	if store == nil {
+		return nil, errors.New("spill: store is required")
+	}
+	return agentic.NewTypedTool(ToolName,
+		"Read a saved tool output that was truncated, a page of lines at a time. Pass next_offset (and next_byte when set) from the previous page to continue.",
+		func(ctx context.Context, in readInput) (readOutput, error) {
+			data, err := store.Load(ctx, in.Ref)
+			if err != nil {
+				return readOutput{}, err
+			}
+			return page(in, string(data)), nil
+		})
+}
+
+func page(in readInput, content string) readOutput {
+	lines := strings.Split(content, "\n")
+	start := max(in.Offset, 1)
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultPageLines
+	}
+	out := readOutput{Ref: in.Ref, StartLine: start, TotalLines: len(lines)}
+	if start > len(lines) {
+		out.EndLine = start - 1
+		return out
+	}
+
+	first := lines[start-1]
+	skip := min(max(in.Byte, 0), len(first))
+	if rest := first[skip:]; len(rest) > maxPageBytes {
+		n := maxPageBytes
+		for n > 0 && !utf8.RuneStart(rest[n]) {
+			n--
+		}
+		out.Content = rest[:n]
+		out.EndLine = start
+		out.NextOffset = start
+		out.NextByte = skip + n
+		return out
+	}
+
+	var b strings.Builder
+	b.WriteString(first[skip:])
+	end := start
+	for end < len(lines) && end-start+1 < limit {
+		line := lines[end]
+		if b.Len()+len(line)+1 > maxPageBytes {
+			break
+		}
+		b.WriteByte('\n')
+		b.WriteString(line)
+		end++
+	}
+	out.Content = b.String()
+	out.EndLine = end
+	if end < len(lines) {
+		out.NextOffset = end + 1
+	}
+	return out
+}
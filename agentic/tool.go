@@ -40,19 +40,26 @@ type ToolCall struct {
 }
 
 // ToolResult is the tool execution output.
+// Parts carry multimodal output, such as images, alongside Output.
 type ToolResult struct {
 	ID     string          `json:"id,omitempty"`
 	Name   string          `json:"name"`
 	Output json.RawMessage `json:"output,omitempty"`
+	Parts  []ContentPart   `json:"parts,omitempty"`
 	Error  *ToolError      `json:"error,omitempty"`
 }
 
 // ToolError is a normalized tool error payload.
+// It also implements error so tool functions can return a coded failure.
 type ToolError struct {
 	Message string `json:"message"`
 	Code    string `json:"code,omitempty"`
 }
 
+func (e *ToolError) Error() string {
+	return e.Message
+}
+
 // Tool executes a single tool call.
 type Tool interface {
 	Definition() ToolDefinition
@@ -0,0 +1,424 @@
+// Package toolsearch ranks tool definitions for agentic.ToolSearcher using
+// BM25 over an inverted index, optionally blended with embedding similarity.
+package toolsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+// Embedder turns texts into vectors for semantic ranking.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// Options configures an Index.
+type Options struct {
+	// TopK caps SearchTools results (default 10).
+	TopK int
+	// K1 and B are the BM25 parameters (defaults 1.2 and 0.75).
+	K1 float64
+	B  float64
+	// Embedder enables hybrid ranking. Nil ranks with BM25 only.
+	Embedder Embedder
+	// SemanticWeight is the share of the score taken from embedding similarity
+	// when an Embedder is set (default 0.5).
+	SemanticWeight float64
+}
+
+// Result is a ranked tool.
+type Result struct {
+	Tool     agentic.ToolDefinition
+	Score    float64 // blended score used for ordering
+	Lexical  float64 // BM25 score normalized to [0,1] against the best match
+	Semantic float64 // cosine similarity, 0 without an Embedder
+}
+
+// Index is a concurrency-safe tool index.
+type Index struct {
+	opts Options
+
+	mu       sync.RWMutex
+	docs     map[string]*document
+	postings map[string]map[string]int // term -> tool name -> frequency
+	totalLen int
+}
+
+type document struct {
+	def    agentic.ToolDefinition
+	text   string
+	length int
+	terms  map[string]int
+	vector []float64
+}
+
+// nameWeight boosts terms from the tool name over other fields.
+const nameWeight = 3
+
+// New creates an empty Index.
+func New(opts Options) *Index {
+	if opts.TopK <= 0 {
+		opts.TopK = 10
+	}
+	if opts.K1 <= 0 {
+		opts.K1 = 1.2
+	}
+	if opts.B <= 0 {
+		opts.B = 0.75
+	}
+	if opts.SemanticWeight <= 0 {
+		opts.SemanticWeight = 0.5
+	}
+	if opts.SemanticWeight > 1 {
+		opts.SemanticWeight = 1
+	}
+	return &Index{
+		opts:     opts,
+		docs:     make(map[string]*document),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// Attach indexes the registry's current tools and keeps the index updated as
+// tools are registered. Only tools the registry's policy lists are indexed.
+func Attach(ctx context.Context, reg *agentic.Registry, opts Options) (*Index, error) {
+	idx := New(opts)
+	reg.OnRegister(func(def agentic.ToolDefinition) {
+		idx.Add(def)
+	})
+	defs, err := reg.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idx.Add(defs...)
+	return idx, nil
+}
+
+// Add indexes definitions, replacing any with the same name.
+func (i *Index) Add(defs ...agentic.ToolDefinition) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, def := range defs {
+		if def.Name == "" {
+			continue
+		}
+		i.removeLocked(def.Name)
+		doc := buildDocument(def)
+		i.docs[def.Name] = doc
+		i.totalLen += doc.length
+		for term, freq := range doc.terms {
+			posting := i.postings[term]
+			if posting == nil {
+				posting = make(map[string]int)
+				i.postings[term] = posting
+			}
+			posting[def.Name] = freq
+		}
+	}
+}
+
+// Remove drops tools from the index.
+func (i *Index) Remove(names ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, name := range names {
+		i.removeLocked(name)
+	}
+}
+
+// Len reports the number of indexed tools.
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+func (i *Index) removeLocked(name string) {
+	doc, ok := i.docs[name]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		posting := i.postings[term]
+		delete(posting, name)
+		if len(posting) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	i.totalLen -= doc.length
+	delete(i.docs, name)
+}
+
+// SearchTools implements agentic.ToolSearcher, returning up to TopK tools.
+func (i *Index) SearchTools(ctx context.Context, query string) ([]agentic.ToolDefinition, error) {
+	results, err := i.Search(ctx, query, i.opts.TopK)
+	if err != nil {
+		return nil, err
+	}
+	defs := make([]agentic.ToolDefinition, len(results))
+	for n, result := range results {
+		defs[n] = result.Tool
+	}
+	return defs, nil
+}
+
+// Search ranks tools for query and returns at most k results (k <= 0 means TopK).
+func (i *Index) Search(ctx context.Context, query string, k int) ([]Result, error) {
+	if k <= 0 {
+		k = i.opts.TopK
+	}
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	i.mu.RLock()
+	lexical := i.bm25Locked(terms)
+	i.mu.RUnlock()
+
+	var semantic map[string]float64
+	if i.opts.Embedder != nil {
+		var err error
+		semantic, err = i.semanticScores(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	maxLexical := 0.0
+	for _, score := range lexical {
+		maxLexical = math.Max(maxLexical, score)
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	results := make([]Result, 0, len(lexical))
+	for name, doc := range i.docs {
+		result := Result{Tool: doc.def}
+		if maxLexical > 0 {
+			result.Lexical = lexical[name] / maxLexical
+		}
+		if semantic != nil {
+			result.Semantic = semantic[name]
+			result.Score = (1-i.opts.SemanticWeight)*result.Lexical + i.opts.SemanticWeight*result.Semantic
+		} else {
+			result.Score = result.Lexical
+		}
+		if result.Score > 0 {
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Tool.Name < results[b].Tool.Name
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// bm25Locked scores documents containing at least one query term.
+func (i *Index) bm25Locked(terms []string) map[string]float64 {
+	scores := make(map[string]float64)
+	n := float64(len(i.docs))
+	if n == 0 {
+		return scores
+	}
+	avgLen := float64(i.totalLen) / n
+	seen := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		if _, dup := seen[term]; dup {
+			continue
+		}
+		seen[term] = struct{}{}
+		posting := i.postings[term]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for name, freq := range posting {
+			tf := float64(freq)
+			docLen := float64(i.docs[name].length)
+			norm := tf * (i.opts.K1 + 1) / (tf + i.opts.K1*(1-i.opts.B+i.opts.B*docLen/avgLen))
+			scores[name] += idf * norm
+		}
+	}
+	return scores
+}
+
+// semanticScores embeds the query and any documents missing vectors.
+func (i *Index) semanticScores(ctx context.Context, query string) (map[string]float64, error) {
+	i.mu.RLock()
+	var pending []*document
+	for _, doc := range i.docs {
+		if doc.vector == nil {
+			pending = append(pending, doc)
+		}
+	}
+	i.mu.RUnlock()
+
+	texts := make([]string, 0, len(pending)+1)
+	texts = append(texts, query)
+	for _, doc := range pending {
+		texts = append(texts, doc.text)
+	}
+	vectors, err := i.opts.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("toolsearch: embed: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("toolsearch: embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for n, doc := range pending {
+		// Skip documents replaced while embedding.
+		if current, ok := i.docs[doc.def.Name]; ok && current == doc {
+			doc.vector = vectors[n+1]
+		}
+	}
+	scores := make(map[string]float64, len(i.docs))
+	for name, doc := range i.docs {
+		if doc.vector != nil {
+			scores[name] = math.Max(0, cosine(vectors[0], doc.vector))
+		}
+	}
+	return scores, nil
+}
+
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for n := range a {
+		dot += a[n] * b[n]
+		na += a[n] * a[n]
+		nb += b[n] * b[n]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// buildDocument collects searchable text from a definition.
+func buildDocument(def agentic.ToolDefinition) *document {
+	terms := make(map[string]int)
+	length := 0
+	add := func(text string, weight int) {
+		for _, term := range tokenize(text) {
+			terms[term] += weight
+			length += weight
+		}
+	}
+	add(def.Name, nameWeight)
+	add(def.Description, 1)
+	var parts []string
+	parts = append(parts, def.Name, def.Description)
+	for _, text := range schemaText(def.InputSchema) {
+		add(text, 1)
+		parts = append(parts, text)
+	}
+	for _, example := range def.Examples {
+		add(example.Description, 1)
+		add(string(example.Input), 1)
+		parts = append(parts, example.Description)
+	}
+	return &document{def: def, text: strings.Join(parts, "\n"), length: length, terms: terms}
+}
+
+// schemaText returns property names and descriptions from a JSON schema.
+func schemaText(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var node any
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return nil
+	}
+	var out []string
+	var walk func(any)
+	walk = func(value any) {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+		if desc, ok := obj["description"].(string); ok {
+			out = append(out, desc)
+		}
+		if props, ok := obj["properties"].(map[string]any); ok {
+			names := make([]string, 0, len(props))
+			for name := range props {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				out = append(out, name)
+				walk(props[name])
+			}
+		}
+		walk(obj["items"])
+	}
+	walk(node)
+	return out
+}
+
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "for": {}, "from": {}, "in": {}, "is": {},
+	"it": {}, "of": {}, "on": {}, "or": {}, "the": {}, "to": {}, "with": {},
+}
+
+// tokenize lowercases, splits on punctuation, snake_case and camelCase, drops
+// stop words and strips plural "s".
+func tokenize(text string) []string {
+	var tokens []string
+	var current []rune
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		token := normalizeToken(string(current))
+		current = current[:0]
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	runes := []rune(text)
+	for n, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if unicode.IsUpper(r) && n > 0 && unicode.IsLower(runes[n-1]) {
+				flush()
+			}
+			current = append(current, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+func normalizeToken(token string) string {
+	if _, stop := stopWords[token]; stop {
+		return ""
+	}
+	if len(token) > 3 && strings.HasSuffix(token, "s") && !strings.HasSuffix(token, "ss") {
+		token = strings.TrimSuffix(token, "s")
+	}
+	return token
+}
@@ -0,0 +1,186 @@
+package toolsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+func sampleTools() []agentic.ToolDefinition {
+	return []agentic.ToolDefinition{
+		{Name: "read_file", Description: "Read the contents of a file from disk."},
+		{Name: "write_file", Description: "Write contents to a file, replacing it."},
+		{
+			Name:        "get_weather",
+			Description: "Look up current conditions.",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string","description":"City name for the forecast."}}}`),
+		},
+		{
+			Name:        "send_email",
+			Description: "Deliver a message.",
+			Examples:    []agentic.ToolExample{{Description: "Notify a teammate by mail", Input: json.RawMessage(`{"to":"a@b.c"}`)}},
+		},
+	}
+}
+
+func TestSearchRanksWithBM25(t *testing.T) {
+	idx := New(Options{})
+	idx.Add(sampleTools()...)
+	ctx := context.Background()
+
+	results, err := idx.Search(ctx, "read file", 0)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) != 2 || results[0].Tool.Name != "read_file" || results[0].Score != 1 {
+		t.Fatalf("unexpected ranking: %#v", results)
+	}
+	if results[1].Tool.Name != "write_file" || results[1].Score <= 0 || results[1].Score >= 1 {
+		t.Fatalf("expected partial match second, got %#v", results[1])
+	}
+
+	cases := map[string]string{
+		"forecast for a city": "get_weather",
+		"mail teammate":       "send_email",
+		"readFiles":           "read_file",
+	}
+	for query, want := range cases {
+		defs, err := idx.SearchTools(ctx, query)
+		if err != nil {
+			t.Fatalf("search %q: %v", query, err)
+		}
+		if len(defs) == 0 || defs[0].Name != want {
+			t.Fatalf("query %q: expected %s first, got %#v", query, want, defs)
+		}
+	}
+	if defs, _ := idx.SearchTools(ctx, "the and of"); len(defs) != 0 {
+		t.Fatalf("expected stop-word query to match nothing, got %#v", defs)
+	}
+}
+
+func TestSearchTopKAndRemove(t *testing.T) {
+	idx := New(Options{TopK: 1})
+	idx.Add(sampleTools()...)
+	ctx := context.Background()
+
+	defs, err := idx.SearchTools(ctx, "file")
+	if err != nil || len(defs) != 1 {
+		t.Fatalf("expected top-1 result, got %#v %v", defs, err)
+	}
+	idx.Remove("read_file", "write_file")
+	if defs, _ := idx.SearchTools(ctx, "file"); len(defs) != 0 {
+		t.Fatalf("expected removed tools to disappear, got %#v", defs)
+	}
+	if idx.Len() != 2 {
+		t.Fatalf("expected 2 tools left, got %d", idx.Len())
+	}
+}
+
+type keywordEmbedder struct {
+	calls int
+	err   error
+}
+
+// Embed maps texts onto a tiny "weather" vs "files" concept space.
+func (e *keywordEmbedder) Embed(_ context.Context, texts []string) ([][]float64, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		lower := strings.ToLower(text)
+		vec := []float64{0.01, 0.01}
+		if strings.Contains(lower, "weather") || strings.Contains(lower, "rain") {
+			vec[0] = 1
+		}
+		if strings.Contains(lower, "file") {
+			vec[1] = 1
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func TestSearchHybridEmbedding(t *testing.T) {
+	embedder := &keywordEmbedder{}
+	idx := New(Options{Embedder: embedder})
+	idx.Add(sampleTools()...)
+	ctx := context.Background()
+
+	results, err := idx.Search(ctx, "will it rain", 0)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(results) == 0 || results[0].Tool.Name != "get_weather" || results[0].Lexical != 0 || results[0].Semantic < 0.9 {
+		t.Fatalf("expected semantic match, got %#v", results)
+	}
+	if _, err := idx.Search(ctx, "file", 0); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if embedder.calls != 2 {
+		t.Fatalf("expected document vectors to be cached, got %d embed calls", embedder.calls)
+	}
+
+	embedder.err = errors.New("boom")
+	if _, err := idx.Search(ctx, "file", 0); err == nil {
+		t.Fatal("expected embedder error")
+	}
+}
+
+func TestAttachFollowsRegistry(t *testing.T) {
+	reg := agentic.NewRegistry()
+	if err := reg.Register(stubTool{def: sampleTools()[0]}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	idx, err := Attach(context.Background(), reg, Options{})
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	if err := reg.Register(stubTool{def: sampleTools()[2]}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if idx.Len() != 2 {
+		t.Fatalf("expected 2 indexed tools, got %d", idx.Len())
+	}
+	defs, _ := idx.SearchTools(context.Background(), "weather")
+	if len(defs) != 1 || defs[0].Name != "get_weather" {
+		t.Fatalf("expected newly registered tool to be searchable, got %#v", defs)
+	}
+}
+
+func TestAttachSkipsToolsHiddenByPolicy(t *testing.T) {
+	reg := agentic.NewRegistry(agentic.WithPolicy(agentic.NewAllowlistPolicy([]string{"read_file", "get_weather"})))
+	tools := sampleTools()
+	if err := reg.Register(stubTool{def: tools[0]}, stubTool{def: tools[1]}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	idx, err := Attach(context.Background(), reg, Options{})
+	if err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	if err := reg.Register(stubTool{def: tools[2]}, stubTool{def: tools[3]}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if idx.Len() != 2 {
+		t.Fatalf("expected only allowed tools indexed, got %d", idx.Len())
+	}
+	defs, _ := idx.SearchTools(context.Background(), "email")
+	if len(defs) != 0 {
+		t.Fatalf("expected hidden tool to stay out of the index, got %#v", defs)
+	}
+}
+
+type stubTool struct {
+	def agentic.ToolDefinition
+}
+
+func (s stubTool) Definition() agentic.ToolDefinition { return s.def }
+
+func (s stubTool) Execute(_ context.Context, call agentic.ToolCall) (agentic.ToolResult, error) {
+	return agentic.ToolResult{ID: call.ID, Name: call.Name}, nil
+}
@@ -0,0 +1,194 @@
+package truncate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	minJSONString  = 32
+	jsonStringStep = 256
+)
+
+// JSON shortens content that is valid JSON until its compact encoding fits in
+// MaxBytes. Long strings keep a prefix, and long arrays and objects keep their
+// first elements, each with a note on what was dropped, so the result is
+// still valid JSON. MaxLines does not apply. Content that is not JSON, or
+// that cannot be shortened enough, falls back to Middle.
+func JSON(content string, opts Options) Result {
+	_, maxBytes := normalize(opts)
+
+	totalBytes := len([]byte(content))
+	totalLines := len(splitLines(content))
+	root, err := parseJSON(content)
+	if err != nil {
+		return Middle(content, opts)
+	}
+	if totalBytes <= maxBytes {
+		return Result{
+			Content:     content,
+			TotalLines:  totalLines,
+			TotalBytes:  totalBytes,
+			OutputLines: totalLines,
+			OutputBytes: totalBytes,
+		}
+	}
+
+	limits := jsonLimits{str: maxBytes, items: root.widest()}
+	for {
+		var buf bytes.Buffer
+		root.render(&buf, limits)
+		if buf.Len() <= maxBytes {
+			out := buf.String()
+			return Result{
+				Content:     out,
+				Truncated:   true,
+				TruncatedBy: "bytes",
+				TotalLines:  totalLines,
+				TotalBytes:  totalBytes,
+				OutputLines: len(splitLines(out)),
+				OutputBytes: len(out),
+			}
+		}
+		if limits.str <= minJSONString && limits.items <= 1 {
+			return Middle(content, opts)
+		}
+		// Cut long strings first; shrink arrays and objects once strings are short.
+		limits.str = max(minJSONString, limits.str/2)
+		if limits.str <= jsonStringStep {
+			limits.items = max(1, limits.items/2)
+		}
+	}
+}
+
+type jsonLimits struct {
+	str   int // bytes kept per string
+	items int // elements kept per array or object
+}
+
+// jsonNode is a parsed JSON value that keeps object key order.
+type jsonNode struct {
+	kind  byte   // '{', '[', '"', or 0 for other literals
+	value string // string value or literal text
+	keys  []string
+	items []jsonNode
+}
+
+func parseJSON(content string) (jsonNode, error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	dec.UseNumber()
+	node, err := parseJSONNode(dec)
+	if err != nil {
+		return jsonNode{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return jsonNode{}, errors.New("truncate: trailing data after JSON value")
+	}
+	return node, nil
+}
+
+func parseJSONNode(dec *json.Decoder) (jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return jsonNode{}, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		node := jsonNode{kind: byte(v)}
+		if v != '{' && v != '[' {
+			return jsonNode{}, fmt.Errorf("truncate: unexpected %q", v)
+		}
+		for dec.More() {
+			if v == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return jsonNode{}, err
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+			child, err := parseJSONNode(dec)
+			if err != nil {
+				return jsonNode{}, err
+			}
+			node.items = append(node.items, child)
+		}
+		if _, err := dec.Token(); err != nil {
+			return jsonNode{}, err
+		}
+		return node, nil
+	case string:
+		return jsonNode{kind: '"', value: v}, nil
+	case json.Number:
+		return jsonNode{value: v.String()}, nil
+	case bool:
+		return jsonNode{value: fmt.Sprint(v)}, nil
+	default:
+		return jsonNode{value: "null"}, nil
+	}
+}
+
+// widest returns the largest element count of any array or object.
+func (n jsonNode) widest() int {
+	widest := len(n.items)
+	for _, child := range n.items {
+		widest = max(widest, child.widest())
+	}
+	return widest
+}
+
+func (n jsonNode) render(buf *bytes.Buffer, limits jsonLimits) {
+	switch n.kind {
+	case '"':
+		value := n.value
+		if len(value) > limits.str {
+			kept := truncateStringToBytes(value, limits.str)
+			value = fmt.Sprintf("%s…[%d more bytes]", kept, len(value)-len(kept))
+		}
+		writeJSONString(buf, value)
+	case '[', '{':
+		buf.WriteByte(n.kind)
+		kept := min(len(n.items), limits.items)
+		for i := 0; i < kept; i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if n.kind == '{' {
+				writeJSONString(buf, n.keys[i])
+				buf.WriteByte(':')
+			}
+			n.items[i].render(buf, limits)
+		}
+		if dropped := len(n.items) - kept; dropped > 0 {
+			if kept > 0 {
+				buf.WriteByte(',')
+			}
+			if n.kind == '{' {
+				writeJSONString(buf, "…")
+				buf.WriteByte(':')
+				writeJSONString(buf, fmt.Sprintf("[%d more keys]", dropped))
+			} else {
+				writeJSONString(buf, fmt.Sprintf("…[%d more items]", dropped))
+			}
+		}
+		if n.kind == '{' {
+			buf.WriteByte('}')
+		} else {
+			buf.WriteByte(']')
+		}
+
+	default:
+		buf.WriteString(n.value)
+	}
+}
+
+// writeJSONString writes s as a JSON string without HTML escaping.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	buf.Truncate(buf.Len() - 1) // Encode appends a newline
+}
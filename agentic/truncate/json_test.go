@@ -0,0 +1,54 @@
+package truncate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+func TestJSONShortensStringsAndArrays(t *testing.T) {
+	items := make([]int, 100)
+	input, _ := json.Marshal(map[string]any{
+		"path":    "main.go",
+		"content": strings.Repeat("x", 2000),
+		"items":   items,
+	})
+
+	res := JSON(string(input), Options{MaxBytes: 300})
+	if !res.Truncated || res.OutputBytes > 300 {
+		t.Fatalf("expected truncation within 300 bytes, got %d bytes", res.OutputBytes)
+	}
+	var out map[string]any
+	if err := json.Unmarshal([]byte(res.Content), &out); err != nil {
+		t.Fatalf("expected valid JSON, got %v: %s", err, res.Content)
+	}
+	if out["path"] != "main.go" {
+		t.Fatalf("expected short fields to survive, got %v", out["path"])
+	}
+	if content := out["content"].(string); !strings.Contains(content, "more bytes]") {
+		t.Fatalf("expected a shortened string, got %q", content)
+	}
+	if list := out["items"].([]any); !strings.Contains(list[len(list)-1].(string), "more items]") {
+		t.Fatalf("expected a shortened array, got %v", list)
+	}
+	if strings.Index(res.Content, `"content"`) > strings.Index(res.Content, `"items"`) {
+		t.Fatalf("expected key order to be kept: %s", res.Content)
+	}
+}
+
+func TestJSONFallsBackForText(t *testing.T) {
+	res := JSON("not json\n"+strings.Repeat("line\n", 50), Options{MaxLines: 5})
+	if !res.Truncated || !strings.Contains(res.Content, "omitted] ...") {
+		t.Fatalf("expected middle truncation, got %+v", res)
+	}
+}
+
+func TestJSONToolResultKeepsSmallOutput(t *testing.T) {
+	result := agentic.ToolResult{Output: json.RawMessage(`{"ok": true}`)}
+	got, res := JSONToolResult(result, Options{MaxBytes: 100})
+	if res.Truncated || string(got.Output) != `{"ok": true}` {
+		t.Fatalf("expected output unchanged, got %s", got.Output)
+	}
+}
@@ -1,6 +1,7 @@
 package truncate
 
 import (
+	"fmt"
 	"unicode/utf8"
 
 	"github.com/victorarias/agentic-weave/agentic"
@@ -36,8 +37,16 @@ type Mode string
 const (
 	ModeHead Mode = "head"
 	ModeTail Mode = "tail"
+	// ModeMiddle keeps the head and the tail and elides the middle.
+	ModeMiddle Mode = "middle"
+	// ModeJSON keeps JSON output valid by shortening strings, arrays and
+	// objects; other output falls back to ModeMiddle.
+	ModeJSON Mode = "json"
 )
 
+// elisionReserve is the byte budget kept for the Middle marker line.
+const elisionReserve = 64
+
 // Head truncates content from the head (keeps first N lines/bytes).
 // Never returns partial lines unless the first line exceeds the byte limit.
 func Head(content string, opts Options) Result {
@@ -171,6 +180,49 @@ func Tail(content string, opts Options) Result {
 	}
 }
 
+// Middle keeps the first and last lines of content within the limits and
+// replaces the rest with a marker line naming what was omitted.
+func Middle(content string, opts Options) Result {
+	maxLines, maxBytes := normalize(opts)
+
+	totalBytes := len([]byte(content))
+	totalLines := len(splitLines(content))
+	if totalLines <= maxLines && totalBytes <= maxBytes {
+		return Result{
+			Content:     content,
+			TotalLines:  totalLines,
+			TotalBytes:  totalBytes,
+			OutputLines: totalLines,
+			OutputBytes: totalBytes,
+		}
+	}
+
+	headLines := max(1, (maxLines-1)/2)
+	tailLines := max(1, maxLines-1-headLines)
+	headBytes := max(1, (maxBytes-elisionReserve)/2)
+	tailBytes := max(1, maxBytes-elisionReserve-headBytes)
+	head := Head(content, Options{MaxLines: headLines, MaxBytes: headBytes})
+	tail := Tail(content, Options{MaxLines: tailLines, MaxBytes: tailBytes})
+
+	omittedLines := max(0, totalLines-head.OutputLines-tail.OutputLines)
+	omittedBytes := max(0, totalBytes-head.OutputBytes-tail.OutputBytes)
+	out := fmt.Sprintf("%s\n... [%d lines, %d bytes omitted] ...\n%s", head.Content, omittedLines, omittedBytes, tail.Content)
+
+	truncatedBy := "bytes"
+	if totalLines > maxLines {
+		truncatedBy = "lines"
+	}
+	return Result{
+		Content:     out,
+		Truncated:   true,
+		TruncatedBy: truncatedBy,
+		TotalLines:  totalLines,
+		TotalBytes:  totalBytes,
+		OutputLines: head.OutputLines + tail.OutputLines + 1,
+		OutputBytes: len([]byte(out)),
+	}
+}
+
 // HeadToolResult truncates tool output from the head.
 func HeadToolResult(result agentic.ToolResult, opts Options) (agentic.ToolResult, Result) {
 	return truncateToolResult(result, opts, ModeHead)
@@ -181,6 +233,16 @@ func TailToolResult(result agentic.ToolResult, opts Options) (agentic.ToolResult
 	return truncateToolResult(result, opts, ModeTail)
 }
 
+// MiddleToolResult truncates the middle of tool output.
+func MiddleToolResult(result agentic.ToolResult, opts Options) (agentic.ToolResult, Result) {
+	return truncateToolResult(result, opts, ModeMiddle)
+}
+
+// JSONToolResult shortens JSON tool output so it stays valid JSON.
+func JSONToolResult(result agentic.ToolResult, opts Options) (agentic.ToolResult, Result) {
+	return truncateToolResult(result, opts, ModeJSON)
+}
+
 func truncateToolResult(result agentic.ToolResult, opts Options, mode Mode) (agentic.ToolResult, Result) {
 	if len(result.Output) == 0 {
 		return result, Result{}
@@ -192,6 +254,10 @@ func truncateToolResult(result agentic.ToolResult, opts Options, mode Mode) (age
 		res = Head(content, opts)
 	case ModeTail:
 		res = Tail(content, opts)
+	case ModeMiddle:
+		res = Middle(content, opts)
+	case ModeJSON:
+		res = JSON(content, opts)
 	default:
 		res = Head(content, opts)
 	}
@@ -1,6 +1,7 @@
 package truncate
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/victorarias/agentic-weave/agentic"
@@ -113,3 +114,28 @@ func TestHeadToolResultNoOutput(t *testing.T) {
 		t.Fatalf("expected empty output")
 	}
 }
+
+func TestMiddleKeepsHeadAndTail(t *testing.T) {
+	input := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10"
+	res := Middle(input, Options{MaxLines: 5, MaxBytes: 1000})
+	if !res.Truncated || res.TruncatedBy != "lines" {
+		t.Fatalf("expected truncation by lines, got %+v", res)
+	}
+	if res.Content != "1\n2\n... [6 lines, 13 bytes omitted] ...\n9\n10" {
+		t.Fatalf("unexpected content: %q", res.Content)
+	}
+	if res.OutputLines != 5 {
+		t.Fatalf("expected 5 output lines, got %d", res.OutputLines)
+	}
+}
+
+func TestMiddleByteLimit(t *testing.T) {
+	input := strings.Repeat("a", 200) + strings.Repeat("z", 200)
+	res := Middle(input, Options{MaxBytes: 164})
+	if !res.Truncated || res.TruncatedBy != "bytes" || res.OutputBytes > 164 {
+		t.Fatalf("expected truncation within 164 bytes, got %+v", res)
+	}
+	if !strings.HasPrefix(res.Content, strings.Repeat("a", 50)) || !strings.HasSuffix(res.Content, strings.Repeat("z", 50)) {
+		t.Fatalf("unexpected content: %q", res.Content)
+	}
+}
@@ -0,0 +1,93 @@
+package agentic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/victorarias/agentic-weave/agentic/schema"
+)
+
+// TypedTool adapts a typed function into a Tool. Input schemas are derived
+// from In with schema.SchemaFromStruct.
+type TypedTool[In, Out any] struct {
+	def ToolDefinition
+	fn  func(ctx context.Context, in In) (Out, error)
+}
+
+// NewTypedTool builds a Tool from fn. In must be a struct (or pointer to one).
+// Calls are validated against the generated schema and decoded into In; the
+// returned Out is marshaled as the tool output. Errors become ToolError
+// results: return a *ToolError to choose the code, otherwise ErrorCode applies.
+func NewTypedTool[In, Out any](name, desc string, fn func(ctx context.Context, in In) (Out, error)) (*TypedTool[In, Out], error) {
+	if name == "" {
+		return nil, errors.New("typed tool: name is required")
+	}
+	if fn == nil {
+		return nil, fmt.Errorf("typed tool %s: nil function", name)
+	}
+	var zero In
+	inputSchema, err := schema.SchemaFromStruct(zero)
+	if err != nil {
+		return nil, fmt.Errorf("typed tool %s: %w", name, err)
+	}
+	hash, err := schema.HashJSON(inputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("typed tool %s: %w", name, err)
+	}
+	return &TypedTool[In, Out]{
+		def: ToolDefinition{
+			Name:        name,
+			Description: desc,
+			InputSchema: inputSchema,
+			SchemaHash:  hash,
+		},
+		fn: fn,
+	}, nil
+}
+
+// Definition implements Tool.
+func (t *TypedTool[In, Out]) Definition() ToolDefinition {
+	return t.def
+}
+
+// Execute implements Tool. Failures are reported in ToolResult.Error.
+func (t *TypedTool[In, Out]) Execute(ctx context.Context, call ToolCall) (ToolResult, error) {
+	in, err := t.decode(call.Input)
+	if err != nil {
+		return t.errorResult(call, err), nil
+	}
+	out, err := t.fn(ctx, in)
+	if err != nil {
+		return t.errorResult(call, err), nil
+	}
+	payload, err := json.Marshal(out)
+	if err != nil {
+		return t.errorResult(call, fmt.Errorf("marshal output: %w", err)), nil
+	}
+	return ToolResult{ID: call.ID, Name: t.def.Name, Output: payload}, nil
+}
+
+func (t *TypedTool[In, Out]) decode(input json.RawMessage) (In, error) {
+	var in In
+	if err := schema.Validate(t.def.InputSchema, input); err != nil {
+		return in, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	if len(bytes.TrimSpace(input)) == 0 {
+		return in, nil
+	}
+	if err := json.Unmarshal(input, &in); err != nil {
+		return in, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	return in, nil
+}
+
+func (t *TypedTool[In, Out]) errorResult(call ToolCall, err error) ToolResult {
+	return ToolResult{
+		ID:    call.ID,
+		Name:  t.def.Name,
+		Error: &ToolError{Message: err.Error(), Code: ErrorCode(err)},
+	}
+}
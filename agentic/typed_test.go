@@ -0,0 +1,95 @@
+package agentic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type addInput struct {
+	A    int    `json:"a" desc:"First operand."`
+	B    int    `json:"b"`
+	Mode string `json:"mode,omitempty" enum:"sum,diff"`
+}
+
+type addOutput struct {
+	Result int `json:"result"`
+}
+
+func newAddTool(t *testing.T) *TypedTool[addInput, addOutput] {
+	t.Helper()
+	tool, err := NewTypedTool("add", "Add numbers", func(ctx context.Context, in addInput) (addOutput, error) {
+		switch {
+		case in.A < 0:
+			return addOutput{}, &ToolError{Code: "negative", Message: "a must be positive"}
+		case in.B < 0:
+			return addOutput{}, errors.New("b is negative")
+		case in.Mode == "diff":
+			return addOutput{Result: in.A - in.B}, nil
+		}
+		return addOutput{Result: in.A + in.B}, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("new typed tool: %v", err)
+	}
+	return tool
+}
+
+func TestTypedToolDefinition(t *testing.T) {
+	def := newAddTool(t).Definition()
+	if def.Name != "add" || def.Description != "Add numbers" || def.SchemaHash == "" {
+		t.Fatalf("unexpected definition: %#v", def)
+	}
+	if !strings.Contains(string(def.InputSchema), `"required":["a","b"]`) {
+		t.Fatalf("expected generated schema, got %s", def.InputSchema)
+	}
+}
+
+func TestTypedToolExecute(t *testing.T) {
+	tool := newAddTool(t)
+	ctx := context.Background()
+
+	result, err := tool.Execute(ctx, ToolCall{ID: "1", Name: "add", Input: json.RawMessage(`{"a":2,"b":3}`)})
+	if err != nil || result.Error != nil {
+		t.Fatalf("unexpected failure: %v %#v", err, result.Error)
+	}
+	if result.ID != "1" || string(result.Output) != `{"result":5}` {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	cases := []struct {
+		input string
+		code  string
+		msg   string
+	}{
+		{input: `{"a":"x","b":1}`, code: "invalid_input", msg: "$.a: expected integer, got string"},
+		{input: `{"a":1,"b":1,"mode":"mul"}`, code: "invalid_input", msg: "$.mode: must be one of"},
+		{input: `{"a":-1,"b":1}`, code: "negative", msg: "a must be positive"},
+		{input: `{"a":1,"b":-1}`, code: "", msg: "b is negative"},
+	}
+	for _, tc := range cases {
+		result, err := tool.Execute(ctx, ToolCall{Name: "add", Input: json.RawMessage(tc.input)})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.input, err)
+		}
+		if result.Error == nil || result.Error.Code != tc.code || !strings.Contains(result.Error.Message, tc.msg) {
+			t.Fatalf("%s: unexpected tool error: %#v", tc.input, result.Error)
+		}
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	result, _ = tool.Execute(canceled, ToolCall{Name: "add", Input: json.RawMessage(`{"a":1,"b":1}`)})
+	if result.Error == nil || result.Error.Code != "canceled" {
+		t.Fatalf("expected canceled code, got %#v", result.Error)
+	}
+}
+
+func TestTypedToolRejectsNonStructInput(t *testing.T) {
+	_, err := NewTypedTool("bad", "", func(ctx context.Context, in string) (string, error) { return in, nil })
+	if err == nil {
+		t.Fatal("expected error for non-struct input")
+	}
+}
@@ -1,10 +1,14 @@
 package usage
 
 // Usage captures token usage for a single model response.
+// Input counts uncached input tokens; CacheRead and CacheWrite count input
+// tokens read from and written to a provider prompt cache.
 type Usage struct {
-	Input  int
-	Output int
-	Total  int
+	Input      int
+	Output     int
+	Total      int
+	CacheRead  int `json:",omitempty"`
+	CacheWrite int `json:",omitempty"`
 }
 
 // StopReason describes why a generation stopped.
@@ -21,7 +25,24 @@ const (
 // Normalize fills Total when missing.
 func Normalize(u Usage) Usage {
 	if u.Total == 0 {
-		u.Total = u.Input + u.Output
+		u.Total = u.Input + u.Output + u.CacheRead + u.CacheWrite
 	}
 	return u
 }
+
+// Add returns the field-wise sum of a and b after normalizing both.
+func Add(a, b Usage) Usage {
+	a, b = Normalize(a), Normalize(b)
+	return Usage{
+		Input:      a.Input + b.Input,
+		Output:     a.Output + b.Output,
+		Total:      a.Total + b.Total,
+		CacheRead:  a.CacheRead + b.CacheRead,
+		CacheWrite: a.CacheWrite + b.CacheWrite,
+	}
+}
+
+// Prompt returns all input tokens of u, cached or not.
+func Prompt(u Usage) int {
+	return u.Input + u.CacheRead + u.CacheWrite
+}
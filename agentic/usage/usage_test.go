@@ -8,8 +8,24 @@ func TestNormalize(t *testing.T) {
 		t.Fatalf("expected total to be 7, got %d", u.Total)
 	}
 
+	u = Normalize(Usage{Input: 1, Output: 2, CacheRead: 5, CacheWrite: 2})
+	if u.Total != 10 {
+		t.Fatalf("expected cache tokens in total, got %d", u.Total)
+	}
+
 	u = Normalize(Usage{Input: 1, Output: 2, Total: 10})
 	if u.Total != 10 {
 		t.Fatalf("expected total to remain 10, got %d", u.Total)
 	}
 }
+
+func TestAdd(t *testing.T) {
+	got := Add(Usage{Input: 1, Output: 2}, Usage{Input: 3, Output: 4, CacheRead: 10, Total: 20})
+	want := Usage{Input: 4, Output: 6, Total: 23, CacheRead: 10}
+	if got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if Prompt(got) != 14 {
+		t.Fatalf("expected 14 prompt tokens, got %d", Prompt(got))
+	}
+}
@@ -0,0 +1,25 @@
+package openai
+
+import "github.com/victorarias/agentic-weave/capabilities"
+
+// Adapter reports capabilities shared by OpenAI-compatible Chat Completions endpoints.
+// Self-hosted servers (vLLM, llama.cpp, Ollama) vary; flags reflect the common subset.
+type Adapter struct{}
+
+func (Adapter) Capabilities() capabilities.Capabilities {
+	return capabilities.Capabilities{
+		ToolUse:        true,
+		ToolChoiceNone: true,
+		ToolSearch:     false,
+		ToolExamples:   false,
+		DeferLoad:      false,
+		AllowedCallers: false,
+		PromptCaching:  false,
+		TokenCounting:  false,
+		Batching:       false,
+		ModelsAPI:      true,
+		Vision:         true,
+		CodeExecution:  false,
+		ComputerUse:    false,
+	}
+}
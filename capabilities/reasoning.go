@@ -0,0 +1,51 @@
+package capabilities
+
+// ReasoningEffort is a provider-neutral reasoning level.
+type ReasoningEffort string
+
+const (
+	ReasoningLow    ReasoningEffort = "low"
+	ReasoningMedium ReasoningEffort = "medium"
+	ReasoningHigh   ReasoningEffort = "high"
+)
+
+// Reasoning requests extended thinking. Providers that take a token budget
+// use BudgetTokens, falling back to a default for Effort; providers that take
+// a level use Effort, falling back to a level derived from BudgetTokens.
+type Reasoning struct {
+	BudgetTokens int
+	Effort       ReasoningEffort
+}
+
+// Budget returns the thinking token budget, or 0 when reasoning is off.
+func (r Reasoning) Budget() int {
+	if r.BudgetTokens > 0 {
+		return r.BudgetTokens
+	}
+	switch r.Effort {
+	case ReasoningLow:
+		return 2048
+	case ReasoningMedium:
+		return 8192
+	case ReasoningHigh:
+		return 24576
+	default:
+		return 0
+	}
+}
+
+// Level returns the reasoning effort, or "" when reasoning is off.
+func (r Reasoning) Level() ReasoningEffort {
+	switch {
+	case r.Effort != "":
+		return r.Effort
+	case r.BudgetTokens <= 0:
+		return ""
+	case r.BudgetTokens <= 2048:
+		return ReasoningLow
+	case r.BudgetTokens <= 8192:
+		return ReasoningMedium
+	default:
+		return ReasoningHigh
+	}
+}
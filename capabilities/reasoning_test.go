@@ -0,0 +1,26 @@
+package capabilities
+
+import "testing"
+
+func TestReasoningBudgetAndLevel(t *testing.T) {
+	tests := []struct {
+		in     Reasoning
+		budget int
+		level  ReasoningEffort
+	}{
+		{Reasoning{}, 0, ""},
+		{Reasoning{Effort: ReasoningLow}, 2048, ReasoningLow},
+		{Reasoning{Effort: ReasoningHigh}, 24576, ReasoningHigh},
+		{Reasoning{BudgetTokens: 4000}, 4000, ReasoningMedium},
+		{Reasoning{BudgetTokens: 32000}, 32000, ReasoningHigh},
+		{Reasoning{BudgetTokens: 1024, Effort: ReasoningHigh}, 1024, ReasoningHigh},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Budget(); got != tt.budget {
+			t.Fatalf("%+v: expected budget %d, got %d", tt.in, tt.budget, got)
+		}
+		if got := tt.in.Level(); got != tt.level {
+			t.Fatalf("%+v: expected level %q, got %q", tt.in, tt.level, got)
+		}
+	}
+}
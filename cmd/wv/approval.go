@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/victorarias/agentic-weave/agentic"
+)
+
+// approvalPrompt is a tool call waiting for the user's answer.
+type approvalPrompt struct {
+	req   agentic.ApprovalRequest
+	reply chan agentic.ApprovalDecision
+}
+
+// tuiApprover forwards approval requests to the UI loop and blocks until answered.
+type tuiApprover struct {
+	prompts chan approvalPrompt
+}
+
+func newTUIApprover() *tuiApprover {
+	return &tuiApprover{prompts: make(chan approvalPrompt)}
+}
+
+// RequestApproval implements agentic.Approver.
+func (t *tuiApprover) RequestApproval(ctx context.Context, req agentic.ApprovalRequest) (agentic.ApprovalDecision, error) {
+	prompt := approvalPrompt{req: req, reply: make(chan agentic.ApprovalDecision, 1)}
+	select {
+	case t.prompts <- prompt:
+	case <-ctx.Done():
+		return agentic.ApprovalDeny, ctx.Err()
+	}
+	select {
+	case decision := <-prompt.reply:
+		return decision, nil
+	case <-ctx.Done():
+		return agentic.ApprovalDeny, ctx.Err()
+	}
+}
+
+// parseApprovalAnswer maps user input to a decision.
+func parseApprovalAnswer(text string) (agentic.ApprovalDecision, bool) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case "o", "once", "y", "yes":
+		return agentic.ApprovalAllowOnce, true
+	case "a", "always":
+		return agentic.ApprovalAllowAlways, true
+	case "d", "deny", "n", "no":
+		return agentic.ApprovalDeny, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/victorarias/agentic-weave/agentic/history"
+	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/cmd/wv/sanitize"
+)
+
+const branchPreviewLength = 60
+
+// branchManager is the branching history the app forks and switches.
+type branchManager interface {
+	Load(ctx context.Context) ([]message.AgentMessage, error)
+	Branches(ctx context.Context) ([]history.Branch, error)
+	Checkout(ctx context.Context, id string) error
+}
+
+// forkCommand lists the active branch, or forks after message n of it.
+func (a *app) forkCommand(args []string) {
+	if !a.canSwitchBranch() {
+		return
+	}
+	ctx := context.Background()
+	path, err := a.branches.Load(ctx)
+	if err != nil {
+		a.appendConversation("System", "Failed to load history: "+err.Error())
+		return
+	}
+	if len(args) == 0 {
+		if len(path) == 0 {
+			a.appendConversation("System", "No messages to fork from.")
+			return
+		}
+		lines := make([]string, 0, len(path)+1)
+		lines = append(lines, "Usage: /fork N keeps messages 1..N and branches from there.")
+		for i, msg := range path {
+			lines = append(lines, fmt.Sprintf("%d. %s: %s", i+1, msg.Role, preview(msg.Content)))
+		}
+		a.appendConversation("System", strings.Join(lines, "\n"))
+		return
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(path) {
+		a.appendConversation("System", fmt.Sprintf("Fork point must be between 1 and %d.", len(path)))
+		return
+	}
+	if err := a.branches.Checkout(ctx, path[n-1].ID); err != nil {
+		a.appendConversation("System", "Fork failed: "+err.Error())
+		return
+	}
+	a.showHistory(path[:n])
+	a.appendConversation("System", fmt.Sprintf("Forked after message %d. Your next message starts a new branch; /branches lists the others.", n))
+}
+
+// branchesCommand lists branches, or switches to branch n.
+func (a *app) branchesCommand(args []string) {
+	if !a.canSwitchBranch() {
+		return
+	}
+	ctx := context.Background()
+	branches, err := a.branches.Branches(ctx)
+	if err != nil {
+		a.appendConversation("System", "Failed to list branches: "+err.Error())
+		return
+	}
+	if len(branches) == 0 {
+		a.appendConversation("System", "No branches yet.")
+		return
+	}
+	if len(args) == 0 {
+		lines := make([]string, 0, len(branches)+1)
+		lines = append(lines, "Branches (switch with /branches N):")
+		for i, branch := range branches {
+			marker := " "
+			if branch.Active {
+				marker = "*"
+			}
+			lines = append(lines, fmt.Sprintf("%s %d. %d messages: %s", marker, i+1, branch.Length, preview(branch.Preview)))
+		}
+		a.appendConversation("System", strings.Join(lines, "\n"))
+		return
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(branches) {
+		a.appendConversation("System", fmt.Sprintf("Branch must be between 1 and %d.", len(branches)))
+		return
+	}
+	if err := a.branches.Checkout(ctx, branches[n-1].Head); err != nil {
+		a.appendConversation("System", "Switch failed: "+err.Error())
+		return
+	}
+	path, err := a.branches.Load(ctx)
+	if err != nil {
+		a.appendConversation("System", "Failed to load history: "+err.Error())
+		return
+	}
+	a.showHistory(path)
+	a.appendConversation("System", fmt.Sprintf("Switched to branch %d.", n))
+}
+
+func (a *app) canSwitchBranch() bool {
+	if a.branches == nil {
+		a.appendConversation("System", "Branching is not available for this session.")
+		return false
+	}
+	if a.busy || a.runCancel != nil {
+		a.appendConversation("System", "Cannot change branches while run is active. Use /cancel and wait for completion.")
+		return false
+	}
+	return true
+}
+
+// showHistory replaces the visible conversation with messages.
+func (a *app) showHistory(messages []message.AgentMessage) {
+	a.conversation = conversationFromHistory(messages)
+	a.streamingActive = false
+	a.streamingBuffer = ""
+	a.reasoningChars = 0
+	a.tools.Clear()
+	a.refreshChat()
+}
+
+func preview(text string) string {
+	text = strings.Join(strings.Fields(sanitize.Text(text)), " ")
+	if runes := []rune(text); len(runes) > branchPreviewLength {
+		text = string(runes[:branchPreviewLength]) + "..."
+	}
+	if text == "" {
+		return "(no text)"
+	}
+	return text
+}
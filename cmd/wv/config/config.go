@@ -19,6 +19,7 @@ type Config struct {
 	EnableExtensions        bool
 	EnableProjectExtensions bool
 	EnableBash              bool
+	PolicyFile              string
 	RunTimeoutSeconds       int
 }
 
@@ -57,6 +58,7 @@ func Load() (Config, error) {
 		EnableExtensions:        enableExtensions,
 		EnableProjectExtensions: enableProjectExtensions,
 		EnableBash:              enableBash,
+		PolicyFile:              trimmedEnv("WV_POLICY_FILE"),
 		RunTimeoutSeconds:       runTimeoutSeconds,
 	}
 	if cfg.SystemPrompt == "" {
@@ -10,8 +10,11 @@ import (
 
 	"github.com/victorarias/agentic-weave/agentic"
 	"github.com/victorarias/agentic-weave/agentic/events"
+	"github.com/victorarias/agentic-weave/agentic/history"
+	"github.com/victorarias/agentic-weave/agentic/loop"
 	"github.com/victorarias/agentic-weave/agentic/message"
 	provider "github.com/victorarias/agentic-weave/agentic/providers/anthropic"
+	"github.com/victorarias/agentic-weave/agentic/rules"
 	"github.com/victorarias/agentic-weave/cmd/wv/config"
 	"github.com/victorarias/agentic-weave/cmd/wv/extensions"
 	"github.com/victorarias/agentic-weave/cmd/wv/persist"
@@ -60,27 +63,47 @@ func run() error {
 	if err != nil {
 		return err
 	}
+	defer sessionStore.Close()
 	if opts.NewSession {
 		if err := sessionStore.Replace(context.Background(), nil); err != nil {
 			return err
 		}
 	}
-	reg := agentic.NewRegistry()
+	// The tree keeps /clear and /fork from discarding earlier branches.
+	tree := history.NewTree(sessionStore)
+	// Interactive sessions always offer bash and gate calls through the rule
+	// policy (WV_POLICY_FILE or built-in defaults); non-interactive runs keep
+	// the WV_ENABLE_BASH gate.
+	var approver *tuiApprover
+	var reg *agentic.Registry
+	if opts.NonInteractive {
+		reg = agentic.NewRegistry(agentic.WithSchemaValidation())
+	} else {
+		policy, err := loadPolicy(cfg)
+		if err != nil {
+			return err
+		}
+		approver = newTUIApprover()
+		reg = agentic.NewRegistry(
+			agentic.WithSchemaValidation(),
+			agentic.WithPolicy(policy),
+			agentic.WithApprover(agentic.NewSessionApprover(approver)),
+		)
+	}
 	if err := tools.RegisterBuiltins(reg, tools.Options{
 		WorkDir:    workDir,
-		EnableBash: cfg.EnableBash,
+		EnableBash: cfg.EnableBash || !opts.NonInteractive,
 	}); err != nil {
 		return err
 	}
 
 	sess, err := session.New(session.Config{
-		Decider: anthropicDecider{
-			client:      client,
-			maxTokens:   cfg.MaxTokens,
-			temperature: cfg.Temperature,
-		},
+		Decider: provider.NewDecider(client, provider.DeciderOptions{
+			MaxTokens:   cfg.MaxTokens,
+			Temperature: cfg.Temperature,
+		}),
 		Executor:     reg,
-		HistoryStore: sessionStore,
+		HistoryStore: tree,
 		SystemPrompt: cfg.SystemPrompt,
 		MaxTurns:     cfg.MaxTurns,
 	})
@@ -96,7 +119,7 @@ func run() error {
 		return runNonInteractive(context.Background(), sess, messageValue, time.Duration(cfg.RunTimeoutSeconds)*time.Second, os.Stdout)
 	}
 
-	initialHistory, err := sessionStore.Load(context.Background())
+	initialHistory, err := tree.Load(context.Background())
 	if err != nil {
 		return err
 	}
@@ -124,8 +147,10 @@ func run() error {
 		extensionNotice,
 		time.Duration(cfg.RunTimeoutSeconds)*time.Second,
 		initialHistory,
-		sessionStore,
+		tree,
 	)
+	app.approvals = approver.prompts
+	app.branches = tree
 	term := tui.NewTerminal(os.Stdin, os.Stdout)
 	ui := tui.New(term, app.root, app.root)
 	ui.SetOnTick(app.OnTick)
@@ -138,6 +163,13 @@ func run() error {
 	return err
 }
 
+func loadPolicy(cfg config.Config) (*rules.Engine, error) {
+	if cfg.PolicyFile != "" {
+		return rules.LoadFile(cfg.PolicyFile)
+	}
+	return rules.New(tools.DefaultRules(cfg.EnableBash))
+}
+
 type app struct {
 	session *session.Session
 
@@ -153,10 +185,15 @@ type app struct {
 	conversation    []string
 	streamingBuffer string
 	streamingActive bool
+	// reasoningChars counts streamed reasoning, shown collapsed to one line.
+	reasoningChars  int
 	extensions      extensionReloader
 	runTimeout      time.Duration
 	runCancel       context.CancelFunc
 	historyResetter historyResetter
+	approvals       <-chan approvalPrompt
+	pendingApproval *approvalPrompt
+	branches        branchManager
 }
 
 type extensionReloader interface {
@@ -231,6 +268,10 @@ func (a *app) submit(msg string) {
 		a.handleCommand(text)
 		return
 	}
+	if a.pendingApproval != nil {
+		a.answerApproval(text)
+		return
+	}
 	if a.busy {
 		a.status.Set("agent is still running")
 		return
@@ -253,7 +294,7 @@ func (a *app) handleCommand(raw string) {
 	}
 	switch fields[0] {
 	case "/help":
-		a.appendConversation("System", "Commands: /help, /clear, /reload, /cancel")
+		a.appendConversation("System", "Commands: /help, /clear, /reload, /cancel, /fork [N], /branches [N]")
 	case "/clear":
 		if a.busy || a.runCancel != nil {
 			a.appendConversation("System", "Cannot clear while run is active. Use /cancel and wait for completion.")
@@ -262,6 +303,7 @@ func (a *app) handleCommand(raw string) {
 		a.conversation = nil
 		a.streamingActive = false
 		a.streamingBuffer = ""
+		a.reasoningChars = 0
 		a.tools.Clear()
 		if a.historyResetter != nil {
 			if err := a.historyResetter.Replace(context.Background(), nil); err != nil {
@@ -278,6 +320,10 @@ func (a *app) handleCommand(raw string) {
 		a.cancelActiveRun()
 		a.status.Set("cancelling...")
 		a.appendConversation("System", "Cancellation requested.")
+	case "/fork":
+		a.forkCommand(fields[1:])
+	case "/branches":
+		a.branchesCommand(fields[1:])
 	case "/reload":
 		if a.extensions == nil {
 			a.appendConversation("System", "No extension loader configured.")
@@ -304,6 +350,8 @@ func (a *app) OnTick() {
 		select {
 		case update := <-a.session.Updates():
 			a.applyUpdate(update)
+		case prompt := <-a.approvalPrompts():
+			a.showApproval(prompt)
 		default:
 			if a.busy {
 				a.status.Set(a.loader.Render(80)[0])
@@ -313,6 +361,14 @@ func (a *app) OnTick() {
 	}
 }
 
+// readyStatus reports the idle state with the session's token usage, if known.
+func readyStatus(result *loop.Result) string {
+	if result == nil || result.SessionUsage == nil {
+		return "ready"
+	}
+	return fmt.Sprintf("ready · %d tokens this session", result.SessionUsage.Total)
+}
+
 func (a *app) applyUpdate(update session.Update) {
 	switch update.Type {
 	case session.UpdateRunStart:
@@ -321,7 +377,7 @@ func (a *app) applyUpdate(update session.Update) {
 	case session.UpdateRunEnd:
 		a.busy = false
 		a.runCancel = nil
-		a.status.Set("ready")
+		a.status.Set(readyStatus(update.Result))
 	case session.UpdateRunError:
 		a.busy = false
 		a.runCancel = nil
@@ -338,6 +394,40 @@ func (a *app) cancelActiveRun() {
 	}
 	a.runCancel()
 	a.runCancel = nil
+	a.pendingApproval = nil
+}
+
+// approvalPrompts returns the approval channel, or nil while a prompt is pending.
+func (a *app) approvalPrompts() <-chan approvalPrompt {
+	if a.pendingApproval != nil {
+		return nil
+	}
+	return a.approvals
+}
+
+func (a *app) showApproval(prompt approvalPrompt) {
+	a.pendingApproval = &prompt
+	input := strings.TrimSpace(string(prompt.req.Call.Input))
+	if len(input) > 200 {
+		input = input[:200] + "..."
+	}
+	choices := "[o]nce / [a]lways / [d]eny"
+	if prompt.req.EveryTime {
+		choices = fmt.Sprintf("[o]nce / [d]eny (rule %s asks every time)", prompt.req.Rule)
+	}
+	a.appendConversation("System", fmt.Sprintf("Tool %s wants to run with input %s. Allow? %s", prompt.req.Tool.Name, input, choices))
+	a.status.Set("awaiting approval: " + prompt.req.Tool.Name)
+}
+
+func (a *app) answerApproval(text string) {
+	decision, ok := parseApprovalAnswer(text)
+	if !ok {
+		a.status.Set("answer o (once), a (always) or d (deny)")
+		return
+	}
+	a.pendingApproval.reply <- decision
+	a.pendingApproval = nil
+	a.status.Set("- thinking")
 }
 
 func (a *app) applyEvent(e events.Event) {
@@ -346,6 +436,13 @@ func (a *app) applyEvent(e events.Event) {
 		if e.Role == "assistant" {
 			a.streamingActive = true
 			a.streamingBuffer = ""
+			a.reasoningChars = 0
+		}
+	case events.ReasoningUpdate:
+		if e.Role == "assistant" {
+			a.streamingActive = true
+			a.reasoningChars += len([]rune(e.Delta))
+			a.refreshChat()
 		}
 	case events.MessageUpdate:
 		if e.Role == "assistant" {
@@ -361,6 +458,7 @@ func (a *app) applyEvent(e events.Event) {
 			}
 			a.streamingActive = false
 			a.streamingBuffer = ""
+			a.reasoningChars = 0
 			a.refreshChat()
 		}
 	case events.ToolStart:
@@ -406,6 +504,11 @@ func (a *app) refreshChat() {
 			body += "\n\n"
 		}
 		body += "**Assistant:** " + a.streamingBuffer
+	} else if a.streamingActive && a.reasoningChars > 0 {
+		if body != "" {
+			body += "\n\n"
+		}
+		body += fmt.Sprintf("**Assistant:** _reasoning (%d chars)..._", a.reasoningChars)
 	}
 	if strings.TrimSpace(body) == "" {
 		body = "Welcome to wv. Type a message and press Enter."
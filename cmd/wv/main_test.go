@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/events"
+	"github.com/victorarias/agentic-weave/agentic/history"
 	"github.com/victorarias/agentic-weave/agentic/loop"
 	"github.com/victorarias/agentic-weave/agentic/message"
 	"github.com/victorarias/agentic-weave/cmd/wv/session"
@@ -122,6 +124,23 @@ func TestAppSanitizesAssistantTerminalEscapes(t *testing.T) {
 	}
 }
 
+func TestAppCollapsesReasoningDeltas(t *testing.T) {
+	s, err := session.New(session.Config{Decider: appReplyDecider{reply: "unused"}})
+	if err != nil {
+		t.Fatalf("new session: %v", err)
+	}
+	app := newApp("test-model", s, nil, "", time.Second)
+	app.applyEvent(events.Event{Type: events.MessageStart, Role: "assistant"})
+	app.applyEvent(events.Event{Type: events.ReasoningUpdate, Role: "assistant", Delta: "let me think"})
+	if !strings.Contains(app.chat.Value, "_reasoning (12 chars)..._") || strings.Contains(app.chat.Value, "let me think") {
+		t.Fatalf("expected collapsed reasoning line, got %q", app.chat.Value)
+	}
+	app.applyEvent(events.Event{Type: events.MessageUpdate, Role: "assistant", Delta: "answer"})
+	if !strings.Contains(app.chat.Value, "**Assistant:** answer") || strings.Contains(app.chat.Value, "reasoning") {
+		t.Fatalf("expected reply text to replace reasoning line, got %q", app.chat.Value)
+	}
+}
+
 func TestAppToolEventsAppearInConversation(t *testing.T) {
 	reg := agentic.NewRegistry()
 	if err := reg.Register(appEchoTool{}); err != nil {
@@ -156,6 +175,50 @@ func TestAppToolEventsAppearInConversation(t *testing.T) {
 	}
 }
 
+func TestAppPromptsForToolApproval(t *testing.T) {
+	approver := newTUIApprover()
+	reg := agentic.NewRegistry(
+		agentic.WithPolicy(agentic.NewApprovalPolicy(nil, []string{"echo"})),
+		agentic.WithApprover(agentic.NewSessionApprover(approver)),
+	)
+	if err := reg.Register(appEchoTool{}); err != nil {
+		t.Fatalf("register tool: %v", err)
+	}
+	s, err := session.New(session.Config{
+		Decider:  appToolDecider{},
+		Executor: reg,
+	})
+	if err != nil {
+		t.Fatalf("new session: %v", err)
+	}
+
+	app := newApp("test-model", s, nil, "", time.Second)
+	app.approvals = approver.prompts
+	app.submit("run tool")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for app.pendingApproval == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for approval prompt")
+		}
+		app.OnTick()
+		time.Sleep(2 * time.Millisecond)
+	}
+	if !strings.Contains(app.chat.Value, "Tool echo wants to run") {
+		t.Fatalf("expected approval prompt in chat, got %q", app.chat.Value)
+	}
+	app.submit("maybe")
+	if app.pendingApproval == nil {
+		t.Fatal("expected invalid answer to keep prompt pending")
+	}
+	app.submit("o")
+	waitForIdle(t, app, 2*time.Second)
+
+	if !containsLine(app.conversation, "**Assistant:** done after tool") {
+		t.Fatalf("expected assistant final line, got %#v", app.conversation)
+	}
+}
+
 func TestAppSlashCommands(t *testing.T) {
 	s, err := session.New(session.Config{
 		Decider: appReplyDecider{reply: "assistant reply"},
@@ -390,3 +453,46 @@ func containsPrefix(lines []string, prefix string) bool {
 	}
 	return false
 }
+
+func TestAppForkAndSwitchBranches(t *testing.T) {
+	tree := history.NewTree(nil)
+	s, err := session.New(session.Config{
+		Decider:      appReplyDecider{reply: "assistant reply"},
+		HistoryStore: tree,
+	})
+	if err != nil {
+		t.Fatalf("new session: %v", err)
+	}
+	app := newAppWithHistory("test-model", s, nil, "", time.Second, nil, tree)
+	app.branches = tree
+
+	app.submit("first")
+	waitForIdle(t, app, 2*time.Second)
+	app.submit("second")
+	waitForIdle(t, app, 2*time.Second)
+
+	app.submit("/fork 2")
+	if containsLine(app.conversation, "**User:** second") || !containsPrefix(app.conversation, "**System:** Forked after message 2.") {
+		t.Fatalf("expected forked conversation, got %#v", app.conversation)
+	}
+	app.submit("retry")
+	waitForIdle(t, app, 2*time.Second)
+
+	app.submit("/branches")
+	if !containsPrefix(app.conversation, "**System:** Branches (switch with /branches N):\n  1. 4 messages: second\n* 2. 4 messages: retry") {
+		t.Fatalf("expected branch listing, got %#v", app.conversation)
+	}
+	app.submit("/branches 1")
+	if !containsLine(app.conversation, "**User:** second") || containsLine(app.conversation, "**User:** retry") {
+		t.Fatalf("expected original branch, got %#v", app.conversation)
+	}
+	path, _ := tree.Load(context.Background())
+	if len(path) != 4 || path[2].Content != "second" {
+		t.Fatalf("expected original branch to be active, got %#v", path)
+	}
+
+	app.submit("/fork 9")
+	if !containsPrefix(app.conversation, "**System:** Fork point must be between 1 and 4.") {
+		t.Fatalf("expected range error, got %#v", app.conversation)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/victorarias/agentic-weave/agentic/history"
 	"github.com/victorarias/agentic-weave/agentic/message"
 )
 
@@ -30,10 +31,14 @@ type filePayload struct {
 
 const fileVersion = 1
 
-// Store persists session history on disk.
+// Store persists session history on disk as an append-only JSONL log.
+// Sessions written by older versions as a single JSON file are migrated on
+// first use.
 type Store struct {
-	path     string
+	path     string // legacy JSON file
 	lockPath string
+	headPath string
+	log      *history.JSONLStore
 	mu       sync.Mutex
 }
 
@@ -51,15 +56,21 @@ func NewStore(workDir, sessionID string) (*Store, error) {
 		return nil, fmt.Errorf("persist: invalid session id %q", sessionID)
 	}
 	dir := filepath.Join(root, ".wv", "sessions")
+	log, err := history.NewJSONLStore(filepath.Join(dir, id+".jsonl"), history.JSONLOptions{})
+	if err != nil {
+		return nil, err
+	}
 	return &Store{
 		path:     filepath.Join(dir, id+".json"),
 		lockPath: filepath.Join(dir, id+".lock"),
+		headPath: filepath.Join(dir, id+".head"),
+		log:      log,
 	}, nil
 }
 
-// Path returns the underlying JSON file path.
+// Path returns the underlying JSONL log path.
 func (s *Store) Path() string {
-	return s.path
+	return s.log.Path()
 }
 
 // Append stores a message.
@@ -75,12 +86,10 @@ func (s *Store) Append(ctx context.Context, msg message.AgentMessage) error {
 	}
 	defer unlock()
 
-	messages, err := s.loadLocked()
-	if err != nil {
+	if err := s.migrateLocked(ctx); err != nil {
 		return err
 	}
-	messages = append(messages, msg)
-	return s.saveLocked(messages)
+	return s.log.Append(ctx, msg)
 }
 
 // Load reads all stored messages.
@@ -95,7 +104,10 @@ func (s *Store) Load(ctx context.Context) ([]message.AgentMessage, error) {
 		return nil, err
 	}
 	defer unlock()
-	return s.loadLocked()
+	if err := s.migrateLocked(ctx); err != nil {
+		return nil, err
+	}
+	return s.log.Load(ctx)
 }
 
 // Replace overwrites stored messages.
@@ -110,69 +122,103 @@ func (s *Store) Replace(ctx context.Context, messages []message.AgentMessage) er
 		return err
 	}
 	defer unlock()
-	return s.saveLocked(messages)
+	if err := s.log.Replace(ctx, messages); err != nil {
+		return err
+	}
+	if err := removeIfExists(s.headPath); err != nil {
+		return err
+	}
+	return removeIfExists(s.path)
 }
 
-func (s *Store) loadLocked() ([]message.AgentMessage, error) {
-	data, err := os.ReadFile(s.path)
+// LoadHead implements history.HeadStore.
+func (s *Store) LoadHead(ctx context.Context) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(s.headPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			return "", false, nil
 		}
-		return nil, err
-	}
-	var payload filePayload
-	if err := json.Unmarshal(data, &payload); err != nil {
-		return nil, fmt.Errorf("persist: decode %s: %w", s.path, err)
-	}
-	if payload.Version != 0 && payload.Version != fileVersion {
-		return nil, fmt.Errorf("persist: unsupported version %d in %s", payload.Version, s.path)
+		return "", false, err
 	}
-	out := make([]message.AgentMessage, len(payload.Messages))
-	copy(out, payload.Messages)
-	return out, nil
+	return strings.TrimSpace(string(data)), true, nil
 }
 
-func (s *Store) saveLocked(messages []message.AgentMessage) error {
-	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+// SaveHead implements history.HeadStore.
+func (s *Store) SaveHead(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
-	payload := filePayload{
-		Version:  fileVersion,
-		Messages: make([]message.AgentMessage, len(messages)),
-	}
-	copy(payload.Messages, messages)
-	data, err := json.MarshalIndent(payload, "", "  ")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.acquireLock(ctx)
 	if err != nil {
 		return err
 	}
-	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
-	if err != nil {
+	defer unlock()
+	tmp := s.headPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(id+"\n"), 0o644); err != nil {
 		return err
 	}
-	tmp := tmpFile.Name()
-	defer func() {
-		_ = os.Remove(tmp)
-	}()
-	if _, err := tmpFile.Write(data); err != nil {
-		_ = tmpFile.Close()
+	return os.Rename(tmp, s.headPath)
+}
+
+// Close releases the log file handle.
+func (s *Store) Close() error {
+	return s.log.Close()
+}
+
+// migrateLocked moves a legacy JSON session into the JSONL log.
+func (s *Store) migrateLocked(ctx context.Context) error {
+	if _, err := os.Stat(s.path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
 		return err
 	}
-	if err := tmpFile.Sync(); err != nil {
-		_ = tmpFile.Close()
-		return err
+	if _, err := os.Stat(s.log.Path()); err == nil {
+		// A previous migration finished writing the log; drop the leftover.
+		return removeIfExists(s.path)
 	}
-	if err := tmpFile.Close(); err != nil {
+	messages, err := s.loadLocked()
+	if err != nil {
 		return err
 	}
-	if err := os.Rename(tmp, s.path); err == nil {
-		return nil
+	if err := s.log.Replace(ctx, messages); err != nil {
+		return err
 	}
-	// Windows does not always allow rename-over-existing semantics.
-	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+	return removeIfExists(s.path)
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return err
 	}
-	return os.Rename(tmp, s.path)
+	return nil
+}
+
+func (s *Store) loadLocked() ([]message.AgentMessage, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var payload filePayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("persist: decode %s: %w", s.path, err)
+	}
+	if payload.Version != 0 && payload.Version != fileVersion {
+		return nil, fmt.Errorf("persist: unsupported version %d in %s", payload.Version, s.path)
+	}
+	out := make([]message.AgentMessage, len(payload.Messages))
+	copy(out, payload.Messages)
+	return out, nil
 }
 
 func (s *Store) acquireLock(ctx context.Context) (func(), error) {
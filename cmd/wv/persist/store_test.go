@@ -11,7 +11,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/victorarias/agentic-weave/agentic/history"
 	"github.com/victorarias/agentic-weave/agentic/message"
+	"github.com/victorarias/agentic-weave/agentic/usage"
 )
 
 func TestStoreAppendLoadReplace(t *testing.T) {
@@ -56,7 +58,7 @@ func TestStorePathAndDefaultSessionID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("new store: %v", err)
 	}
-	want := filepath.Join(workDir, ".wv", "sessions", "default.json")
+	want := filepath.Join(workDir, ".wv", "sessions", "default.jsonl")
 	if got := store.Path(); got != want {
 		t.Fatalf("unexpected store path got=%q want=%q", got, want)
 	}
@@ -356,3 +358,114 @@ func TestTouchLockIgnoresNonOwnerToken(t *testing.T) {
 		t.Fatalf("expected stale lock unchanged, got stale=%v token=%q", stale, token)
 	}
 }
+
+func TestStoreMigratesLegacyJSONSession(t *testing.T) {
+	workDir := t.TempDir()
+	store, err := NewStore(workDir, "legacy")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	payload := map[string]any{
+		"version":  1,
+		"messages": []map[string]any{{"role": "user", "content": "old"}},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	if err := os.WriteFile(store.path, data, 0o644); err != nil {
+		t.Fatalf("write payload: %v", err)
+	}
+
+	msg := message.AgentMessage{Role: message.RoleAssistant, Content: "new"}
+	if err := store.Append(context.Background(), msg); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	loaded, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].Content != "old" || loaded[1].Content != "new" {
+		t.Fatalf("unexpected migrated history: %#v", loaded)
+	}
+	if _, err := os.Stat(store.path); !os.IsNotExist(err) {
+		t.Fatalf("expected legacy file to be removed, got %v", err)
+	}
+}
+
+func TestStoreKeepsTreeHeadAcrossRestarts(t *testing.T) {
+	workDir := t.TempDir()
+	ctx := context.Background()
+	store, err := NewStore(workDir, "s1")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	tree := history.NewTree(store)
+	for _, content := range []string{"one", "two", "three"} {
+		if err := tree.Append(ctx, message.AgentMessage{Role: message.RoleUser, Content: content}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	path, _ := tree.Load(ctx)
+	if err := tree.Checkout(ctx, path[0].ID); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	_ = store.Close()
+
+	reopened, err := NewStore(workDir, "s1")
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reopened.Close()
+	loaded, err := history.NewTree(reopened).Load(ctx)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Content != "one" {
+		t.Fatalf("expected checked-out head to persist, got %#v", loaded)
+	}
+
+	if err := reopened.Replace(ctx, nil); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if _, ok, _ := reopened.LoadHead(ctx); ok {
+		t.Fatal("expected replace to drop the saved head")
+	}
+}
+
+func TestStorePreservesMessageMetadata(t *testing.T) {
+	store, err := NewStore(t.TempDir(), "s1")
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	msg := message.AgentMessage{
+		ID:         "msg_1",
+		Role:       message.RoleAssistant,
+		Content:    "done",
+		Usage:      &usage.Usage{Input: 3, Output: 2, Total: 5},
+		StopReason: usage.StopReasonStop,
+		Model:      "claude-test",
+		Metadata:   map[string]any{"source": "test"},
+		ProviderBlocks: []message.ProviderBlock{{
+			Provider: "anthropic",
+			Type:     "thinking",
+			Data:     json.RawMessage(`{"thinking":"hmm","signature":"sig"}`),
+		}},
+	}
+	if err := store.Append(ctx, msg); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	loaded, err := store.Load(ctx)
+	if err != nil || len(loaded) != 1 {
+		t.Fatalf("load: %v %#v", err, loaded)
+	}
+	got := loaded[0]
+	if got.ID != "msg_1" || got.Usage == nil || got.Usage.Total != 5 || got.StopReason != usage.StopReasonStop || got.Model != "claude-test" {
+		t.Fatalf("unexpected message: %#v", got)
+	}
+	if got.Metadata["source"] != "test" || len(got.ProviderBlocks) != 1 || string(got.ProviderBlocks[0].Data) != `{"thinking":"hmm","signature":"sig"}` {
+		t.Fatalf("expected metadata and provider blocks to round-trip, got %#v", got)
+	}
+}
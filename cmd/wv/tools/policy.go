@@ -0,0 +1,48 @@
+package tools
+
+import "github.com/victorarias/agentic-weave/agentic/rules"
+
+// safeBashPattern matches go test/vet/build and git status/diff/log/show
+// commands without chaining, redirection, substitution, quotes or escapes.
+// Commands it matches still ask when they use a flag in unsafeFlagPattern.
+const safeBashPattern = "^(go (test|vet|build)|git (status|diff|log|show))( [^;&|<>$`'\"\\\\\\n]*)?$"
+
+// unsafeFlagPattern matches flags that make an otherwise safe command run
+// another program (-exec, -toolexec, -vettool, -ldflags, git -c, --ext-diff)
+// or write files (-o, --output, profiles). Git accepts unique prefixes of long
+// options, so --ou* and --ext* are matched too.
+const unsafeFlagPattern = "(^|\\s)(--?(exec|toolexec|vettool|o|c|ldflags|gcflags|asmflags|pkgdir|outputdir|trace|[a-z]*profile)|--(ou|ext)[a-z-]*)(=|\\s|$)"
+
+// DefaultRules returns wv's built-in tool policy. File tools run freely, safe
+// go/git commands run without asking and other bash commands ask for approval
+// unless bashPreapproved is set.
+func DefaultRules(bashPreapproved bool) rules.RuleSet {
+	bashEffect := rules.Ask
+	if bashPreapproved {
+		bashEffect = rules.Allow
+	}
+	return rules.RuleSet{
+		Default: rules.Allow,
+		Rules: []rules.Rule{
+			{
+				Name:   "unsafe-bash-flags",
+				Effect: bashEffect,
+				Tools:  []string{"bash"},
+				Input:  []rules.InputMatch{{Path: "command", Matches: unsafeFlagPattern}},
+				Reason: "shell commands need approval",
+			},
+			{
+				Name:   "safe-bash",
+				Effect: rules.Allow,
+				Tools:  []string{"bash"},
+				Input:  []rules.InputMatch{{Path: "command", Matches: safeBashPattern}},
+			},
+			{
+				Name:   "bash",
+				Effect: bashEffect,
+				Tools:  []string{"bash"},
+				Reason: "shell commands need approval",
+			},
+		},
+	}
+}
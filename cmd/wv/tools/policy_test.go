@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/victorarias/agentic-weave/agentic"
+	"github.com/victorarias/agentic-weave/agentic/rules"
+)
+
+func TestDefaultRules(t *testing.T) {
+	engine, err := rules.New(DefaultRules(false))
+	if err != nil {
+		t.Fatalf("compile rules: %v", err)
+	}
+	bash := agentic.ToolDefinition{Name: "bash"}
+	cases := []struct {
+		command string
+		want    rules.Effect
+	}{
+		{command: "go test ./...", want: rules.Allow},
+		{command: "git status", want: rules.Allow},
+		{command: "go test ./... && rm -rf /", want: rules.Ask},
+		{command: "git log $(whoami)", want: rules.Ask},
+		{command: "rm -rf /", want: rules.Ask},
+		{command: "go build -o bin/wv ./cmd/wv", want: rules.Ask},
+		{command: "go build -o=/tmp/wv", want: rules.Ask},
+		{command: "go test -exec=/tmp/x ./...", want: rules.Ask},
+		{command: "go test --exec /tmp/x ./...", want: rules.Ask},
+		{command: "go build -toolexec=/tmp/x ./...", want: rules.Ask},
+		{command: "go vet -vettool=/tmp/x ./...", want: rules.Ask},
+		{command: "go test -c ./agentic", want: rules.Ask},
+		{command: "go test -coverprofile=/tmp/c.out ./...", want: rules.Ask},
+		{command: "git diff --output=/tmp/f", want: rules.Ask},
+		{command: "git diff --outp=/tmp/f", want: rules.Ask},
+		{command: "git diff --ext-diff", want: rules.Ask},
+		{command: "git diff -c HEAD", want: rules.Ask},
+		{command: "go build '-o' /tmp/wv", want: rules.Ask},
+		{command: "go build \\-o /tmp/wv", want: rules.Ask},
+		{command: "go test -run TestOutput -count=1 ./...", want: rules.Allow},
+		{command: "git diff --stat HEAD~1", want: rules.Allow},
+		{command: "git diff --no-ext-diff", want: rules.Allow},
+	}
+	for _, tc := range cases {
+		input, _ := json.Marshal(map[string]string{"command": tc.command})
+		got := engine.Evaluate(context.Background(), bash, agentic.ToolCall{Name: "bash", Input: input})
+		if got.Effect != tc.want {
+			t.Fatalf("%q: expected %s, got %#v", tc.command, tc.want, got)
+		}
+	}
+	if got := engine.Evaluate(context.Background(), agentic.ToolDefinition{Name: "read"}, agentic.ToolCall{Name: "read"}); got.Effect != rules.Allow {
+		t.Fatalf("expected read allowed, got %#v", got)
+	}
+
+	preapproved, err := rules.New(DefaultRules(true))
+	if err != nil {
+		t.Fatalf("compile rules: %v", err)
+	}
+	got := preapproved.Evaluate(context.Background(), bash, agentic.ToolCall{Name: "bash", Input: []byte(`{"command":"rm -rf /"}`)})
+	if got.Effect != rules.Allow {
+		t.Fatalf("expected preapproved bash, got %#v", got)
+	}
+}
@@ -49,6 +49,9 @@ func summarizeToolResult(result *agentic.ToolResult) string {
 		}
 		return truncatePreview(text, 120)
 	case "read":
+		if mimeType, ok := payload["mime_type"].(string); ok {
+			return sanitize.Text(mimeType) + " image"
+		}
 		content, _ := payload["content"].(string)
 		return truncatePreview(strings.TrimSpace(sanitize.Text(content)), 120)
 	case "write", "edit":
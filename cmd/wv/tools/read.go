@@ -3,6 +3,9 @@ package tools
 import (
 	"bufio"
 	"context"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"strings"
 
@@ -16,7 +19,19 @@ type readInput struct {
 	MaxBytes  int    `json:"max_bytes,omitempty"`
 }
 
+// maxImageBytes matches the largest image providers accept inline.
+const maxImageBytes = 5 * 1024 * 1024
+
+// imageTypes are the image formats returned as image parts.
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
 // ReadTool reads file contents with optional line ranges.
+// Images are returned as image parts instead of text.
 type ReadTool struct {
 	WorkDir string
 }
@@ -57,6 +72,12 @@ func (t ReadTool) Execute(ctx context.Context, call agentic.ToolCall) (agentic.T
 	}
 	defer f.Close()
 
+	if mimeType, err := sniffImage(f); err != nil {
+		return agentic.ToolResult{ID: call.ID, Name: call.Name, Error: &agentic.ToolError{Message: err.Error()}}, nil
+	} else if mimeType != "" {
+		return readImage(call, f, path, mimeType), nil
+	}
+
 	maxBytes := sanitizeLimit(input.MaxBytes, 64*1024, 512*1024)
 	start := input.StartLine
 	if start <= 0 {
@@ -143,3 +164,43 @@ func (t ReadTool) Execute(ctx context.Context, call agentic.ToolCall) (agentic.T
 		"truncated":   truncated,
 	})}, nil
 }
+
+// sniffImage returns the image MIME type of f, or "" for other files. It
+// leaves f positioned at the start.
+func sniffImage(f *os.File) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	mimeType := http.DetectContentType(head[:n])
+	if !imageTypes[mimeType] {
+		return "", nil
+	}
+	return mimeType, nil
+}
+
+func readImage(call agentic.ToolCall, f *os.File, path, mimeType string) agentic.ToolResult {
+	data, err := io.ReadAll(io.LimitReader(f, maxImageBytes+1))
+	if err != nil {
+		return agentic.ToolResult{ID: call.ID, Name: call.Name, Error: &agentic.ToolError{Message: err.Error()}}
+	}
+	if len(data) > maxImageBytes {
+		return agentic.ToolResult{ID: call.ID, Name: call.Name, Error: &agentic.ToolError{
+			Message: fmt.Sprintf("image exceeds %d bytes", maxImageBytes),
+		}}
+	}
+	return agentic.ToolResult{
+		ID:   call.ID,
+		Name: call.Name,
+		Output: toJSON(map[string]any{
+			"path":      path,
+			"mime_type": mimeType,
+			"bytes":     len(data),
+		}),
+		Parts: []agentic.ContentPart{agentic.ImagePart(mimeType, data)},
+	}
+}
@@ -1,8 +1,11 @@
 package tools
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"image"
+	"image/png"
 	"os"
 	"path/filepath"
 	"strings"
@@ -266,3 +269,30 @@ func TestResolvePathAcceptsPathsInsideSymlinkedWorkspace(t *testing.T) {
 		t.Fatalf("expected file created in real workspace: %v", err)
 	}
 }
+
+func TestReadReturnsImagesAsParts(t *testing.T) {
+	workDir := t.TempDir()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "pixel.png"), buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write png: %v", err)
+	}
+
+	result := executeTool(t, ReadTool{WorkDir: workDir}, map[string]any{"path": "pixel.png"})
+	if result.Error != nil {
+		t.Fatalf("read error: %v", result.Error)
+	}
+	out := decodeOutput(t, result)
+	if out["mime_type"] != "image/png" || out["content"] != nil {
+		t.Fatalf("unexpected output: %v", out)
+	}
+	if len(result.Parts) != 1 {
+		t.Fatalf("expected one image part, got %d", len(result.Parts))
+	}
+	part := result.Parts[0]
+	if part.Type != agentic.PartImage || part.MIMEType != "image/png" || !bytes.Equal(part.Data, buf.Bytes()) {
+		t.Fatalf("unexpected part: %+v", part)
+	}
+}
@@ -8,6 +8,7 @@ require (
 	github.com/anthropics/anthropic-sdk-go v1.21.0
 	github.com/joho/godotenv v1.5.1
 	golang.org/x/oauth2 v0.34.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -305,3 +305,74 @@ func TestLoopToolListingRespectsPolicy(t *testing.T) {
 		t.Fatalf("unexpected tools list: %#v", decider.inputs[0].Tools)
 	}
 }
+
+func TestLoopRecordsDeniedApprovalAsToolError(t *testing.T) {
+	reg := agentic.NewRegistry(
+		agentic.WithPolicy(agentic.NewApprovalPolicy(nil, []string{"bash"})),
+		agentic.WithApprover(agentic.ApproverFunc(func(ctx context.Context, req agentic.ApprovalRequest) (agentic.ApprovalDecision, error) {
+			return agentic.ApprovalDeny, nil
+		})),
+	)
+	if err := reg.Register(staticTool{
+		def:    agentic.ToolDefinition{Name: "bash"},
+		output: json.RawMessage(`"ran"`),
+	}); err != nil {
+		t.Fatalf("register tool: %v", err)
+	}
+
+	decider := &scriptedDecider{
+		script: []loop.Decision{
+			{ToolCalls: []agentic.ToolCall{{Name: "bash", Input: json.RawMessage(`{"command":"rm -rf /"}`)}}},
+			{Reply: "ok"},
+		},
+	}
+
+	result, _, err := runScenario(t, loop.Config{
+		Decider:  decider,
+		Executor: reg,
+	}, loop.Request{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.ToolResults) != 1 || result.ToolResults[0].Error == nil {
+		t.Fatalf("expected denied tool error, got %#v", result.ToolResults)
+	}
+	if result.ToolResults[0].Error.Code != "denied" || len(result.ToolResults[0].Output) != 0 {
+		t.Fatalf("unexpected tool result: %#v", result.ToolResults[0])
+	}
+}
+
+func TestLoopRecordsInvalidInputAsToolError(t *testing.T) {
+	reg := agentic.NewRegistry(agentic.WithSchemaValidation())
+	if err := reg.Register(staticTool{
+		def: agentic.ToolDefinition{
+			Name:        "read",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"path":{"type":"string"}},"required":["path"]}`),
+		},
+		output: json.RawMessage(`"contents"`),
+	}); err != nil {
+		t.Fatalf("register tool: %v", err)
+	}
+
+	decider := &scriptedDecider{
+		script: []loop.Decision{
+			{ToolCalls: []agentic.ToolCall{{Name: "read", Input: json.RawMessage(`{"file":"a.go"}`)}}},
+			{Reply: "ok"},
+		},
+	}
+
+	result, _, err := runScenario(t, loop.Config{
+		Decider:  decider,
+		Executor: reg,
+	}, loop.Request{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.ToolResults) != 1 || result.ToolResults[0].Error == nil {
+		t.Fatalf("expected invalid input tool error, got %#v", result.ToolResults)
+	}
+	toolErr := result.ToolResults[0].Error
+	if toolErr.Code != "invalid_input" || !strings.Contains(toolErr.Message, "$.path: required property is missing") {
+		t.Fatalf("unexpected tool error: %#v", toolErr)
+	}
+}